@@ -0,0 +1,221 @@
+// Command replay re-publishes payment.created messages for the PENDING or FAILED payments created
+// in a time window, at a throttled rate, so the worker processes them again after a bug fix
+//
+// Without -execute it only reports what it would replay. With -checkpoint it records its
+// position after every batch and resumes from there when run again with the same flags
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/cashflow/payment-gateway/internal/adapter/secondary/cache"
+	"github.com/cashflow/payment-gateway/internal/adapter/secondary/database"
+	"github.com/cashflow/payment-gateway/internal/adapter/secondary/messaging"
+	"github.com/cashflow/payment-gateway/internal/config"
+	"github.com/cashflow/payment-gateway/internal/constant/model/db"
+	"github.com/cashflow/payment-gateway/internal/core"
+	"github.com/cashflow/payment-gateway/internal/core/service"
+	"github.com/cashflow/payment-gateway/internal/logger"
+	"github.com/cashflow/payment-gateway/internal/port/output"
+	"github.com/google/uuid"
+)
+
+// checkpoint is the resume state written to the -checkpoint file after every batch
+// The selection is stored with the cursor so a checkpoint is never resumed for a different window
+type checkpoint struct {
+	Status          core.PaymentStatus `json:"status"`
+	CreatedAfter    time.Time          `json:"created_after"`
+	CreatedBefore   time.Time          `json:"created_before"`
+	CursorCreatedAt time.Time          `json:"cursor_created_at"`
+	CursorID        string             `json:"cursor_id"`
+	Scanned         int                `json:"scanned"`
+	Published       int                `json:"published"`
+	Skipped         int                `json:"skipped"`
+}
+
+func main() {
+	var (
+		from           = flag.String("from", "", "replay payments created at or after this RFC3339 time (required)")
+		to             = flag.String("to", "", "replay payments created before this RFC3339 time (required)")
+		status         = flag.String("status", string(core.PaymentStatusPending), "PENDING to re-publish as is, FAILED to reset eligible payments to PENDING first")
+		perSecond      = flag.Float64("rate", 10, "messages published per second at most")
+		checkpointPath = flag.String("checkpoint", "", "file recording progress; an existing checkpoint for the same selection is resumed")
+		execute        = flag.Bool("execute", false, "reset and publish; without it the replay is a dry run")
+	)
+	flag.Parse()
+
+	req := service.ReplayRequest{
+		Status: core.PaymentStatus(strings.ToUpper(*status)),
+		DryRun: !*execute,
+	}
+	var err error
+	if req.CreatedAfter, err = time.Parse(time.RFC3339, *from); err != nil {
+		log.Fatalf("-from must be an RFC3339 timestamp: %v", err)
+	}
+	if req.CreatedBefore, err = time.Parse(time.RFC3339, *to); err != nil {
+		log.Fatalf("-to must be an RFC3339 timestamp: %v", err)
+	}
+	if *perSecond <= 0 {
+		log.Fatalf("-rate must be positive")
+	}
+
+	// Resume from the checkpoint of an earlier run over the same selection
+	var resumed checkpoint
+	if *checkpointPath != "" && !req.DryRun {
+		found, err := readCheckpoint(*checkpointPath, &resumed)
+		if err != nil {
+			log.Fatalf("Failed to read checkpoint: %v", err)
+		}
+		if found {
+			if resumed.Status != req.Status || !resumed.CreatedAfter.Equal(req.CreatedAfter) || !resumed.CreatedBefore.Equal(req.CreatedBefore) {
+				log.Fatalf("Checkpoint %s is for %s payments created in [%s, %s); remove it to start a different replay",
+					*checkpointPath, resumed.Status, resumed.CreatedAfter.Format(time.RFC3339), resumed.CreatedBefore.Format(time.RFC3339))
+			}
+			if req.After, err = resumed.cursor(); err != nil {
+				log.Fatalf("Failed to read checkpoint: %v", err)
+			}
+			log.Printf("Resuming after payment %s (%d scanned, %d published so far)", resumed.CursorID, resumed.Scanned, resumed.Published)
+		}
+	}
+
+	// Load configuration from environment variables
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+	redactor := logger.NewRedactor(cfg.LogRedactKeys)
+	slog.SetDefault(logger.New(cfg.LogFormat, redactor))
+
+	// Initialize secondary adapter: Database (always the primary, since FAILED payments are reset)
+	dbConn, err := db.NewDB(cfg.DatabaseURL, "", cfg.DatabaseSchema, db.PoolConfig{
+		MaxOpenConns:    cfg.DBMaxOpenConns,
+		MaxIdleConns:    cfg.DBMaxIdleConns,
+		ConnMaxLifetime: cfg.DBConnMaxLifetime,
+	}, logger.NewGormLogger(slog.Default(), cfg.DBLogLevel, cfg.DBSlowQueryThreshold))
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer dbConn.Close()
+
+	// Resets only move payments back to PENDING, which merchants get no webhook for
+	var paymentRepo output.PaymentRepository = database.NewGormPaymentRepository(dbConn.DB, nil)
+	// Resets go through the shared cache so the API stops serving cached FAILED copies
+	if cfg.CacheBackend == cache.BackendRedis {
+		paymentCache, err := cache.NewRedisCache(cfg.RedisURL)
+		if err != nil {
+			log.Fatalf("Failed to connect to redis cache: %v", err)
+		}
+		defer paymentCache.Close()
+		paymentRepo = cache.NewCachedPaymentRepository(paymentRepo, paymentCache, cfg.CachePendingTTL, cfg.CacheTerminalTTL)
+	}
+
+	// Initialize secondary adapter: Messaging
+	msgClient, err := messaging.NewClient(messaging.Config{
+		Backend:            cfg.MessagingBackend,
+		RabbitMQURL:        cfg.RabbitMQURL,
+		KafkaBrokers:       cfg.KafkaBrokers,
+		KafkaTopic:         cfg.KafkaTopic,
+		KafkaConsumerGroup: cfg.KafkaConsumerGroup,
+		PublishTimeout:     cfg.PublishTimeout,
+		Compress:           cfg.CompressMessages,
+		CompressMinBytes:   cfg.CompressMinBytes,
+	})
+	if err != nil {
+		log.Fatalf("Failed to connect to %s: %v", cfg.MessagingBackend, err)
+	}
+	defer msgClient.Close()
+
+	// Stop between messages on CTRL+C; the checkpoint already covers every finished batch
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	total, err := paymentRepo.Count(output.PaymentFilter{
+		Status:        req.Status,
+		CreatedAfter:  req.CreatedAfter,
+		CreatedBefore: req.CreatedBefore,
+	})
+	if err != nil {
+		log.Fatalf("Failed to count payments: %v", err)
+	}
+	mode := "Replaying"
+	if req.DryRun {
+		mode = "Dry run (pass -execute to replay):"
+	}
+	log.Printf("%s %d %s payments created in [%s, %s) at up to %g messages/s",
+		mode, total, req.Status, req.CreatedAfter.Format(time.RFC3339), req.CreatedBefore.Format(time.RFC3339), *perSecond)
+
+	replayer := service.NewPaymentReplayer(paymentRepo, msgClient, *perSecond)
+	result, err := replayer.Run(ctx, req, func(p service.ReplayProgress) {
+		log.Printf("Progress: %d/%d scanned, %d published, %d skipped",
+			resumed.Scanned+p.Scanned, total, resumed.Published+p.Published, resumed.Skipped+p.Skipped)
+		if *checkpointPath != "" && !req.DryRun {
+			if err := writeCheckpoint(*checkpointPath, resumed.advance(req, p)); err != nil {
+				log.Printf("Failed to write checkpoint: %v", err)
+			}
+		}
+	})
+	if err != nil {
+		log.Fatalf("Replay stopped after %d published: %v", resumed.Published+result.Published, err)
+	}
+	log.Printf("Replay finished: %d scanned, %d published, %d skipped",
+		resumed.Scanned+result.Scanned, resumed.Published+result.Published, resumed.Skipped+result.Skipped)
+}
+
+// advance returns the checkpoint after this run's progress p, adding to the totals it resumed from
+func (c checkpoint) advance(req service.ReplayRequest, p service.ReplayProgress) checkpoint {
+	return checkpoint{
+		Status:          req.Status,
+		CreatedAfter:    req.CreatedAfter,
+		CreatedBefore:   req.CreatedBefore,
+		CursorCreatedAt: p.Cursor.CreatedAt,
+		CursorID:        p.Cursor.ID.String(),
+		Scanned:         c.Scanned + p.Scanned,
+		Published:       c.Published + p.Published,
+		Skipped:         c.Skipped + p.Skipped,
+	}
+}
+
+// cursor returns the position the checkpoint resumes after
+func (c checkpoint) cursor() (output.PaymentCursor, error) {
+	id, err := uuid.Parse(c.CursorID)
+	if err != nil {
+		return output.PaymentCursor{}, fmt.Errorf("invalid cursor_id %q: %w", c.CursorID, err)
+	}
+	return output.PaymentCursor{CreatedAt: c.CursorCreatedAt, ID: id}, nil
+}
+
+// readCheckpoint loads the checkpoint at path, reporting false if there is none yet
+func readCheckpoint(path string, c *checkpoint) (bool, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, json.Unmarshal(data, c)
+}
+
+// writeCheckpoint replaces the checkpoint at path, via a rename so a crash never leaves it half written
+func writeCheckpoint(path string, c checkpoint) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
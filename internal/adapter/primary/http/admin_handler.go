@@ -0,0 +1,246 @@
+package http
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/cashflow/payment-gateway/internal/core"
+	"github.com/cashflow/payment-gateway/internal/port/input"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// AdminHandler is a primary adapter (HTTP handler) for operator-only endpoints
+type AdminHandler struct {
+	adminService input.AdminService
+}
+
+// NewAdminHandler creates a new admin handler
+func NewAdminHandler(adminService input.AdminService) *AdminHandler {
+	return &AdminHandler{
+		adminService: adminService,
+	}
+}
+
+// PurgePaymentsRequest represents the HTTP request to purge payments
+type PurgePaymentsRequest struct {
+	Status        string `json:"status"`
+	CreatedBefore string `json:"created_before"`
+	DryRun        bool   `json:"dry_run"`
+}
+
+// PurgePaymentsResponse represents the HTTP response for a purge
+type PurgePaymentsResponse struct {
+	Count  int64 `json:"count"`
+	DryRun bool  `json:"dry_run"`
+}
+
+// ListPendingPaymentsResponse represents the HTTP response for stuck PENDING payments
+type ListPendingPaymentsResponse struct {
+	Payments []PaymentResponse `json:"payments"`
+	Cutoff   string            `json:"cutoff"`
+	Limit    int               `json:"limit"`
+}
+
+// ReprocessPaymentsRequest represents the HTTP request to reprocess FAILED payments
+type ReprocessPaymentsRequest struct {
+	PaymentIDs    []string `json:"payment_ids"`
+	CreatedAfter  string   `json:"created_after"`
+	CreatedBefore string   `json:"created_before"`
+	DryRun        bool     `json:"dry_run"`
+}
+
+// OverrideStatusRequest represents the HTTP request to force a payment's status
+// The actor is the operator the admin token belongs to, not a field of the body
+type OverrideStatusRequest struct {
+	Status string `json:"status"`
+	Reason string `json:"reason"`
+}
+
+// ReprocessPaymentsResponse represents the HTTP response for a reprocess
+type ReprocessPaymentsResponse struct {
+	Count      int      `json:"count"`
+	PaymentIDs []string `json:"payment_ids"`
+	DryRun     bool     `json:"dry_run"`
+	Enqueued   bool     `json:"enqueued"`
+}
+
+// PurgePayments handles bulk soft-deletion of stale payments
+func (h *AdminHandler) PurgePayments(c echo.Context) error {
+	var req PurgePaymentsRequest
+	if err := c.Bind(&req); err != nil {
+		return respondError(c, http.StatusBadRequest, ErrCodeInvalidRequestBody, "Invalid request body")
+	}
+
+	// Convert to service request
+	serviceReq := input.PurgePaymentsRequest{
+		Status: core.PaymentStatus(req.Status),
+		DryRun: req.DryRun,
+	}
+	if req.CreatedBefore != "" {
+		createdBefore, err := time.Parse(time.RFC3339, req.CreatedBefore)
+		if err != nil {
+			return respondError(c, http.StatusBadRequest, ErrCodeInvalidParameter, "created_before must be an RFC3339 timestamp")
+		}
+		serviceReq.CreatedBefore = createdBefore
+	}
+
+	// Call service (input port)
+	response, err := h.adminService.PurgePayments(serviceReq)
+	if err != nil {
+		return respondServiceError(c, err, "Failed to purge payments")
+	}
+
+	return respondData(c, http.StatusOK, PurgePaymentsResponse{
+		Count:  response.Count,
+		DryRun: response.DryRun,
+	})
+}
+
+// ListPendingPayments handles listing payments stuck in PENDING
+// Query parameters: older_than (Go duration, e.g. 15m) and limit
+func (h *AdminHandler) ListPendingPayments(c echo.Context) error {
+	var req input.ListPendingPaymentsRequest
+	if value := c.QueryParam("older_than"); value != "" {
+		olderThan, err := time.ParseDuration(value)
+		if err != nil {
+			return respondError(c, http.StatusBadRequest, ErrCodeInvalidParameter, "older_than must be a duration such as 15m or 2h")
+		}
+		req.OlderThan = olderThan
+	}
+	var err error
+	if req.Limit, err = parseIntParam(c, "limit"); err != nil {
+		return respondError(c, http.StatusBadRequest, ErrCodeInvalidParameter, "limit must be an integer")
+	}
+
+	// Call service (input port)
+	response, err := h.adminService.ListPendingPayments(req)
+	if err != nil {
+		return respondServiceError(c, err, "Failed to list pending payments")
+	}
+
+	// Convert to HTTP response
+	httpResponse := ListPendingPaymentsResponse{
+		Payments: make([]PaymentResponse, 0, len(response.Payments)),
+		Cutoff:   formatTimestamp(c, response.Cutoff),
+		Limit:    response.Limit,
+	}
+	for i := range response.Payments {
+		httpResponse.Payments = append(httpResponse.Payments, toHTTPPaymentResponse(c, &response.Payments[i]))
+	}
+
+	return respondData(c, http.StatusOK, httpResponse)
+}
+
+// ReprocessPayments handles sending FAILED payments back to PENDING for another processing attempt
+func (h *AdminHandler) ReprocessPayments(c echo.Context) error {
+	var req ReprocessPaymentsRequest
+	if err := c.Bind(&req); err != nil {
+		return respondError(c, http.StatusBadRequest, ErrCodeInvalidRequestBody, "Invalid request body")
+	}
+
+	// Convert to service request
+	serviceReq := input.ReprocessPaymentsRequest{DryRun: req.DryRun}
+	for _, value := range req.PaymentIDs {
+		id, err := uuid.Parse(value)
+		if err != nil {
+			return respondError(c, http.StatusBadRequest, ErrCodeInvalidPaymentID, "Invalid payment ID "+value)
+		}
+		serviceReq.PaymentIDs = append(serviceReq.PaymentIDs, id)
+	}
+	var err error
+	if serviceReq.CreatedAfter, err = parseTimeValue(req.CreatedAfter); err != nil {
+		return respondError(c, http.StatusBadRequest, ErrCodeInvalidParameter, "created_after must be an RFC3339 timestamp")
+	}
+	if serviceReq.CreatedBefore, err = parseTimeValue(req.CreatedBefore); err != nil {
+		return respondError(c, http.StatusBadRequest, ErrCodeInvalidParameter, "created_before must be an RFC3339 timestamp")
+	}
+
+	// Call service (input port)
+	response, err := h.adminService.ReprocessPayments(serviceReq)
+	if err != nil {
+		return respondServiceError(c, err, "Failed to reprocess payments")
+	}
+
+	// Convert to HTTP response
+	httpResponse := ReprocessPaymentsResponse{
+		Count:      len(response.PaymentIDs),
+		PaymentIDs: make([]string, 0, len(response.PaymentIDs)),
+		DryRun:     response.DryRun,
+		Enqueued:   response.Enqueued,
+	}
+	for _, id := range response.PaymentIDs {
+		httpResponse.PaymentIDs = append(httpResponse.PaymentIDs, id.String())
+	}
+
+	return respondData(c, http.StatusOK, httpResponse)
+}
+
+// ApprovePayment handles releasing a payment held for review to processing
+func (h *AdminHandler) ApprovePayment(c echo.Context) error {
+	paymentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, ErrCodeInvalidPaymentID, "Invalid payment ID")
+	}
+
+	// Call service (input port)
+	response, err := h.adminService.ApprovePayment(paymentID)
+	if err != nil {
+		return respondServiceError(c, err, "Failed to approve payment")
+	}
+
+	return respondData(c, http.StatusOK, CreatePaymentResponse{
+		PaymentResponse: toHTTPPaymentResponse(c, response),
+		Enqueued:        response.Enqueued,
+	})
+}
+
+// RejectPayment handles failing a payment held for review
+func (h *AdminHandler) RejectPayment(c echo.Context) error {
+	paymentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, ErrCodeInvalidPaymentID, "Invalid payment ID")
+	}
+
+	// Call service (input port)
+	response, err := h.adminService.RejectPayment(paymentID)
+	if err != nil {
+		return respondServiceError(c, err, "Failed to reject payment")
+	}
+
+	return respondData(c, http.StatusOK, toHTTPPaymentResponse(c, response))
+}
+
+// OverrideStatus handles forcing a payment to a status outside the normal state machine
+func (h *AdminHandler) OverrideStatus(c echo.Context) error {
+	paymentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, ErrCodeInvalidPaymentID, "Invalid payment ID")
+	}
+
+	var req OverrideStatusRequest
+	if err := c.Bind(&req); err != nil {
+		return respondError(c, http.StatusBadRequest, ErrCodeInvalidRequestBody, "Invalid request body")
+	}
+
+	// Call service (input port)
+	response, err := h.adminService.OverrideStatus(input.OverrideStatusRequest{
+		PaymentID: paymentID,
+		Status:    core.PaymentStatus(req.Status),
+		Actor:     adminActorFromContext(c),
+		Reason:    req.Reason,
+	})
+	if err != nil {
+		return respondServiceError(c, err, "Failed to override payment status")
+	}
+
+	return respondData(c, http.StatusOK, toHTTPPaymentResponse(c, response))
+}
+
+// parseTimeValue parses an optional RFC3339 timestamp from a request body
+func parseTimeValue(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
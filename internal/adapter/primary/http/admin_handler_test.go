@@ -0,0 +1,67 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/cashflow/payment-gateway/internal/core"
+	"github.com/cashflow/payment-gateway/internal/port/input"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// stubAdminService is an input.AdminService that records the override it was asked for
+// Methods a test doesn't stub panic through the nil embedded interface
+type stubAdminService struct {
+	input.AdminService
+	override *input.OverrideStatusRequest
+}
+
+func (s *stubAdminService) OverrideStatus(req input.OverrideStatusRequest) (*input.PaymentResponse, error) {
+	s.override = &req
+	return &input.PaymentResponse{ID: req.PaymentID, Status: req.Status, Currency: core.CurrencyETB}, nil
+}
+
+func TestOverrideStatusActorIsAuthenticatedOperator(t *testing.T) {
+	credentials := AdminCredentials{"jane": "jane-token", DefaultAdminActor: "shared-token"}
+	tests := []struct {
+		name      string
+		token     string
+		wantCode  int
+		wantActor string
+	}{
+		{name: "operator token", token: "jane-token", wantCode: http.StatusOK, wantActor: "jane"},
+		{name: "shared token", token: "shared-token", wantCode: http.StatusOK, wantActor: DefaultAdminActor},
+		{name: "unknown token", token: "guess", wantCode: http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			admin := &stubAdminService{}
+			e := echo.New()
+			e.POST("/admin/payments/:id/status", NewAdminHandler(admin).OverrideStatus, AdminAuth(credentials))
+
+			// An actor in the body is not a field of the request and must not be recorded
+			body := `{"status": "SUCCESS", "actor": "mallory", "reason": "reconciled"}`
+			req := httptest.NewRequest(http.MethodPost, "/admin/payments/"+uuid.NewString()+"/status", strings.NewReader(body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			req.Header.Set(echo.HeaderAuthorization, "Bearer "+tt.token)
+			rec := httptest.NewRecorder()
+			e.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantCode {
+				t.Fatalf("status: got %d, want %d (body %s)", rec.Code, tt.wantCode, rec.Body.String())
+			}
+			if tt.wantActor == "" {
+				if admin.override != nil {
+					t.Errorf("override reached the service: %+v", admin.override)
+				}
+				return
+			}
+			if admin.override == nil || admin.override.Actor != tt.wantActor {
+				t.Errorf("actor: got %+v, want %q", admin.override, tt.wantActor)
+			}
+		})
+	}
+}
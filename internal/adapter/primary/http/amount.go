@@ -0,0 +1,49 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/cashflow/payment-gateway/internal/core"
+)
+
+// decimalPattern matches a plain decimal number such as "10", "10.5" or "-0.25"
+var decimalPattern = regexp.MustCompile(`^-?[0-9]+(\.[0-9]+)?$`)
+
+// Amount is a monetary amount accepted as either a JSON number (10.50) or a decimal string ("10.50")
+type Amount float64
+
+// UnmarshalJSON accepts both number and decimal string forms
+func (a *Amount) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		// Only plain decimals are accepted; exponents, hex, "NaN" etc. are rejected
+		if !decimalPattern.MatchString(s) {
+			return fmt.Errorf("amount %q is not a decimal number", s)
+		}
+		data = []byte(s)
+	}
+
+	value, err := strconv.ParseFloat(string(data), 64)
+	if err != nil {
+		return fmt.Errorf("amount must be a number or decimal string: %w", err)
+	}
+	*a = Amount(value)
+	return nil
+}
+
+// formatAmount renders an amount for responses with exactly the currency's decimal places
+// json.Number is marshaled verbatim, so 10.5 USD is sent as the JSON number 10.50 rather than 10.5
+func formatAmount(amount float64, currency core.Currency) json.Number {
+	return json.Number(core.FormatAmount(amount, currency))
+}
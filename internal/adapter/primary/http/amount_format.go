@@ -0,0 +1,80 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"unicode"
+
+	"github.com/cashflow/payment-gateway/internal/core"
+	"github.com/labstack/echo/v4"
+	"golang.org/x/text/currency"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"golang.org/x/text/number"
+)
+
+const (
+	// FormatAmountsParam is the optional query flag that adds display-formatted amounts to responses
+	FormatAmountsParam = "format_amounts"
+	// LocaleParam is the optional BCP 47 language tag formatted amounts follow, e.g. am-ET
+	LocaleParam = "locale"
+
+	amountPrinterContextKey = "amount_printer"
+)
+
+// DefaultAmountLocale is the locale amounts are formatted in when the request sets no locale
+var DefaultAmountLocale = language.AmericanEnglish
+
+// AmountFormat resolves the request's format_amounts and locale parameters and stores a printer
+// for the locale on the context
+// Requests without format_amounts=true skip formatting altogether, so they pay nothing for it
+func AmountFormat() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			raw := c.QueryParam(FormatAmountsParam)
+			if raw == "" {
+				return next(c)
+			}
+			enabled, err := strconv.ParseBool(raw)
+			if err != nil {
+				return respondError(c, http.StatusBadRequest, ErrCodeInvalidParameter, "format_amounts must be true or false")
+			}
+			if !enabled {
+				return next(c)
+			}
+
+			tag := DefaultAmountLocale
+			if name := c.QueryParam(LocaleParam); name != "" {
+				if tag, err = language.Parse(name); err != nil {
+					return respondError(c, http.StatusBadRequest, ErrCodeInvalidLocale, "locale must be a BCP 47 language tag such as en-US or am-ET")
+				}
+			}
+			c.Set(amountPrinterContextKey, message.NewPrinter(tag))
+			return next(c)
+		}
+	}
+}
+
+// formatDisplayAmount renders amount for display in the request's locale, e.g. "$10.50" or "ETB 10.50",
+// or returns "" unless the request asked for formatted amounts
+// The symbol and digit grouping follow the locale; the decimal places are the currency's own
+func formatDisplayAmount(c echo.Context, amount float64, code core.Currency) string {
+	printer, ok := c.Get(amountPrinterContextKey).(*message.Printer)
+	if !ok {
+		return ""
+	}
+	digits := printer.Sprint(number.Decimal(core.RoundAmount(amount, code), number.Scale(code.Decimals())))
+
+	unit, err := currency.ParseISO(string(code))
+	if err != nil {
+		return string(code) + " " + digits
+	}
+	symbol := printer.Sprint(currency.Symbol(unit))
+	// Letter symbols such as "ETB" read better apart from the digits, sign symbols such as "$" do not
+	for _, r := range symbol {
+		if unicode.IsLetter(r) {
+			return symbol + " " + digits
+		}
+	}
+	return symbol + digits
+}
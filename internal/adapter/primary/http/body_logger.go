@@ -0,0 +1,114 @@
+package http
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"log/slog"
+	"math/rand"
+	"net"
+	"net/http"
+
+	"github.com/cashflow/payment-gateway/internal/logger"
+	"github.com/labstack/echo/v4"
+)
+
+// BodyLogConfig configures the debug body logger
+type BodyLogConfig struct {
+	SampleRate float64 // Fraction of requests logged, between 0 and 1
+	MaxBytes   int     // Maximum bytes captured from each body
+	Redactor   *logger.Redactor
+}
+
+// BodyLogger logs a sample of request and response bodies for debugging
+// Captured bodies are capped at MaxBytes and redacted before logging; it is meant for staging only
+func BodyLogger(cfg BodyLogConfig) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if rand.Float64() >= cfg.SampleRate {
+				return next(c)
+			}
+
+			// Capture the request body as the handler reads it
+			reqBody := &limitedBuffer{limit: cfg.MaxBytes}
+			req := c.Request()
+			if req.Body != nil {
+				req.Body = struct {
+					io.Reader
+					io.Closer
+				}{io.TeeReader(req.Body, reqBody), req.Body}
+			}
+
+			// Capture the response body as the handler writes it
+			resBody := &limitedBuffer{limit: cfg.MaxBytes}
+			res := c.Response()
+			res.Writer = &bodyCaptureWriter{ResponseWriter: res.Writer, capture: resBody}
+
+			err := next(c)
+
+			slog.Info("Body log",
+				"method", req.Method,
+				"path", req.URL.Path,
+				"status", res.Status,
+				"request_body", reqBody.redacted(cfg.Redactor),
+				"response_body", resBody.redacted(cfg.Redactor),
+			)
+			return err
+		}
+	}
+}
+
+// limitedBuffer keeps the first limit bytes written to it and discards the rest
+type limitedBuffer struct {
+	buf       bytes.Buffer
+	limit     int
+	truncated bool
+}
+
+// Write always reports success so the wrapped stream is unaffected
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	remaining := b.limit - b.buf.Len()
+	if len(p) > remaining {
+		b.truncated = true
+		if remaining > 0 {
+			b.buf.Write(p[:remaining])
+		}
+		return len(p), nil
+	}
+	b.buf.Write(p)
+	return len(p), nil
+}
+
+// redacted returns the captured body with sensitive values masked
+func (b *limitedBuffer) redacted(redactor *logger.Redactor) string {
+	body := redactor.RedactJSON(b.buf.Bytes())
+	if b.truncated {
+		body += "...(truncated)"
+	}
+	return body
+}
+
+// bodyCaptureWriter copies everything written to the response into a capture buffer
+type bodyCaptureWriter struct {
+	http.ResponseWriter
+	capture io.Writer
+}
+
+func (w *bodyCaptureWriter) Write(p []byte) (int, error) {
+	w.capture.Write(p)
+	return w.ResponseWriter.Write(p)
+}
+
+func (w *bodyCaptureWriter) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (w *bodyCaptureWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if hijacker, ok := w.ResponseWriter.(http.Hijacker); ok {
+		return hijacker.Hijack()
+	}
+	return nil, nil, errors.New("response writer does not support hijacking")
+}
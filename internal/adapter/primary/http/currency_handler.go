@@ -0,0 +1,57 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/cashflow/payment-gateway/internal/port/input"
+	"github.com/labstack/echo/v4"
+)
+
+// CurrencyHandler is a primary adapter (HTTP handler) for the currency registry
+type CurrencyHandler struct {
+	currencyService input.CurrencyService
+}
+
+// NewCurrencyHandler creates a new currency handler
+func NewCurrencyHandler(currencyService input.CurrencyService) *CurrencyHandler {
+	return &CurrencyHandler{
+		currencyService: currencyService,
+	}
+}
+
+// CurrencyResponse represents the HTTP response for a supported currency
+type CurrencyResponse struct {
+	Code          string      `json:"code"`
+	DecimalPlaces int         `json:"decimal_places"`
+	MinAmount     json.Number `json:"min_amount"`
+	MaxAmount     json.Number `json:"max_amount"`
+
+	MinProcessable json.Number `json:"min_processable_amount"`
+}
+
+// ListCurrenciesResponse represents the HTTP response for the supported currencies
+type ListCurrenciesResponse struct {
+	Currencies []CurrencyResponse `json:"currencies"`
+}
+
+// ListCurrencies handles listing the currencies payments can be created in
+func (h *CurrencyHandler) ListCurrencies(c echo.Context) error {
+	currencies := h.currencyService.ListCurrencies()
+
+	httpResponse := ListCurrenciesResponse{
+		Currencies: make([]CurrencyResponse, 0, len(currencies)),
+	}
+	for _, currency := range currencies {
+		httpResponse.Currencies = append(httpResponse.Currencies, CurrencyResponse{
+			Code:          string(currency.Code),
+			DecimalPlaces: currency.Decimals,
+			MinAmount:     formatAmount(currency.MinAmount, currency.Code),
+			MaxAmount:     formatAmount(currency.MaxAmount, currency.Code),
+
+			MinProcessable: formatAmount(max(currency.MinProcessable, currency.MinAmount), currency.Code),
+		})
+	}
+
+	return respondData(c, http.StatusOK, httpResponse)
+}
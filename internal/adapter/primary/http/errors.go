@@ -0,0 +1,108 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/cashflow/payment-gateway/internal/core"
+	"github.com/cashflow/payment-gateway/internal/port/input"
+	"github.com/labstack/echo/v4"
+)
+
+// errorMapping pairs a core sentinel error with the HTTP status and code it is reported as
+type errorMapping struct {
+	err    error
+	status int
+	code   string
+}
+
+// errorMappings is the single source of truth for how service errors reach clients
+// The first entry whose sentinel matches (errors.Is) wins
+var errorMappings = []errorMapping{
+	{core.ErrPaymentNotFound, http.StatusNotFound, ErrCodePaymentNotFound},
+	{core.ErrPaymentAlreadyProcessed, http.StatusConflict, ErrCodePaymentAlreadyProcessed},
+	{core.ErrPaymentNotProcessed, http.StatusConflict, ErrCodePaymentNotProcessed},
+	{core.ErrReferenceExists, http.StatusConflict, ErrCodeReferenceExists},
+	{core.ErrPaymentStatusUnchanged, http.StatusConflict, ErrCodePaymentStatusUnchanged},
+	{core.ErrInvalidTransition, http.StatusConflict, ErrCodeInvalidTransition},
+	{core.ErrInvalidIdempotencyKey, http.StatusBadRequest, ErrCodeInvalidIdempotencyKey},
+	{core.ErrIdempotencyKeyInUse, http.StatusConflict, ErrCodeIdempotencyKeyInUse},
+	{core.ErrIdempotencyKeyReused, http.StatusUnprocessableEntity, ErrCodeIdempotencyKeyReused},
+	{core.ErrInvalidAmount, http.StatusBadRequest, ErrCodeInvalidAmount},
+	{core.ErrInvalidCurrency, http.StatusBadRequest, ErrCodeInvalidCurrency},
+	{core.ErrAmountNotProcessable, http.StatusBadRequest, ErrCodeAmountNotProcessable},
+	{core.ErrInvalidReference, http.StatusBadRequest, ErrCodeInvalidReference},
+	{core.ErrInvalidRefundID, http.StatusBadRequest, ErrCodeInvalidRefundID},
+	{core.ErrInvalidExpiry, http.StatusBadRequest, ErrCodeInvalidExpiry},
+	{core.ErrInvalidTags, http.StatusBadRequest, ErrCodeInvalidTags},
+	{core.ErrInvalidDescription, http.StatusBadRequest, ErrCodeInvalidDescription},
+	{core.ErrInvalidCustomerID, http.StatusBadRequest, ErrCodeInvalidCustomerID},
+	{core.ErrInvalidCustomerEmail, http.StatusBadRequest, ErrCodeInvalidCustomerEmail},
+	{core.ErrInvalidSource, http.StatusBadRequest, ErrCodeInvalidSource},
+	{core.ErrInvalidMethod, http.StatusBadRequest, ErrCodeInvalidMethod},
+	{core.ErrInvalidParentPayment, http.StatusBadRequest, ErrCodeInvalidParentPayment},
+	{core.ErrInvalidParameter, http.StatusBadRequest, ErrCodeInvalidParameter},
+	{core.ErrPaymentNotRefundable, http.StatusUnprocessableEntity, ErrCodePaymentNotRefundable},
+	{core.ErrRefundExceedsBalance, http.StatusUnprocessableEntity, ErrCodeRefundExceedsBalance},
+	{core.ErrRefundIDConflict, http.StatusConflict, ErrCodeRefundIDConflict},
+	{core.ErrPaymentNotCapturable, http.StatusUnprocessableEntity, ErrCodePaymentNotCapturable},
+	{core.ErrPaymentNotVoidable, http.StatusUnprocessableEntity, ErrCodePaymentNotVoidable},
+	{core.ErrCaptureExceedsAuthorization, http.StatusUnprocessableEntity, ErrCodeCaptureExceedsAuth},
+	{core.ErrInstallmentsExceedTotal, http.StatusUnprocessableEntity, ErrCodeInstallmentsExceedTotal},
+	{core.ErrPaymentNotInReview, http.StatusUnprocessableEntity, ErrCodePaymentNotInReview},
+	{core.ErrPaymentDeclined, http.StatusUnprocessableEntity, ErrCodePaymentDeclined},
+	{core.ErrVelocityLimitExceeded, http.StatusTooManyRequests, ErrCodeVelocityLimitExceeded},
+	{core.ErrLimitExceeded, http.StatusUnprocessableEntity, ErrCodeLimitExceeded},
+	{core.ErrDeliveryNotFound, http.StatusNotFound, ErrCodeDeliveryNotFound},
+	{core.ErrDeliveryPending, http.StatusConflict, ErrCodeDeliveryPending},
+	{core.ErrWebhookURLNotConfigured, http.StatusUnprocessableEntity, ErrCodeWebhookURLNotConfigured},
+	{core.ErrExportNotFound, http.StatusNotFound, ErrCodeExportNotFound},
+	{core.ErrExportsNotConfigured, http.StatusUnprocessableEntity, ErrCodeExportsNotConfigured},
+	{core.ErrReadOnly, http.StatusServiceUnavailable, ErrCodeReadOnly},
+	{core.ErrPaymentNotEnqueued, http.StatusServiceUnavailable, ErrCodePaymentNotEnqueued},
+}
+
+// lookupErrorMapping finds the mapping for err, if any
+func lookupErrorMapping(err error) (errorMapping, bool) {
+	for _, mapping := range errorMappings {
+		if errors.Is(err, mapping.err) {
+			return mapping, true
+		}
+	}
+	return errorMapping{}, false
+}
+
+// respondServiceError writes the error envelope for an error returned by a service
+// Validation errors list their field problems; sentinel errors use the mapping table;
+// anything else is an internal error reported with fallbackMessage
+func respondServiceError(c echo.Context, err error, fallbackMessage string) error {
+	var validationErr *input.ValidationError
+	if errors.As(err, &validationErr) {
+		return respondValidationError(c, validationErr)
+	}
+
+	mapping, ok := lookupErrorMapping(err)
+	if !ok {
+		return respondError(c, http.StatusInternalServerError, ErrCodeInternal, fallbackMessage)
+	}
+	return respondError(c, mapping.status, mapping.code, sentinelMessage(err, mapping.err))
+}
+
+// sentinelMessage strips the "failed to ..." context callers wrapped around a sentinel,
+// keeping the sentinel text and any detail appended after it
+func sentinelMessage(err, sentinel error) string {
+	message := err.Error()
+	if i := strings.Index(message, sentinel.Error()); i >= 0 {
+		return message[i:]
+	}
+	return sentinel.Error()
+}
+
+// fieldErrorCode returns the code for a single field problem
+func fieldErrorCode(field input.FieldError) string {
+	if mapping, ok := lookupErrorMapping(field.Err); ok {
+		return mapping.code
+	}
+	return ErrCodeValidationFailed
+}
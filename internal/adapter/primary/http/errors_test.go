@@ -0,0 +1,59 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cashflow/payment-gateway/internal/core"
+	"github.com/labstack/echo/v4"
+)
+
+func TestRespondServiceErrorMapsSentinels(t *testing.T) {
+	tests := []struct {
+		name        string
+		err         error
+		wantStatus  int
+		wantCode    string
+		wantMessage string
+	}{
+		{
+			// What the repository returns when a concurrent request moved the payment first
+			name:        "invalid transition",
+			err:         fmt.Errorf("failed to void payment: %w: %s to %s", core.ErrInvalidTransition, core.PaymentStatusSuccess, core.PaymentStatusCancelled),
+			wantStatus:  http.StatusConflict,
+			wantCode:    ErrCodeInvalidTransition,
+			wantMessage: "invalid payment status transition: SUCCESS to CANCELLED",
+		},
+		{
+			name:        "unmapped error",
+			err:         fmt.Errorf("failed to void payment: connection reset"),
+			wantStatus:  http.StatusInternalServerError,
+			wantCode:    ErrCodeInternal,
+			wantMessage: "Failed to void payment",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			rec := httptest.NewRecorder()
+
+			if err := respondServiceError(echo.New().NewContext(req, rec), tt.err, "Failed to void payment"); err != nil {
+				t.Fatalf("respondServiceError: %v", err)
+			}
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status: got %d, want %d", rec.Code, tt.wantStatus)
+			}
+			var envelope ErrorEnvelope
+			if err := json.Unmarshal(rec.Body.Bytes(), &envelope); err != nil {
+				t.Fatalf("failed to decode body %q: %v", rec.Body.String(), err)
+			}
+			if envelope.Error.Code != tt.wantCode || envelope.Error.Message != tt.wantMessage {
+				t.Errorf("error: got %+v, want %s: %s", envelope.Error, tt.wantCode, tt.wantMessage)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package http
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/cashflow/payment-gateway/internal/port/input"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// ExportHandler is a primary adapter (HTTP handler) for bulk payment exports
+type ExportHandler struct {
+	exportService input.ExportService
+}
+
+// NewExportHandler creates a new export handler
+func NewExportHandler(exportService input.ExportService) *ExportHandler {
+	return &ExportHandler{
+		exportService: exportService,
+	}
+}
+
+// StartExportRequest represents the HTTP request to export a day's payments
+type StartExportRequest struct {
+	Date string `json:"date"` // YYYY-MM-DD, a UTC day
+}
+
+// ExportResponse represents the HTTP response for an export
+type ExportResponse struct {
+	ID          string  `json:"id"`
+	Date        string  `json:"date"`
+	Status      string  `json:"status"`
+	ObjectKey   string  `json:"object_key"`
+	RowCount    int64   `json:"row_count"`
+	Error       string  `json:"error,omitempty"`
+	CreatedAt   string  `json:"created_at"`
+	CompletedAt *string `json:"completed_at,omitempty"`
+}
+
+// StartExport handles starting a background export of a day's payments
+func (h *ExportHandler) StartExport(c echo.Context) error {
+	var req StartExportRequest
+	if err := c.Bind(&req); err != nil {
+		return respondError(c, http.StatusBadRequest, ErrCodeInvalidRequestBody, "Invalid request body")
+	}
+	day, err := time.Parse(time.DateOnly, req.Date)
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, ErrCodeInvalidParameter, "date must be a day such as 2024-01-31")
+	}
+
+	// Call service (input port)
+	response, err := h.exportService.StartExport(input.StartExportRequest{Day: day})
+	if err != nil {
+		return respondServiceError(c, err, "Failed to start export")
+	}
+
+	return respondData(c, http.StatusAccepted, toHTTPExportResponse(c, response))
+}
+
+// GetExport handles retrieving an export to follow its progress
+func (h *ExportHandler) GetExport(c echo.Context) error {
+	exportID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, ErrCodeInvalidExportID, "Invalid export ID")
+	}
+
+	// Call service (input port)
+	response, err := h.exportService.GetExport(exportID)
+	if err != nil {
+		return respondServiceError(c, err, "Failed to get export")
+	}
+
+	return respondData(c, http.StatusOK, toHTTPExportResponse(c, response))
+}
+
+// toHTTPExportResponse converts a service export to the HTTP response
+func toHTTPExportResponse(c echo.Context, response *input.ExportResponse) ExportResponse {
+	httpResponse := ExportResponse{
+		ID:        response.ID.String(),
+		Date:      response.Day.Format(time.DateOnly),
+		Status:    string(response.Status),
+		ObjectKey: response.ObjectKey,
+		RowCount:  response.RowCount,
+		Error:     response.Error,
+		CreatedAt: formatTimestamp(c, response.CreatedAt),
+	}
+	if response.CompletedAt != nil {
+		completedAt := formatTimestamp(c, *response.CompletedAt)
+		httpResponse.CompletedAt = &completedAt
+	}
+	return httpResponse
+}
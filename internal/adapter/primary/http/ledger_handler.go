@@ -0,0 +1,67 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/cashflow/payment-gateway/internal/port/input"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// LedgerHandler is a primary adapter (HTTP handler) for ledger queries
+type LedgerHandler struct {
+	ledgerService input.LedgerService
+}
+
+// NewLedgerHandler creates a new ledger handler
+func NewLedgerHandler(ledgerService input.LedgerService) *LedgerHandler {
+	return &LedgerHandler{
+		ledgerService: ledgerService,
+	}
+}
+
+// LedgerEntryResponse represents the HTTP response for a ledger entry
+type LedgerEntryResponse struct {
+	ID        string      `json:"id"`
+	PaymentID string      `json:"payment_id"`
+	Account   string      `json:"account"`
+	Direction string      `json:"direction"`
+	Amount    json.Number `json:"amount"`
+	Currency  string      `json:"currency"`
+	CreatedAt string      `json:"created_at"`
+}
+
+// GetPaymentLedger handles retrieval of a payment's ledger entries
+func (h *LedgerHandler) GetPaymentLedger(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, ErrCodeInvalidPaymentID, "Invalid payment ID")
+	}
+
+	// Call service (input port)
+	entries, err := h.ledgerService.GetPaymentLedger(id)
+	if err != nil {
+		return respondServiceError(c, err, "Failed to retrieve ledger")
+	}
+
+	// Convert to HTTP response
+	return respondData(c, http.StatusOK, toHTTPLedgerEntryResponses(c, entries))
+}
+
+// toHTTPLedgerEntryResponses converts service ledger entries to HTTP responses
+func toHTTPLedgerEntryResponses(c echo.Context, entries []input.LedgerEntryResponse) []LedgerEntryResponse {
+	httpResponse := make([]LedgerEntryResponse, 0, len(entries))
+	for _, entry := range entries {
+		httpResponse = append(httpResponse, LedgerEntryResponse{
+			ID:        entry.ID.String(),
+			PaymentID: entry.PaymentID.String(),
+			Account:   entry.Account,
+			Direction: string(entry.Direction),
+			Amount:    formatAmount(entry.Amount, entry.Currency),
+			Currency:  string(entry.Currency),
+			CreatedAt: formatTimestamp(c, entry.CreatedAt),
+		})
+	}
+	return httpResponse
+}
@@ -0,0 +1,239 @@
+package http
+
+import (
+	"crypto/subtle"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cashflow/payment-gateway/internal/core"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	"golang.org/x/time/rate"
+)
+
+const (
+	// MerchantIDHeader carries the merchant the request is scoped to
+	MerchantIDHeader = "X-Merchant-ID"
+
+	merchantIDContextKey = "merchant_id"
+	adminActorContextKey = "admin_actor"
+	maxMerchantIDLength  = 64
+)
+
+// MerchantScope resolves the merchant the request is scoped to and stores it on the context
+// Requests without the header are unscoped
+func MerchantScope() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			merchantID := c.Request().Header.Get(MerchantIDHeader)
+			if len(merchantID) > maxMerchantIDLength {
+				return respondError(c, http.StatusBadRequest, ErrCodeInvalidMerchantID, "Invalid merchant ID")
+			}
+			c.Set(merchantIDContextKey, merchantID)
+			return next(c)
+		}
+	}
+}
+
+// merchantIDFromContext returns the merchant the request is scoped to, or "" if unscoped
+func merchantIDFromContext(c echo.Context) string {
+	merchantID, _ := c.Get(merchantIDContextKey).(string)
+	return merchantID
+}
+
+// AdminCredentials maps each operator's name to the bearer token they authenticate with
+type AdminCredentials map[string]string
+
+// DefaultAdminActor is the operator name of the shared ADMIN_API_KEY token
+const DefaultAdminActor = "admin"
+
+// AdminAuth guards admin endpoints with per-operator bearer tokens, storing the operator the
+// token belongs to on the context, where handlers that record who acted read it
+// When no token is configured the admin API is disabled
+func AdminAuth(credentials AdminCredentials) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if len(credentials) == 0 {
+				return respondError(c, http.StatusForbidden, ErrCodeAdminDisabled, "Admin API is disabled")
+			}
+
+			// Every token is compared, so the response time doesn't reveal which ones were tried
+			token := strings.TrimPrefix(c.Request().Header.Get(echo.HeaderAuthorization), "Bearer ")
+			actor := ""
+			for operator, key := range credentials {
+				if subtle.ConstantTimeCompare([]byte(token), []byte(key)) == 1 {
+					actor = operator
+				}
+			}
+			if actor == "" {
+				return respondError(c, http.StatusUnauthorized, ErrCodeUnauthorized, "Invalid admin credentials")
+			}
+			c.Set(adminActorContextKey, actor)
+			return next(c)
+		}
+	}
+}
+
+// adminActorFromContext returns the operator the admin request authenticated as
+func adminActorFromContext(c echo.Context) string {
+	actor, _ := c.Get(adminActorContextKey).(string)
+	return actor
+}
+
+// ReadOnly rejects write requests with 503 while the gateway is in read-only (maintenance) mode
+// GET, HEAD and OPTIONS pass through, as do the given route paths (e.g. dry-run validation)
+// The services enforce the same rule, so this only saves the work of binding a doomed request
+func ReadOnly(allowedPaths ...string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			switch c.Request().Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+				return next(c)
+			}
+			for _, path := range allowedPaths {
+				if c.Path() == path {
+					return next(c)
+				}
+			}
+			return respondServiceError(c, core.ErrReadOnly, "Failed to handle request")
+		}
+	}
+}
+
+// MaxInFlight sheds load by rejecting requests with 503 while limit requests are already being
+// handled, so an overload queues in clients rather than in the database pool and the broker
+// Rejected requests never reach the handler and are safe to retry after Retry-After
+func MaxInFlight(limit int) echo.MiddlewareFunc {
+	slots := make(chan struct{}, limit)
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			select {
+			case slots <- struct{}{}:
+				defer func() { <-slots }()
+				return next(c)
+			default:
+				return respondError(c, http.StatusServiceUnavailable, ErrCodeOverloaded, "Too many requests in flight, retry later")
+			}
+		}
+	}
+}
+
+// RateLimit allows limit requests per period across all callers of the routes it guards, then
+// rejects them with 429 until the allowance refills
+// It is meant for rare, dangerous operations where a runaway script must be stopped early;
+// the allowance lives in process memory, so each API instance has its own
+func RateLimit(limit int, period time.Duration) echo.MiddlewareFunc {
+	store := middleware.NewRateLimiterMemoryStoreWithConfig(middleware.RateLimiterMemoryStoreConfig{
+		Rate:      rate.Limit(float64(limit) / period.Seconds()),
+		Burst:     limit,
+		ExpiresIn: period,
+	})
+	return middleware.RateLimiterWithConfig(middleware.RateLimiterConfig{
+		Store: store,
+		IdentifierExtractor: func(c echo.Context) (string, error) {
+			return c.Path(), nil
+		},
+		DenyHandler: func(c echo.Context, identifier string, err error) error {
+			return respondError(c, http.StatusTooManyRequests, ErrCodeRateLimited, "Rate limit exceeded, retry later")
+		},
+	})
+}
+
+// RetryAfter sets a Retry-After header on every 503 response, whichever handler or
+// middleware wrote it, so clients back off instead of retrying immediately
+// The delay is rounded up to whole seconds, the header's unit
+// Register it with e.Pre so it also covers requests rejected before routing
+func RetryAfter(delay time.Duration) echo.MiddlewareFunc {
+	seconds := strconv.FormatInt(int64(math.Ceil(delay.Seconds())), 10)
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			response := c.Response()
+			response.Before(func() {
+				if response.Status == http.StatusServiceUnavailable {
+					response.Header().Set(echo.HeaderRetryAfter, seconds)
+				}
+			})
+			return next(c)
+		}
+	}
+}
+
+// Gzip compresses responses for clients that send Accept-Encoding: gzip
+// Bodies shorter than minLength are sent as-is, since compressing them costs more than it saves
+// Server-sent event streams are never compressed: the gzip writer buffers output and would hold
+// events back until the buffer fills
+func Gzip(level, minLength int) echo.MiddlewareFunc {
+	return middleware.GzipWithConfig(middleware.GzipConfig{
+		Level:     level,
+		MinLength: minLength,
+		Skipper: func(c echo.Context) bool {
+			return strings.Contains(c.Request().Header.Get(echo.HeaderAccept), "text/event-stream")
+		},
+	})
+}
+
+// forwardedHeaders are set by reverse proxies and trivially spoofable by clients
+var forwardedHeaders = []string{
+	echo.HeaderXForwardedFor,
+	echo.HeaderXForwardedProto,
+	echo.HeaderXForwardedProtocol,
+	echo.HeaderXForwardedSsl,
+	echo.HeaderXUrlScheme,
+	echo.HeaderXRealIP,
+	"X-Forwarded-Host",
+}
+
+// TrustedProxies strips X-Forwarded-* headers unless the request comes directly from a trusted proxy,
+// so scheme, host and client IP derived from them cannot be spoofed
+// Register it with e.Pre so every later middleware sees the sanitized headers
+func TrustedProxies(trusted []*net.IPNet) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if !isTrustedPeer(c.Request().RemoteAddr, trusted) {
+				for _, header := range forwardedHeaders {
+					c.Request().Header.Del(header)
+				}
+			}
+			return next(c)
+		}
+	}
+}
+
+// IPExtractor returns an extractor that resolves the client IP through X-Forwarded-For,
+// trusting only the configured proxies (and using the peer address when none are configured)
+func IPExtractor(trusted []*net.IPNet) echo.IPExtractor {
+	if len(trusted) == 0 {
+		return echo.ExtractIPDirect()
+	}
+	options := []echo.TrustOption{
+		echo.TrustLoopback(false),
+		echo.TrustLinkLocal(false),
+		echo.TrustPrivateNet(false),
+	}
+	for _, ipRange := range trusted {
+		options = append(options, echo.TrustIPRange(ipRange))
+	}
+	return echo.ExtractIPFromXFFHeader(options...)
+}
+
+// isTrustedPeer reports whether the direct peer address belongs to a trusted proxy range
+func isTrustedPeer(remoteAddr string, trusted []*net.IPNet) bool {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return false
+	}
+	for _, ipRange := range trusted {
+		if ipRange.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
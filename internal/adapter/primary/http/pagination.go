@@ -0,0 +1,56 @@
+package http
+
+import (
+	"net/url"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+// PaginationLinks holds the URLs of the neighbouring pages
+type PaginationLinks struct {
+	Next string `json:"next,omitempty"`
+	Prev string `json:"prev,omitempty"`
+}
+
+// paginationLinks builds next/prev links for an offset-paginated list
+// The current query parameters are preserved; only limit and offset change
+// A next link is only emitted when the page is full, since a short page is the last one
+func paginationLinks(c echo.Context, limit, offset, count int) PaginationLinks {
+	var links PaginationLinks
+	if count >= limit {
+		links.Next = pageURL(c, limit, offset+limit)
+	}
+	if offset > 0 {
+		prevOffset := offset - limit
+		if prevOffset < 0 {
+			prevOffset = 0
+		}
+		links.Prev = pageURL(c, limit, prevOffset)
+	}
+	return links
+}
+
+// pageURL rebuilds the request URL with the given limit and offset
+func pageURL(c echo.Context, limit, offset int) string {
+	query := c.QueryParams()
+	query.Set("limit", strconv.Itoa(limit))
+	query.Set("offset", strconv.Itoa(offset))
+
+	u := url.URL{
+		Scheme:   c.Scheme(),
+		Host:     requestHost(c),
+		Path:     c.Request().URL.Path,
+		RawQuery: query.Encode(),
+	}
+	return u.String()
+}
+
+// requestHost returns the host the client used, preferring X-Forwarded-Host set by a proxy
+// The header only survives TrustedProxies when the request came through a trusted proxy
+func requestHost(c echo.Context) string {
+	if host := c.Request().Header.Get("X-Forwarded-Host"); host != "" {
+		return host
+	}
+	return c.Request().Host
+}
@@ -1,7 +1,11 @@
 package http
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -24,70 +28,212 @@ func NewPaymentHandler(paymentService input.PaymentService) *PaymentHandler {
 }
 
 // CreatePaymentRequest represents the HTTP request to create a payment
+// Amount accepts both JSON numbers and decimal strings
 type CreatePaymentRequest struct {
-	Amount    float64 `json:"amount"`
-	Currency  string  `json:"currency"`
-	Reference string  `json:"reference"`
+	Amount        Amount     `json:"amount"`
+	Currency      string     `json:"currency"`
+	Reference     string     `json:"reference"`
+	GenerateRef   bool       `json:"generate_reference"` // Generate the reference when it is empty
+	Description   string     `json:"description"`
+	CustomerID    string     `json:"customer_id"`
+	CustomerEmail string     `json:"customer_email"`
+	Method        string     `json:"method"`
+	Capture       *bool      `json:"capture"`
+	Test          bool       `json:"test"`
+	Tags          []string   `json:"tags"`
+	ExpiresAt     *time.Time `json:"expires_at"`
+	TTLSeconds    int        `json:"ttl_seconds"`
+
+	ParentPaymentID *uuid.UUID `json:"parent_payment_id"` // Create the payment as an installment of this one
 }
 
 // PaymentResponse represents the HTTP response for a payment
+// Fields tagged without omitempty are part of the contract and always present, even at their zero
+// value: status, amount and is_test are never dropped, and tags is [] rather than null. Optional
+// fields are omitted when unset instead of being sent as "" or null
 type PaymentResponse struct {
-	ID        string  `json:"id"`
-	Amount    float64 `json:"amount"`
-	Currency  string  `json:"currency"`
-	Reference string  `json:"reference"`
-	Status    string  `json:"status"`
-	CreatedAt string  `json:"created_at"`
+	ID               string      `json:"id"`
+	MerchantID       string      `json:"merchant_id,omitempty"`
+	Amount           json.Number `json:"amount"`
+	FormattedAmount  string      `json:"formatted_amount,omitempty"` // Display only, with format_amounts=true
+	Currency         string      `json:"currency"`
+	Reference        string      `json:"reference"`
+	Description      string      `json:"description,omitempty"`
+	CustomerID       string      `json:"customer_id,omitempty"`
+	CustomerEmail    string      `json:"customer_email,omitempty"`
+	Status           string      `json:"status"`
+	Source           string      `json:"source"`
+	Method           string      `json:"method"`
+	IsTest           bool        `json:"is_test"`
+	AuthorizedAmount json.Number `json:"authorized_amount,omitempty"`
+	Tags             []string    `json:"tags"`
+	ExpiresAt        string      `json:"expires_at,omitempty"`
+	ParentPaymentID  string      `json:"parent_payment_id,omitempty"`
+	Attempts         int         `json:"attempts"`
+	CreatedAt        string      `json:"created_at"`
+	UpdatedAt        string      `json:"updated_at"`
+
+	// Related records, only present when requested with ?include=
+	Events *[]PaymentEventResponse `json:"events,omitempty"`
+	Ledger *[]LedgerEntryResponse  `json:"ledger,omitempty"`
+}
+
+// PaymentEventResponse represents the HTTP response for a payment status transition
+type PaymentEventResponse struct {
+	ID         string `json:"id"`
+	FromStatus string `json:"from_status,omitempty"`
+	ToStatus   string `json:"to_status"`
+	Note       string `json:"note,omitempty"`
+	Actor      string `json:"actor,omitempty"`
+	CreatedAt  string `json:"created_at"`
+}
+
+// CreatePaymentResponse represents the HTTP response for a created payment
+type CreatePaymentResponse struct {
+	PaymentResponse
+	Enqueued bool `json:"enqueued"`
+}
+
+// ListPaymentsResponse represents the HTTP response for a page of payments
+type ListPaymentsResponse struct {
+	Payments []PaymentResponse `json:"payments"`
+	Limit    int               `json:"limit"`
+	Offset   int               `json:"offset"`
+	Total    *int64            `json:"total,omitempty"`
+	Links    PaginationLinks   `json:"links"`
+}
+
+// ListChildPaymentsResponse represents the HTTP response for a parent payment's installments
+// total_amount is paid_amount plus pending_amount plus remaining_amount
+type ListChildPaymentsResponse struct {
+	ParentPaymentID string            `json:"parent_payment_id"`
+	Currency        string            `json:"currency"`
+	TotalAmount     json.Number       `json:"total_amount"`
+	PaidAmount      json.Number       `json:"paid_amount"`
+	PendingAmount   json.Number       `json:"pending_amount"`
+	RemainingAmount json.Number       `json:"remaining_amount"`
+	Children        []PaymentResponse `json:"children"`
+}
+
+// ListPaymentEventsResponse represents the HTTP response for a page of a payment's events
+type ListPaymentEventsResponse struct {
+	Events []PaymentEventResponse `json:"events"`
+	Order  string                 `json:"order"`
+	Limit  int                    `json:"limit"`
+	Offset int                    `json:"offset"`
+	Links  PaginationLinks        `json:"links"`
 }
 
+// IdempotencyKeyHeader carries the client's key making create retries safe
+const IdempotencyKeyHeader = "Idempotency-Key"
+
 // CreatePayment handles payment creation
+// A retry with an already-used Idempotency-Key returns the original payment with 200 instead of 201
 func (h *PaymentHandler) CreatePayment(c echo.Context) error {
 	var req CreatePaymentRequest
 	if err := c.Bind(&req); err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{
-			"error": "Invalid request body",
-		})
+		return respondError(c, http.StatusBadRequest, ErrCodeInvalidRequestBody, "Invalid request body")
 	}
 
 	// Convert to service request
-	serviceReq := input.CreatePaymentRequest{
-		Amount:    req.Amount,
-		Currency:  core.Currency(req.Currency),
-		Reference: req.Reference,
-	}
+	serviceReq := toServiceCreateRequest(c, req)
+	serviceReq.IdempotencyKey = c.Request().Header.Get(IdempotencyKeyHeader)
 
 	// Call service (input port)
 	response, err := h.paymentService.CreatePayment(serviceReq)
 	if err != nil {
-		// Handle different error types
-		if strings.Contains(err.Error(), "must be greater than zero") ||
-			strings.Contains(err.Error(), "must be ETB or USD") ||
-			strings.Contains(err.Error(), "reference is required") {
-			return c.JSON(http.StatusBadRequest, map[string]string{
-				"error": err.Error(),
-			})
-		}
-		if strings.Contains(err.Error(), "already exists") {
-			return c.JSON(http.StatusConflict, map[string]string{
-				"error": err.Error(),
-			})
+		// A duplicate reference is a conflict rather than a validation failure, and gets the same
+		// body whether the service's pre-check or the database's unique index caught it
+		var validationErr *input.ValidationError
+		if errors.As(err, &validationErr) && len(validationErr.Fields) == 1 && errors.Is(err, core.ErrReferenceExists) {
+			err = core.ErrReferenceExists
 		}
-		return c.JSON(http.StatusInternalServerError, map[string]string{
-			"error": "Failed to create payment",
-		})
+		return respondServiceError(c, err, "Failed to create payment")
 	}
 
 	// Convert to HTTP response
-	httpResponse := PaymentResponse{
-		ID:        response.ID.String(),
-		Amount:    response.Amount,
-		Currency:  string(response.Currency),
-		Reference: response.Reference,
-		Status:    string(response.Status),
-		CreatedAt: response.CreatedAt.Format(time.RFC3339),
+	httpResponse := CreatePaymentResponse{
+		PaymentResponse: toHTTPPaymentResponse(c, response),
+		Enqueued:        response.Enqueued,
 	}
 
-	return c.JSON(http.StatusCreated, httpResponse)
+	status := http.StatusCreated
+	if response.Replayed {
+		status = http.StatusOK
+	}
+	return respondData(c, status, httpResponse)
+}
+
+// CapturePaymentRequest represents the HTTP request to capture an authorized payment
+// Omitting amount captures the full authorized amount
+type CapturePaymentRequest struct {
+	Amount Amount `json:"amount"`
+}
+
+// CapturePayment handles capturing (part of) an AUTHORIZED payment
+func (h *PaymentHandler) CapturePayment(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, ErrCodeInvalidPaymentID, "Invalid payment ID")
+	}
+
+	var req CapturePaymentRequest
+	if err := c.Bind(&req); err != nil {
+		return respondError(c, http.StatusBadRequest, ErrCodeInvalidRequestBody, "Invalid request body")
+	}
+
+	// Call service (input port)
+	response, err := h.paymentService.CapturePayment(input.CapturePaymentRequest{
+		PaymentID:  id,
+		MerchantID: merchantIDFromContext(c),
+		Amount:     float64(req.Amount),
+	})
+	if err != nil {
+		return respondServiceError(c, err, "Failed to capture payment")
+	}
+
+	return respondData(c, http.StatusOK, toHTTPPaymentResponse(c, response))
+}
+
+// VoidPayment handles releasing an AUTHORIZED payment without capturing it
+func (h *PaymentHandler) VoidPayment(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, ErrCodeInvalidPaymentID, "Invalid payment ID")
+	}
+
+	// Call service (input port)
+	response, err := h.paymentService.VoidPayment(input.VoidPaymentRequest{
+		PaymentID:  id,
+		MerchantID: merchantIDFromContext(c),
+	})
+	if err != nil {
+		return respondServiceError(c, err, "Failed to void payment")
+	}
+
+	return respondData(c, http.StatusOK, toHTTPPaymentResponse(c, response))
+}
+
+// ValidatePaymentResponse represents the HTTP response for a dry-run validation that passed
+// Failures are reported as validation_failed errors listing each field problem in details
+type ValidatePaymentResponse struct {
+	Valid bool `json:"valid"`
+}
+
+// ValidatePayment handles dry-run validation of a create request; nothing is persisted or published
+func (h *PaymentHandler) ValidatePayment(c echo.Context) error {
+	var req CreatePaymentRequest
+	if err := c.Bind(&req); err != nil {
+		return respondError(c, http.StatusBadRequest, ErrCodeInvalidRequestBody, "Invalid request body")
+	}
+
+	// Call service (input port)
+	err := h.paymentService.ValidatePayment(toServiceCreateRequest(c, req))
+	if err != nil {
+		return respondServiceError(c, err, "Failed to validate payment")
+	}
+
+	return respondData(c, http.StatusOK, ValidatePaymentResponse{Valid: true})
 }
 
 // GetPayment handles payment retrieval by ID
@@ -95,34 +241,274 @@ func (h *PaymentHandler) GetPayment(c echo.Context) error {
 	idStr := c.Param("id")
 	id, err := uuid.Parse(idStr)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, map[string]string{
-			"error": "Invalid payment ID",
-		})
+		return respondError(c, http.StatusBadRequest, ErrCodeInvalidPaymentID, "Invalid payment ID")
 	}
 
-	// Call service (input port)
-	response, err := h.paymentService.GetPayment(id)
+	// Call service (input port), loading related records only when requested
+	var response *input.PaymentResponse
+	if include := c.QueryParam("include"); include != "" {
+		response, err = h.paymentService.GetPaymentWithIncludes(id, strings.Split(include, ","))
+	} else {
+		response, err = h.paymentService.GetPayment(id)
+	}
 	if err != nil {
-		if strings.Contains(err.Error(), "not found") {
-			return c.JSON(http.StatusNotFound, map[string]string{
-				"error": "Payment not found",
-			})
+		return respondServiceError(c, err, "Failed to retrieve payment")
+	}
+
+	// Payments belonging to another merchant are reported as not found
+	if merchantID := merchantIDFromContext(c); merchantID != "" && response.MerchantID != merchantID {
+		return respondServiceError(c, core.ErrPaymentNotFound, "Failed to retrieve payment")
+	}
+
+	// Convert to HTTP response
+	c.Response().Header().Set("ETag", paymentETag(response.ID, response.UpdatedAt))
+	return respondData(c, http.StatusOK, toHTTPPaymentResponse(c, response))
+}
+
+// HeadPayment reports whether a payment exists without fetching it, for reconciliation probes
+// The ETag matches the one GetPayment returns, so it also tells whether the payment changed
+func (h *PaymentHandler) HeadPayment(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, ErrCodeInvalidPaymentID, "Invalid payment ID")
+	}
+
+	updatedAt, err := h.paymentService.GetPaymentVersion(id, merchantIDFromContext(c))
+	if err != nil {
+		return respondServiceError(c, err, "Failed to check payment")
+	}
+
+	c.Response().Header().Set("ETag", paymentETag(id, updatedAt))
+	return c.NoContent(http.StatusOK)
+}
+
+// paymentETag identifies a version of a payment; it changes whenever the payment is updated
+func paymentETag(id uuid.UUID, updatedAt time.Time) string {
+	return fmt.Sprintf(`"%s-%x"`, id, updatedAt.UnixNano())
+}
+
+// ListPayments handles listing payments, scoped to the request's merchant when set
+func (h *PaymentHandler) ListPayments(c echo.Context) error {
+	serviceReq := input.ListPaymentsRequest{
+		MerchantID: merchantIDFromContext(c),
+	}
+
+	var err error
+	if serviceReq.CreatedAfter, err = parseTimeParam(c, "created_after"); err != nil {
+		return respondError(c, http.StatusBadRequest, ErrCodeInvalidParameter, "created_after must be an RFC3339 timestamp")
+	}
+	if serviceReq.CreatedBefore, err = parseTimeParam(c, "created_before"); err != nil {
+		return respondError(c, http.StatusBadRequest, ErrCodeInvalidParameter, "created_before must be an RFC3339 timestamp")
+	}
+	if isTest := c.QueryParam("is_test"); isTest != "" {
+		value, err := strconv.ParseBool(isTest)
+		if err != nil {
+			return respondError(c, http.StatusBadRequest, ErrCodeInvalidParameter, "is_test must be a boolean")
 		}
-		return c.JSON(http.StatusInternalServerError, map[string]string{
-			"error": "Failed to retrieve payment",
-		})
+		serviceReq.IsTest = &value
+	}
+	if includeTotal := c.QueryParam("include_total"); includeTotal != "" {
+		value, err := strconv.ParseBool(includeTotal)
+		if err != nil {
+			return respondError(c, http.StatusBadRequest, ErrCodeInvalidParameter, "include_total must be a boolean")
+		}
+		serviceReq.IncludeTotal = value
+	}
+	serviceReq.Status = core.PaymentStatus(strings.ToUpper(c.QueryParam("status")))
+	serviceReq.Method = core.PaymentMethod(c.QueryParam("method"))
+	serviceReq.CustomerID = c.QueryParam("customer_id")
+	serviceReq.Tag = c.QueryParam("tag")
+	if serviceReq.Limit, err = parseIntParam(c, "limit"); err != nil {
+		return respondError(c, http.StatusBadRequest, ErrCodeInvalidParameter, "limit must be an integer")
+	}
+	if serviceReq.Offset, err = parseIntParam(c, "offset"); err != nil {
+		return respondError(c, http.StatusBadRequest, ErrCodeInvalidParameter, "offset must be an integer")
+	}
+
+	// Call service (input port)
+	response, err := h.paymentService.ListPayments(serviceReq)
+	if err != nil {
+		return respondServiceError(c, err, "Failed to list payments")
+	}
+
+	// Convert to HTTP response
+	httpResponse := ListPaymentsResponse{
+		Payments: make([]PaymentResponse, 0, len(response.Payments)),
+		Limit:    response.Limit,
+		Offset:   response.Offset,
+		Total:    response.Total,
+	}
+	for i := range response.Payments {
+		httpResponse.Payments = append(httpResponse.Payments, toHTTPPaymentResponse(c, &response.Payments[i]))
+	}
+	httpResponse.Links = paginationLinks(c, response.Limit, response.Offset, len(response.Payments))
+
+	return respondData(c, http.StatusOK, httpResponse)
+}
+
+// ListPaymentEvents handles paging through a payment's status history
+func (h *PaymentHandler) ListPaymentEvents(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, ErrCodeInvalidPaymentID, "Invalid payment ID")
+	}
+
+	serviceReq := input.ListPaymentEventsRequest{
+		PaymentID:  id,
+		MerchantID: merchantIDFromContext(c),
+		Order:      strings.ToLower(c.QueryParam("order")),
+	}
+	if serviceReq.Limit, err = parseIntParam(c, "limit"); err != nil {
+		return respondError(c, http.StatusBadRequest, ErrCodeInvalidParameter, "limit must be an integer")
+	}
+	if serviceReq.Offset, err = parseIntParam(c, "offset"); err != nil {
+		return respondError(c, http.StatusBadRequest, ErrCodeInvalidParameter, "offset must be an integer")
+	}
+
+	// Call service (input port)
+	response, err := h.paymentService.ListPaymentEvents(serviceReq)
+	if err != nil {
+		return respondServiceError(c, err, "Failed to list payment events")
 	}
 
 	// Convert to HTTP response
+	return respondData(c, http.StatusOK, ListPaymentEventsResponse{
+		Events: toHTTPPaymentEventResponses(c, response.Events),
+		Order:  response.Order,
+		Limit:  response.Limit,
+		Offset: response.Offset,
+		Links:  paginationLinks(c, response.Limit, response.Offset, len(response.Events)),
+	})
+}
+
+// ListChildPayments handles listing a parent payment's installments with the amount they collected so far
+func (h *PaymentHandler) ListChildPayments(c echo.Context) error {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, ErrCodeInvalidPaymentID, "Invalid payment ID")
+	}
+
+	// Call service (input port)
+	response, err := h.paymentService.ListChildPayments(id, merchantIDFromContext(c))
+	if err != nil {
+		return respondServiceError(c, err, "Failed to list child payments")
+	}
+
+	// Convert to HTTP response
+	httpResponse := ListChildPaymentsResponse{
+		ParentPaymentID: response.ParentPaymentID.String(),
+		Currency:        string(response.Currency),
+		TotalAmount:     formatAmount(response.TotalAmount, response.Currency),
+		PaidAmount:      formatAmount(response.PaidAmount, response.Currency),
+		PendingAmount:   formatAmount(response.PendingAmount, response.Currency),
+		RemainingAmount: formatAmount(response.RemainingAmount, response.Currency),
+		Children:        make([]PaymentResponse, 0, len(response.Children)),
+	}
+	for i := range response.Children {
+		httpResponse.Children = append(httpResponse.Children, toHTTPPaymentResponse(c, &response.Children[i]))
+	}
+
+	return respondData(c, http.StatusOK, httpResponse)
+}
+
+// toServiceCreateRequest converts the HTTP create request to the service request
+func toServiceCreateRequest(c echo.Context, req CreatePaymentRequest) input.CreatePaymentRequest {
+	return input.CreatePaymentRequest{
+		MerchantID:    merchantIDFromContext(c),
+		Amount:        float64(req.Amount),
+		Currency:      core.Currency(req.Currency),
+		Reference:     req.Reference,
+		Description:   req.Description,
+		CustomerID:    req.CustomerID,
+		CustomerEmail: req.CustomerEmail,
+		Source:        core.PaymentSourceAPI,
+		Method:        core.PaymentMethod(req.Method),
+		AuthOnly:      req.Capture != nil && !*req.Capture,
+		IsTest:        req.Test,
+		Tags:          req.Tags,
+		ExpiresAt:     req.ExpiresAt,
+		TTLSeconds:    req.TTLSeconds,
+		Timings:       timingsFromContext(c),
+
+		GenerateReference: req.GenerateRef,
+		ParentPaymentID:   req.ParentPaymentID,
+	}
+}
+
+// toHTTPPaymentEventResponses converts service payment events to HTTP responses
+func toHTTPPaymentEventResponses(c echo.Context, events []input.PaymentEventResponse) []PaymentEventResponse {
+	httpResponse := make([]PaymentEventResponse, 0, len(events))
+	for _, event := range events {
+		httpResponse = append(httpResponse, PaymentEventResponse{
+			ID:         event.ID.String(),
+			FromStatus: string(event.FromStatus),
+			ToStatus:   string(event.ToStatus),
+			Note:       event.Note,
+			Actor:      event.Actor,
+			CreatedAt:  formatTimestamp(c, event.CreatedAt),
+		})
+	}
+	return httpResponse
+}
+
+// toHTTPPaymentResponse converts a service response to the HTTP response
+func toHTTPPaymentResponse(c echo.Context, response *input.PaymentResponse) PaymentResponse {
 	httpResponse := PaymentResponse{
-		ID:        response.ID.String(),
-		Amount:    response.Amount,
-		Currency:  string(response.Currency),
-		Reference: response.Reference,
-		Status:    string(response.Status),
-		CreatedAt: response.CreatedAt.Format(time.RFC3339),
+		ID:            response.ID.String(),
+		MerchantID:    response.MerchantID,
+		Amount:        formatAmount(response.Amount, response.Currency),
+		Currency:      string(response.Currency),
+		Reference:     response.Reference,
+		Description:   response.Description,
+		CustomerID:    response.CustomerID,
+		CustomerEmail: response.CustomerEmail,
+		Status:        string(response.Status),
+		Source:        string(response.Source),
+		Method:        string(response.Method),
+		IsTest:        response.IsTest,
+		Tags:          response.Tags,
+		Attempts:      response.Attempts,
+		CreatedAt:     formatTimestamp(c, response.CreatedAt),
+		UpdatedAt:     formatTimestamp(c, response.UpdatedAt),
+	}
+	httpResponse.FormattedAmount = formatDisplayAmount(c, response.Amount, response.Currency)
+	if httpResponse.Tags == nil {
+		httpResponse.Tags = []string{}
+	}
+	if response.AuthOnly {
+		httpResponse.AuthorizedAmount = formatAmount(response.AuthorizedAmount, response.Currency)
 	}
+	if response.ExpiresAt != nil {
+		httpResponse.ExpiresAt = formatTimestamp(c, *response.ExpiresAt)
+	}
+	if response.ParentPaymentID != nil {
+		httpResponse.ParentPaymentID = response.ParentPaymentID.String()
+	}
+	if response.Events != nil {
+		events := toHTTPPaymentEventResponses(c, response.Events)
+		httpResponse.Events = &events
+	}
+	if response.LedgerEntries != nil {
+		ledger := toHTTPLedgerEntryResponses(c, response.LedgerEntries)
+		httpResponse.Ledger = &ledger
+	}
+	return httpResponse
+}
 
-	return c.JSON(http.StatusOK, httpResponse)
+// parseTimeParam parses an optional RFC3339 query parameter
+func parseTimeParam(c echo.Context, name string) (time.Time, error) {
+	value := c.QueryParam(name)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, value)
 }
 
+// parseIntParam parses an optional integer query parameter
+func parseIntParam(c echo.Context, name string) (int, error) {
+	value := c.QueryParam(name)
+	if value == "" {
+		return 0, nil
+	}
+	return strconv.Atoi(value)
+}
@@ -0,0 +1,93 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cashflow/payment-gateway/internal/core"
+	"github.com/cashflow/payment-gateway/internal/port/input"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// stubPaymentService is an input.PaymentService whose CreatePayment fails with createErr
+// Methods a test doesn't stub panic through the nil embedded interface
+type stubPaymentService struct {
+	input.PaymentService
+	createErr error
+}
+
+func (s *stubPaymentService) CreatePayment(req input.CreatePaymentRequest) (*input.PaymentResponse, error) {
+	return nil, s.createErr
+}
+
+func TestCreatePaymentDuplicateReferenceIsConflict(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{
+			name: "caught by the pre-check",
+			err: &input.ValidationError{Fields: []input.FieldError{
+				{Field: "reference", Message: "reference already exists", Err: core.ErrReferenceExists},
+			}},
+		},
+		{
+			// What the repository returns when the unique index rejects the second insert
+			name: "caught by the unique index",
+			err:  fmt.Errorf("failed to create payment: %w", core.ErrReferenceExists),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewPaymentHandler(&stubPaymentService{createErr: tt.err})
+			body := `{"amount": 50, "currency": "ETB", "reference": "ORDER-1"}`
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/payments", strings.NewReader(body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+
+			if err := handler.CreatePayment(echo.New().NewContext(req, rec)); err != nil {
+				t.Fatalf("CreatePayment: %v", err)
+			}
+
+			if rec.Code != http.StatusConflict {
+				t.Errorf("status: got %d, want %d", rec.Code, http.StatusConflict)
+			}
+			var envelope ErrorEnvelope
+			if err := json.Unmarshal(rec.Body.Bytes(), &envelope); err != nil {
+				t.Fatalf("failed to decode body %q: %v", rec.Body.String(), err)
+			}
+			if envelope.Error.Code != ErrCodeReferenceExists || envelope.Error.Message != core.ErrReferenceExists.Error() {
+				t.Errorf("error: got %+v, want %s: %s", envelope.Error, ErrCodeReferenceExists, core.ErrReferenceExists)
+			}
+		})
+	}
+}
+
+func TestPaymentResponseUpdatedAtFollowsRequestZone(t *testing.T) {
+	created := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	response := &input.PaymentResponse{
+		ID:        uuid.New(),
+		Amount:    100,
+		Currency:  core.CurrencyETB,
+		Status:    core.PaymentStatusSuccess,
+		CreatedAt: created,
+		UpdatedAt: created.Add(time.Second),
+	}
+	c := echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/api/v1/payments", nil), httptest.NewRecorder())
+	c.Set(timezoneContextKey, time.FixedZone("EAT", 3*60*60))
+
+	got := toHTTPPaymentResponse(c, response)
+
+	if got.UpdatedAt != "2024-01-01T15:00:01+03:00" {
+		t.Errorf("updated_at: got %q, want %q", got.UpdatedAt, "2024-01-01T15:00:01+03:00")
+	}
+	if got.CreatedAt != "2024-01-01T15:00:00+03:00" {
+		t.Errorf("created_at: got %q, want %q", got.CreatedAt, "2024-01-01T15:00:00+03:00")
+	}
+}
@@ -0,0 +1,116 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/cashflow/payment-gateway/internal/port/input"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// RefundHandler is a primary adapter (HTTP handler) for refunds
+type RefundHandler struct {
+	refundService input.RefundService
+}
+
+// NewRefundHandler creates a new refund handler
+func NewRefundHandler(refundService input.RefundService) *RefundHandler {
+	return &RefundHandler{
+		refundService: refundService,
+	}
+}
+
+// CreateRefundRequest represents the HTTP request to refund a payment
+type CreateRefundRequest struct {
+	RefundID string `json:"refund_id"`
+	Amount   Amount `json:"amount"`
+}
+
+// RefundResponse represents the HTTP response for a refund
+type RefundResponse struct {
+	ID        string      `json:"id"`
+	PaymentID string      `json:"payment_id"`
+	RefundID  string      `json:"refund_id"`
+	Amount    json.Number `json:"amount"`
+	Currency  string      `json:"currency"`
+	Status    string      `json:"status"`
+	CreatedAt string      `json:"created_at"`
+}
+
+// ListRefundsResponse represents the HTTP response for a payment's refunds
+type ListRefundsResponse struct {
+	Refunds          []RefundResponse `json:"refunds"`
+	RefundedAmount   json.Number      `json:"refunded_amount"`
+	RefundableAmount json.Number      `json:"refundable_amount"`
+}
+
+// CreateRefund handles refunding a payment
+// A retry with an already-used refund_id returns the original refund with 200 instead of 201
+func (h *RefundHandler) CreateRefund(c echo.Context) error {
+	paymentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, ErrCodeInvalidPaymentID, "Invalid payment ID")
+	}
+
+	var req CreateRefundRequest
+	if err := c.Bind(&req); err != nil {
+		return respondError(c, http.StatusBadRequest, ErrCodeInvalidRequestBody, "Invalid request body")
+	}
+
+	// Call service (input port)
+	response, err := h.refundService.CreateRefund(input.CreateRefundRequest{
+		PaymentID:  paymentID,
+		MerchantID: merchantIDFromContext(c),
+		RefundID:   req.RefundID,
+		Amount:     float64(req.Amount),
+	})
+	if err != nil {
+		return respondServiceError(c, err, "Failed to refund payment")
+	}
+
+	status := http.StatusCreated
+	if response.Replayed {
+		status = http.StatusOK
+	}
+	return respondData(c, status, toHTTPRefundResponse(c, response))
+}
+
+// ListRefunds handles listing a payment's refunds
+func (h *RefundHandler) ListRefunds(c echo.Context) error {
+	paymentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, ErrCodeInvalidPaymentID, "Invalid payment ID")
+	}
+
+	// Call service (input port)
+	response, err := h.refundService.ListRefunds(paymentID, merchantIDFromContext(c))
+	if err != nil {
+		return respondServiceError(c, err, "Failed to list refunds")
+	}
+
+	// Convert to HTTP response
+	httpResponse := ListRefundsResponse{
+		Refunds:          make([]RefundResponse, 0, len(response.Refunds)),
+		RefundedAmount:   formatAmount(response.RefundedAmount, response.Currency),
+		RefundableAmount: formatAmount(response.RefundableAmount, response.Currency),
+	}
+	for i := range response.Refunds {
+		httpResponse.Refunds = append(httpResponse.Refunds, toHTTPRefundResponse(c, &response.Refunds[i]))
+	}
+
+	return respondData(c, http.StatusOK, httpResponse)
+}
+
+// toHTTPRefundResponse converts a service refund to the HTTP response
+func toHTTPRefundResponse(c echo.Context, response *input.RefundResponse) RefundResponse {
+	return RefundResponse{
+		ID:        response.ID.String(),
+		PaymentID: response.PaymentID.String(),
+		RefundID:  response.RefundID,
+		Amount:    formatAmount(response.Amount, response.Currency),
+		Currency:  string(response.Currency),
+		Status:    string(response.Status),
+		CreatedAt: formatTimestamp(c, response.CreatedAt),
+	}
+}
@@ -0,0 +1,176 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/cashflow/payment-gateway/internal/port/input"
+	"github.com/labstack/echo/v4"
+)
+
+// Error codes returned in the "code" field of error responses
+// They are part of the API contract: messages may change, codes may not
+const (
+	ErrCodeInvalidRequestBody      = "invalid_request_body"
+	ErrCodeInvalidParameter        = "invalid_parameter"
+	ErrCodeInvalidPaymentID        = "invalid_payment_id"
+	ErrCodeInvalidDeliveryID       = "invalid_delivery_id"
+	ErrCodeInvalidMerchantID       = "invalid_merchant_id"
+	ErrCodeInvalidAmount           = "invalid_amount"
+	ErrCodeInvalidCurrency         = "invalid_currency"
+	ErrCodeAmountNotProcessable    = "amount_not_processable"
+	ErrCodeInvalidReference        = "invalid_reference"
+	ErrCodeInvalidRefundID         = "invalid_refund_id"
+	ErrCodeInvalidExpiry           = "invalid_expiry"
+	ErrCodeInvalidTags             = "invalid_tags"
+	ErrCodeInvalidDescription      = "invalid_description"
+	ErrCodeInvalidCustomerID       = "invalid_customer_id"
+	ErrCodeInvalidSource           = "invalid_source"
+	ErrCodeInvalidMethod           = "invalid_method"
+	ErrCodeInvalidCustomerEmail    = "invalid_customer_email"
+	ErrCodeInvalidParentPayment    = "invalid_parent_payment"
+	ErrCodeInvalidTimezone         = "invalid_timezone"
+	ErrCodeInvalidLocale           = "invalid_locale"
+	ErrCodeValidationFailed        = "validation_failed"
+	ErrCodePaymentNotFound         = "payment_not_found"
+	ErrCodePaymentAlreadyProcessed = "payment_already_processed"
+	ErrCodeReferenceExists         = "reference_exists"
+	ErrCodeInvalidIdempotencyKey   = "invalid_idempotency_key"
+	ErrCodeIdempotencyKeyInUse     = "idempotency_key_in_use"
+	ErrCodeIdempotencyKeyReused    = "idempotency_key_reused"
+	ErrCodeRefundIDConflict        = "refund_id_conflict"
+	ErrCodePaymentNotRefundable    = "payment_not_refundable"
+	ErrCodeRefundExceedsBalance    = "refund_exceeds_balance"
+	ErrCodePaymentNotProcessed     = "payment_not_processed"
+	ErrCodePaymentNotCapturable    = "payment_not_capturable"
+	ErrCodePaymentNotVoidable      = "payment_not_voidable"
+	ErrCodeCaptureExceedsAuth      = "capture_exceeds_authorization"
+	ErrCodeInstallmentsExceedTotal = "installments_exceed_total"
+	ErrCodePaymentNotInReview      = "payment_not_in_review"
+	ErrCodePaymentStatusUnchanged  = "payment_status_unchanged"
+	ErrCodeInvalidTransition       = "invalid_status_transition"
+	ErrCodePaymentDeclined         = "payment_declined"
+	ErrCodeLimitExceeded           = "daily_limit_exceeded"
+	ErrCodeVelocityLimitExceeded   = "velocity_limit_exceeded"
+	ErrCodeDeliveryNotFound        = "webhook_delivery_not_found"
+	ErrCodeDeliveryPending         = "webhook_delivery_pending"
+	ErrCodeWebhookURLNotConfigured = "webhook_url_not_configured"
+	ErrCodeInvalidExportID         = "invalid_export_id"
+	ErrCodeExportNotFound          = "export_not_found"
+	ErrCodeExportsNotConfigured    = "exports_not_configured"
+	ErrCodeUnauthorized            = "unauthorized"
+	ErrCodeAdminDisabled           = "admin_disabled"
+	ErrCodeReadOnly                = "read_only"
+	ErrCodePaymentNotEnqueued      = "payment_not_enqueued"
+	ErrCodeOverloaded              = "overloaded"
+	ErrCodeRateLimited             = "rate_limited"
+	ErrCodeNotFound                = "not_found"
+	ErrCodeMethodNotAllowed        = "method_not_allowed"
+	ErrCodeInternal                = "internal_error"
+)
+
+// DataEnvelope wraps every successful response body
+type DataEnvelope struct {
+	Data interface{} `json:"data"`
+}
+
+// ErrorEnvelope wraps every error response body
+type ErrorEnvelope struct {
+	Error ErrorBody `json:"error"`
+}
+
+// ErrorBody describes an error; Details is only set for errors with structured context
+type ErrorBody struct {
+	Code    string      `json:"code"`
+	Message string      `json:"message"`
+	Details interface{} `json:"details,omitempty"`
+}
+
+// respondData writes a successful response wrapped in the data envelope
+func respondData(c echo.Context, status int, data interface{}) error {
+	return c.JSON(status, DataEnvelope{Data: data})
+}
+
+// respondError writes an error response wrapped in the error envelope
+func respondError(c echo.Context, status int, code, message string) error {
+	return respondErrorDetails(c, status, code, message, nil)
+}
+
+// respondErrorDetails writes an error response with structured details
+func respondErrorDetails(c echo.Context, status int, code, message string, details interface{}) error {
+	// HEAD requests must not carry a body; the status alone answers them
+	if c.Request().Method == http.MethodHead {
+		return c.NoContent(status)
+	}
+	return c.JSON(status, ErrorEnvelope{Error: ErrorBody{
+		Code:    code,
+		Message: message,
+		Details: details,
+	}})
+}
+
+// FieldErrorResponse represents a validation problem with a single request field
+type FieldErrorResponse struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// respondValidationError writes a validation_failed error, listing each field problem in details
+// when err carries them
+func respondValidationError(c echo.Context, err error) error {
+	var validationErr *input.ValidationError
+	if !errors.As(err, &validationErr) {
+		return respondError(c, http.StatusBadRequest, ErrCodeValidationFailed, err.Error())
+	}
+
+	details := make([]FieldErrorResponse, 0, len(validationErr.Fields))
+	for _, field := range validationErr.Fields {
+		details = append(details, FieldErrorResponse{
+			Field:   field.Field,
+			Code:    fieldErrorCode(field),
+			Message: field.Message,
+		})
+	}
+	return respondErrorDetails(c, http.StatusBadRequest, ErrCodeValidationFailed, validationErr.Error(), details)
+}
+
+// ErrorHandler renders errors that reach Echo (unknown routes, wrong methods, panics recovered
+// by middleware, oversized bodies) in the error envelope instead of Echo's {"message": ...}
+func ErrorHandler(err error, c echo.Context) {
+	if c.Response().Committed {
+		return
+	}
+
+	status, code, message := http.StatusInternalServerError, ErrCodeInternal, "Internal server error"
+	if httpErr, ok := err.(*echo.HTTPError); ok {
+		status = httpErr.Code
+		if msg, ok := httpErr.Message.(string); ok {
+			message = msg
+		}
+		switch {
+		case status == http.StatusBadRequest:
+			code = ErrCodeInvalidRequestBody
+		case status == http.StatusNotFound:
+			code = ErrCodeNotFound
+		case status == http.StatusMethodNotAllowed:
+			code = ErrCodeMethodNotAllowed
+		case status >= http.StatusInternalServerError:
+			code = ErrCodeInternal
+		default:
+			// e.g. request_entity_too_large for 413
+			code = strings.ReplaceAll(strings.ToLower(http.StatusText(status)), " ", "_")
+		}
+	}
+
+	// HEAD requests must not carry a body
+	if c.Request().Method == http.MethodHead {
+		err = c.NoContent(status)
+	} else {
+		err = respondError(c, status, code, message)
+	}
+	if err != nil {
+		c.Logger().Error(err)
+	}
+}
@@ -0,0 +1,48 @@
+package http
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// TimezoneParam is the optional query parameter naming the IANA zone response timestamps are rendered in
+	TimezoneParam = "tz"
+
+	timezoneContextKey = "timezone"
+)
+
+// Timezone resolves the request's tz parameter and stores the zone on the context
+// Requests without it get UTC; only rendering changes, timestamps are stored and compared in UTC
+func Timezone() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			name := c.QueryParam(TimezoneParam)
+			if name == "" {
+				return next(c)
+			}
+			// "Local" would render in whatever zone the server happens to run in
+			location, err := time.LoadLocation(name)
+			if err != nil || name == "Local" {
+				return respondError(c, http.StatusBadRequest, ErrCodeInvalidTimezone, "tz must be an IANA time zone name such as Africa/Addis_Ababa")
+			}
+			c.Set(timezoneContextKey, location)
+			return next(c)
+		}
+	}
+}
+
+// timezoneFromContext returns the zone response timestamps are rendered in, UTC unless the request set tz
+func timezoneFromContext(c echo.Context) *time.Location {
+	if location, ok := c.Get(timezoneContextKey).(*time.Location); ok {
+		return location
+	}
+	return time.UTC
+}
+
+// formatTimestamp renders t as RFC3339 in the request's zone
+func formatTimestamp(c echo.Context, t time.Time) string {
+	return t.In(timezoneFromContext(c)).Format(time.RFC3339)
+}
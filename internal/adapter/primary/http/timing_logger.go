@@ -0,0 +1,49 @@
+package http
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/cashflow/payment-gateway/internal/port/input"
+	"github.com/labstack/echo/v4"
+)
+
+const timingsContextKey = "timings"
+
+// TimingLogger logs every request at completion with its total time split into database and
+// publish time, to tell whether slow requests are database-bound or broker-bound
+// The breakdown is accumulated by the services for payment creation and validation; other
+// requests report only their total
+func TimingLogger() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			start := time.Now()
+			timings := &input.Timings{}
+			c.Set(timingsContextKey, timings)
+
+			err := next(c)
+
+			req := c.Request()
+			slog.Info("Request completed",
+				"method", req.Method,
+				"route", c.Path(),
+				"status", c.Response().Status,
+				"total_ms", milliseconds(time.Since(start)),
+				"db_ms", milliseconds(timings.DB),
+				"publish_ms", milliseconds(timings.Publish),
+			)
+			return err
+		}
+	}
+}
+
+// timingsFromContext returns the request's timings, or nil when timing logging is off
+func timingsFromContext(c echo.Context) *input.Timings {
+	timings, _ := c.Get(timingsContextKey).(*input.Timings)
+	return timings
+}
+
+// milliseconds converts d to fractional milliseconds for logging
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
@@ -0,0 +1,42 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/cashflow/payment-gateway/internal/version"
+	"github.com/labstack/echo/v4"
+)
+
+// VersionHandler is a primary adapter (HTTP handler) reporting the running build
+type VersionHandler struct {
+	config map[string]string
+}
+
+// NewVersionHandler creates a new version handler reporting the given non-secret config summary
+func NewVersionHandler(config map[string]string) *VersionHandler {
+	return &VersionHandler{
+		config: config,
+	}
+}
+
+// VersionResponse represents the HTTP response for the running build
+type VersionResponse struct {
+	Version   string            `json:"version"`
+	Commit    string            `json:"commit"`
+	BuildTime string            `json:"build_time"`
+	GoVersion string            `json:"go_version"`
+	Config    map[string]string `json:"config"`
+}
+
+// GetVersion handles reporting the build and configuration, to confirm what a rollout deployed
+// Like /health it is outside the API and not wrapped in the response envelope
+func (h *VersionHandler) GetVersion(c echo.Context) error {
+	info := version.Get()
+	return c.JSON(http.StatusOK, VersionResponse{
+		Version:   info.Version,
+		Commit:    info.Commit,
+		BuildTime: info.BuildTime,
+		GoVersion: info.GoVersion,
+		Config:    h.config,
+	})
+}
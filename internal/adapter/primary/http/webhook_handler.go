@@ -0,0 +1,127 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/cashflow/payment-gateway/internal/core"
+	"github.com/cashflow/payment-gateway/internal/port/input"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// WebhookHandler is a primary adapter (HTTP handler) for webhook deliveries
+type WebhookHandler struct {
+	webhookService input.WebhookService
+}
+
+// NewWebhookHandler creates a new webhook handler
+func NewWebhookHandler(webhookService input.WebhookService) *WebhookHandler {
+	return &WebhookHandler{
+		webhookService: webhookService,
+	}
+}
+
+// WebhookDeliveryResponse represents the HTTP response for a webhook delivery
+type WebhookDeliveryResponse struct {
+	ID             string  `json:"id"`
+	PaymentID      string  `json:"payment_id"`
+	Event          string  `json:"event"`
+	URL            string  `json:"url"`
+	Status         string  `json:"status"`
+	Attempts       int     `json:"attempts"`
+	NextAttemptAt  *string `json:"next_attempt_at,omitempty"`
+	LastError      string  `json:"last_error,omitempty"`
+	LastStatusCode int     `json:"last_status_code,omitempty"`
+	DeliveredAt    *string `json:"delivered_at,omitempty"`
+	CreatedAt      string  `json:"created_at"`
+}
+
+// ListWebhookDeliveriesResponse represents the HTTP response for a payment's webhook deliveries
+type ListWebhookDeliveriesResponse struct {
+	Deliveries []WebhookDeliveryResponse `json:"deliveries"`
+}
+
+// ListDeliveries handles listing a payment's webhook deliveries
+func (h *WebhookHandler) ListDeliveries(c echo.Context) error {
+	paymentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, ErrCodeInvalidPaymentID, "Invalid payment ID")
+	}
+
+	// Call service (input port)
+	deliveries, err := h.webhookService.ListDeliveries(paymentID, merchantIDFromContext(c))
+	if err != nil {
+		return respondServiceError(c, err, "Failed to list webhook deliveries")
+	}
+
+	// Convert to HTTP response
+	httpResponse := ListWebhookDeliveriesResponse{
+		Deliveries: make([]WebhookDeliveryResponse, 0, len(deliveries)),
+	}
+	for i := range deliveries {
+		httpResponse.Deliveries = append(httpResponse.Deliveries, toHTTPWebhookDeliveryResponse(c, &deliveries[i]))
+	}
+
+	return respondData(c, http.StatusOK, httpResponse)
+}
+
+// ReplayDelivery handles sending a delivered or failed webhook delivery again
+func (h *WebhookHandler) ReplayDelivery(c echo.Context) error {
+	paymentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, ErrCodeInvalidPaymentID, "Invalid payment ID")
+	}
+	deliveryID, err := uuid.Parse(c.Param("delivery_id"))
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, ErrCodeInvalidDeliveryID, "Invalid delivery ID")
+	}
+
+	// Call service (input port)
+	delivery, err := h.webhookService.ReplayDelivery(paymentID, deliveryID, merchantIDFromContext(c))
+	if err != nil {
+		return respondServiceError(c, err, "Failed to replay webhook delivery")
+	}
+
+	return respondData(c, http.StatusAccepted, toHTTPWebhookDeliveryResponse(c, delivery))
+}
+
+// ReplayPaymentWebhook handles re-enqueueing the webhook for a payment's terminal status
+func (h *WebhookHandler) ReplayPaymentWebhook(c echo.Context) error {
+	paymentID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		return respondError(c, http.StatusBadRequest, ErrCodeInvalidPaymentID, "Invalid payment ID")
+	}
+
+	// Call service (input port)
+	delivery, err := h.webhookService.ReplayPaymentWebhook(paymentID, merchantIDFromContext(c))
+	if err != nil {
+		return respondServiceError(c, err, "Failed to replay webhook")
+	}
+
+	return respondData(c, http.StatusAccepted, toHTTPWebhookDeliveryResponse(c, delivery))
+}
+
+// toHTTPWebhookDeliveryResponse converts a service webhook delivery to the HTTP response
+func toHTTPWebhookDeliveryResponse(c echo.Context, response *input.WebhookDeliveryResponse) WebhookDeliveryResponse {
+	httpResponse := WebhookDeliveryResponse{
+		ID:             response.ID.String(),
+		PaymentID:      response.PaymentID.String(),
+		Event:          string(response.EventType),
+		URL:            response.URL,
+		Status:         string(response.Status),
+		Attempts:       response.Attempts,
+		LastError:      response.LastError,
+		LastStatusCode: response.LastStatusCode,
+		CreatedAt:      formatTimestamp(c, response.CreatedAt),
+	}
+	// The next attempt time is only meaningful while the delivery is pending
+	if response.Status == core.WebhookDeliveryPending {
+		nextAttemptAt := formatTimestamp(c, response.NextAttemptAt)
+		httpResponse.NextAttemptAt = &nextAttemptAt
+	}
+	if response.DeliveredAt != nil {
+		deliveredAt := formatTimestamp(c, *response.DeliveredAt)
+		httpResponse.DeliveredAt = &deliveredAt
+	}
+	return httpResponse
+}
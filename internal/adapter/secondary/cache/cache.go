@@ -0,0 +1,41 @@
+package cache
+
+import (
+	"fmt"
+
+	"github.com/cashflow/payment-gateway/internal/port/output"
+)
+
+const (
+	BackendNone   = "none"
+	BackendMemory = "memory"
+	BackendRedis  = "redis"
+)
+
+// Cache is implemented by every cache backend
+type Cache interface {
+	output.PaymentCache
+	// Close releases the backend's connection
+	Close() error
+}
+
+// Config selects and configures the cache backend
+type Config struct {
+	Backend  string // BackendNone (default), BackendMemory or BackendRedis
+	RedisURL string
+}
+
+// New connects to the configured cache backend
+// It returns nil for BackendNone, meaning payments are not cached
+func New(cfg Config) (Cache, error) {
+	switch cfg.Backend {
+	case "", BackendNone:
+		return nil, nil
+	case BackendMemory:
+		return NewMemoryCache(), nil
+	case BackendRedis:
+		return NewRedisCache(cfg.RedisURL)
+	default:
+		return nil, fmt.Errorf("unknown cache backend %q", cfg.Backend)
+	}
+}
@@ -0,0 +1,219 @@
+package cache
+
+import (
+	"log"
+	"sync/atomic"
+	"time"
+
+	"github.com/cashflow/payment-gateway/internal/core"
+	"github.com/cashflow/payment-gateway/internal/metrics"
+	"github.com/cashflow/payment-gateway/internal/port/output"
+	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// CachedPaymentRepository is a read-through cache in front of a PaymentRepository's GetByID
+// SUCCESS, EXPIRED and CANCELLED payments only change through rare operator actions (overrides),
+// so they are cached for terminalTTL; PENDING, AUTHORIZED, REVIEW and FAILED ones, which a worker,
+// merchant or reprocess can still move on, for pendingTTL. Each TTL bounds how stale a status can
+// be when another process, such as a worker or another API instance, changed it
+// Status changes and deletes made through this repository invalidate the affected entries
+// Cache errors are logged and fall through to the repository, so an outage only costs database reads
+type CachedPaymentRepository struct {
+	output.PaymentRepository
+	cache       output.PaymentCache
+	pendingTTL  time.Duration
+	terminalTTL time.Duration
+
+	hits   atomic.Uint64
+	misses atomic.Uint64
+	errors atomic.Uint64
+
+	requestsDesc *prometheus.Desc
+	errorsDesc   *prometheus.Desc
+}
+
+// NewCachedPaymentRepository wraps repo with the cache
+// A zero pendingTTL or terminalTTL leaves the payments it applies to uncached
+// It is also a prometheus.Collector exporting the cache's hit and miss counts
+func NewCachedPaymentRepository(repo output.PaymentRepository, cache output.PaymentCache, pendingTTL, terminalTTL time.Duration) *CachedPaymentRepository {
+	return &CachedPaymentRepository{
+		PaymentRepository: repo,
+		cache:             cache,
+		pendingTTL:        pendingTTL,
+		terminalTTL:       terminalTTL,
+		requestsDesc: prometheus.NewDesc(metrics.Namespace+"_cache_requests_total",
+			"Payment lookups by ID, by whether they were served from the cache", []string{"result"}, nil),
+		errorsDesc: prometheus.NewDesc(metrics.Namespace+"_cache_errors_total",
+			"Cache operations that failed and fell back to the database", nil, nil),
+	}
+}
+
+// GetByID returns the cached payment, loading and caching it on a miss
+func (r *CachedPaymentRepository) GetByID(id uuid.UUID) (*core.Payment, error) {
+	payment, ok, err := r.cache.Get(id)
+	if err != nil {
+		r.logError("read", id, err)
+	}
+	if ok {
+		r.hits.Add(1)
+		return payment, nil
+	}
+	r.misses.Add(1)
+
+	payment, err = r.PaymentRepository.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	ttl := r.ttlFor(payment)
+	if ttl <= 0 {
+		return payment, nil
+	}
+	if err := r.cache.Set(payment, ttl); err != nil {
+		r.logError("write", id, err)
+	}
+	return payment, nil
+}
+
+// ttlFor returns how long the payment may be cached, or 0 when it must not be
+// FAILED is terminal for processing but can still be reprocessed, so it is cached like PENDING
+func (r *CachedPaymentRepository) ttlFor(payment *core.Payment) time.Duration {
+	if payment.IsTerminal() && payment.Status != core.PaymentStatusFailed {
+		return r.terminalTTL
+	}
+	return r.pendingTTL
+}
+
+// ProcessPayment processes the payment and invalidates its cached PENDING copy
+func (r *CachedPaymentRepository) ProcessPayment(id uuid.UUID, newStatus core.PaymentStatus, note string) (core.PaymentStatus, error) {
+	status, err := r.PaymentRepository.ProcessPayment(id, newStatus, note)
+	r.invalidate(id)
+	return status, err
+}
+
+// ExpireDue expires due payments and invalidates their cached PENDING copies
+func (r *CachedPaymentRepository) ExpireDue(limit int) ([]*core.Payment, error) {
+	expired, err := r.PaymentRepository.ExpireDue(limit)
+	if len(expired) > 0 {
+		ids := make([]uuid.UUID, 0, len(expired))
+		for _, payment := range expired {
+			ids = append(ids, payment.ID)
+		}
+		r.invalidate(ids...)
+	}
+	return expired, err
+}
+
+// StartAttempt counts the attempt and invalidates the payment's cached copy, which may have just failed
+func (r *CachedPaymentRepository) StartAttempt(id uuid.UUID, maxAttempts int) (*core.Payment, error) {
+	payment, err := r.PaymentRepository.StartAttempt(id, maxAttempts)
+	r.invalidate(id)
+	return payment, err
+}
+
+// ProcessPayments processes the payments and invalidates their cached PENDING copies
+func (r *CachedPaymentRepository) ProcessPayments(updates []output.StatusUpdate) (map[uuid.UUID]core.PaymentStatus, error) {
+	applied, err := r.PaymentRepository.ProcessPayments(updates)
+	ids := make([]uuid.UUID, 0, len(updates))
+	for _, update := range updates {
+		ids = append(ids, update.PaymentID)
+	}
+	r.invalidate(ids...)
+	return applied, err
+}
+
+// StartAttempts counts the attempts and invalidates the payments' cached copies
+func (r *CachedPaymentRepository) StartAttempts(ids []uuid.UUID, maxAttempts int) ([]*core.Payment, error) {
+	started, err := r.PaymentRepository.StartAttempts(ids, maxAttempts)
+	r.invalidate(ids...)
+	return started, err
+}
+
+// DeleteUnpublished deletes the payment and invalidates any copy cached since it was created
+func (r *CachedPaymentRepository) DeleteUnpublished(id uuid.UUID) error {
+	err := r.PaymentRepository.DeleteUnpublished(id)
+	r.invalidate(id)
+	return err
+}
+
+// ResetForReprocess resets the payments and invalidates their cached FAILED copies
+func (r *CachedPaymentRepository) ResetForReprocess(ids []uuid.UUID, note string) ([]*core.Payment, error) {
+	reset, err := r.PaymentRepository.ResetForReprocess(ids, note)
+	if len(reset) > 0 {
+		resetIDs := make([]uuid.UUID, 0, len(reset))
+		for _, payment := range reset {
+			resetIDs = append(resetIDs, payment.ID)
+		}
+		r.invalidate(resetIDs...)
+	}
+	return reset, err
+}
+
+// Capture captures the payment and invalidates its cached AUTHORIZED copy
+func (r *CachedPaymentRepository) Capture(id uuid.UUID, amount float64) (*core.Payment, error) {
+	payment, err := r.PaymentRepository.Capture(id, amount)
+	r.invalidate(id)
+	return payment, err
+}
+
+// Void voids the payment and invalidates its cached AUTHORIZED copy
+func (r *CachedPaymentRepository) Void(id uuid.UUID) (*core.Payment, error) {
+	payment, err := r.PaymentRepository.Void(id)
+	r.invalidate(id)
+	return payment, err
+}
+
+// ResolveReview resolves the review and invalidates the payment's cached REVIEW copy
+func (r *CachedPaymentRepository) ResolveReview(id uuid.UUID, decision core.PaymentStatus, note string) (*core.Payment, error) {
+	payment, err := r.PaymentRepository.ResolveReview(id, decision, note)
+	r.invalidate(id)
+	return payment, err
+}
+
+// OverrideStatus forces the status and invalidates the payment's cached copy
+func (r *CachedPaymentRepository) OverrideStatus(id uuid.UUID, status core.PaymentStatus, actor, note string) (*core.Payment, error) {
+	payment, err := r.PaymentRepository.OverrideStatus(id, status, actor, note)
+	r.invalidate(id)
+	return payment, err
+}
+
+// SoftDelete deletes the matching payments and clears the whole cache,
+// since the deleted IDs aren't known
+func (r *CachedPaymentRepository) SoftDelete(filter output.PaymentFilter) (int64, error) {
+	deleted, err := r.PaymentRepository.SoftDelete(filter)
+	if deleted > 0 {
+		if err := r.cache.Clear(); err != nil {
+			r.errors.Add(1)
+			log.Printf("Failed to clear payment cache after deleting %d payments: %v", deleted, err)
+		}
+	}
+	return deleted, err
+}
+
+// Describe implements prometheus.Collector
+func (r *CachedPaymentRepository) Describe(ch chan<- *prometheus.Desc) {
+	ch <- r.requestsDesc
+	ch <- r.errorsDesc
+}
+
+// Collect implements prometheus.Collector
+func (r *CachedPaymentRepository) Collect(ch chan<- prometheus.Metric) {
+	ch <- prometheus.MustNewConstMetric(r.requestsDesc, prometheus.CounterValue, float64(r.hits.Load()), "hit")
+	ch <- prometheus.MustNewConstMetric(r.requestsDesc, prometheus.CounterValue, float64(r.misses.Load()), "miss")
+	ch <- prometheus.MustNewConstMetric(r.errorsDesc, prometheus.CounterValue, float64(r.errors.Load()))
+}
+
+// invalidate removes the payments from the cache, logging failures
+func (r *CachedPaymentRepository) invalidate(ids ...uuid.UUID) {
+	if err := r.cache.Delete(ids...); err != nil {
+		r.errors.Add(1)
+		log.Printf("Failed to invalidate %d cached payments: %v", len(ids), err)
+	}
+}
+
+// logError counts and logs a failed cache read or write of a payment
+func (r *CachedPaymentRepository) logError(op string, id uuid.UUID, err error) {
+	r.errors.Add(1)
+	log.Printf("Payment cache %s failed for %s: %v", op, id, err)
+}
@@ -0,0 +1,71 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cashflow/payment-gateway/internal/core"
+	"github.com/cashflow/payment-gateway/internal/port/output"
+	"github.com/google/uuid"
+)
+
+// stubPaymentRepository serves one payment by ID
+// Methods a test doesn't stub panic through the nil embedded interface
+type stubPaymentRepository struct {
+	output.PaymentRepository
+	payment *core.Payment
+}
+
+func (r *stubPaymentRepository) GetByID(id uuid.UUID) (*core.Payment, error) {
+	return r.payment, nil
+}
+
+// recordingCache misses every lookup and records the TTL of each payment it is asked to cache
+type recordingCache struct {
+	output.PaymentCache
+	ttls map[uuid.UUID]time.Duration
+}
+
+func (c *recordingCache) Get(id uuid.UUID) (*core.Payment, bool, error) {
+	return nil, false, nil
+}
+
+func (c *recordingCache) Set(payment *core.Payment, ttl time.Duration) error {
+	c.ttls[payment.ID] = ttl
+	return nil
+}
+
+func TestCachedPaymentRepositoryTTLByStatus(t *testing.T) {
+	const (
+		pendingTTL  = 2 * time.Second
+		terminalTTL = 10 * time.Minute
+	)
+	tests := []struct {
+		status core.PaymentStatus
+		want   time.Duration
+	}{
+		{status: core.PaymentStatusPending, want: pendingTTL},
+		{status: core.PaymentStatusAuthorized, want: pendingTTL},
+		{status: core.PaymentStatusReview, want: pendingTTL},
+		// FAILED can still be reprocessed, so it is not cached like a settled payment
+		{status: core.PaymentStatusFailed, want: pendingTTL},
+		{status: core.PaymentStatusSuccess, want: terminalTTL},
+		{status: core.PaymentStatusExpired, want: terminalTTL},
+		{status: core.PaymentStatusCancelled, want: terminalTTL},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.status), func(t *testing.T) {
+			payment := &core.Payment{ID: uuid.New(), Status: tt.status}
+			cache := &recordingCache{ttls: make(map[uuid.UUID]time.Duration)}
+			repo := NewCachedPaymentRepository(&stubPaymentRepository{payment: payment}, cache, pendingTTL, terminalTTL)
+
+			if _, err := repo.GetByID(payment.ID); err != nil {
+				t.Fatalf("GetByID: %v", err)
+			}
+
+			if ttl, ok := cache.ttls[payment.ID]; !ok || ttl != tt.want {
+				t.Errorf("cached with ttl %v (cached %t), want %v", ttl, ok, tt.want)
+			}
+		})
+	}
+}
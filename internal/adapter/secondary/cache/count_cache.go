@@ -0,0 +1,89 @@
+package cache
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/cashflow/payment-gateway/internal/port/output"
+)
+
+// CountMaxEntries bounds the count cache; past it, expired entries are dropped and then the whole cache
+const CountMaxEntries = 1000
+
+// countEntry is a cached count with its expiry
+type countEntry struct {
+	count     int64
+	expiresAt time.Time
+}
+
+// CountCachingPaymentRepository caches Count results in process memory for ttl, keyed by the filter
+// COUNT(*) scans every matching row, so a client paging through a large result set would otherwise
+// pay for the same scan on every page; a total up to ttl stale is fine for pagination
+// Only wrap the repository of callers that tolerate a stale count: the admin purge dry run must not
+type CountCachingPaymentRepository struct {
+	output.PaymentRepository
+	ttl time.Duration
+
+	mu      sync.Mutex
+	entries map[string]countEntry
+}
+
+// NewCountCachingPaymentRepository wraps repo with a count cache
+func NewCountCachingPaymentRepository(repo output.PaymentRepository, ttl time.Duration) *CountCachingPaymentRepository {
+	return &CountCachingPaymentRepository{
+		PaymentRepository: repo,
+		ttl:               ttl,
+		entries:           make(map[string]countEntry),
+	}
+}
+
+// Count returns the cached count for the filter, counting and caching it on a miss
+func (r *CountCachingPaymentRepository) Count(filter output.PaymentFilter) (int64, error) {
+	key := countKey(filter)
+	now := time.Now()
+
+	r.mu.Lock()
+	entry, ok := r.entries[key]
+	r.mu.Unlock()
+	if ok && now.Before(entry.expiresAt) {
+		return entry.count, nil
+	}
+
+	count, err := r.PaymentRepository.Count(filter)
+	if err != nil {
+		return 0, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.entries[key]; !ok && len(r.entries) >= CountMaxEntries {
+		r.evict(now)
+	}
+	r.entries[key] = countEntry{count: count, expiresAt: now.Add(r.ttl)}
+	return count, nil
+}
+
+// evict drops expired entries, or every entry if none have expired
+// The caller must hold r.mu
+func (r *CountCachingPaymentRepository) evict(now time.Time) {
+	for key, entry := range r.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(r.entries, key)
+		}
+	}
+	if len(r.entries) >= CountMaxEntries {
+		r.entries = make(map[string]countEntry)
+	}
+}
+
+// countKey identifies the filter's matching rows; Limit and Offset don't change the count
+func countKey(filter output.PaymentFilter) string {
+	isTest := "any"
+	if filter.IsTest != nil {
+		isTest = fmt.Sprint(*filter.IsTest)
+	}
+	return fmt.Sprintf("%v|%q|%s|%q|%q|%q|%q|%q|%s|%s",
+		filter.IDs, filter.MerchantID, isTest, filter.Status, filter.Method,
+		filter.CustomerID, filter.Tag, filter.RefPrefix, filter.CreatedAfter.Format(time.RFC3339Nano), filter.CreatedBefore.Format(time.RFC3339Nano))
+}
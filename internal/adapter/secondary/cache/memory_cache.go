@@ -0,0 +1,107 @@
+package cache
+
+import (
+	"sync"
+	"time"
+
+	"github.com/cashflow/payment-gateway/internal/core"
+	"github.com/google/uuid"
+)
+
+// MemoryMaxEntries bounds the in-memory cache; past it, entries are evicted arbitrarily
+const MemoryMaxEntries = 10000
+
+// memoryEntry is a cached payment with its expiry (zero means never)
+type memoryEntry struct {
+	payment   core.Payment
+	expiresAt time.Time
+}
+
+// MemoryCache is a secondary adapter that implements the PaymentCache output port in process memory
+// Each process has its own copy, so it suits a single API instance or local development
+type MemoryCache struct {
+	mu      sync.Mutex
+	entries map[uuid.UUID]memoryEntry
+}
+
+// NewMemoryCache creates a new in-memory payment cache
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{entries: make(map[uuid.UUID]memoryEntry)}
+}
+
+// Get returns a copy of the cached payment, or false when it is missing or expired
+func (c *MemoryCache) Get(id uuid.UUID) (*core.Payment, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[id]
+	if !ok {
+		return nil, false, nil
+	}
+	if !entry.expiresAt.IsZero() && !time.Now().Before(entry.expiresAt) {
+		delete(c.entries, id)
+		return nil, false, nil
+	}
+	payment := entry.payment
+	return &payment, true, nil
+}
+
+// Set caches a copy of the payment
+func (c *MemoryCache) Set(payment *core.Payment, ttl time.Duration) error {
+	entry := memoryEntry{payment: *payment}
+	if ttl > 0 {
+		entry.expiresAt = time.Now().Add(ttl)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.entries[payment.ID]; !ok && len(c.entries) >= MemoryMaxEntries {
+		c.evict()
+	}
+	c.entries[payment.ID] = entry
+	return nil
+}
+
+// Delete removes the payments from the cache
+func (c *MemoryCache) Delete(ids ...uuid.UUID) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, id := range ids {
+		delete(c.entries, id)
+	}
+	return nil
+}
+
+// Clear removes every cached payment
+func (c *MemoryCache) Clear() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries = make(map[uuid.UUID]memoryEntry)
+	return nil
+}
+
+// Close implements Cache; there is no connection to release
+func (c *MemoryCache) Close() error {
+	return nil
+}
+
+// evict drops expired entries, or one arbitrary entry if none have expired
+// The caller must hold c.mu
+func (c *MemoryCache) evict() {
+	now := time.Now()
+	for id, entry := range c.entries {
+		if !entry.expiresAt.IsZero() && !now.Before(entry.expiresAt) {
+			delete(c.entries, id)
+		}
+	}
+	if len(c.entries) < MemoryMaxEntries {
+		return
+	}
+	for id := range c.entries {
+		delete(c.entries, id)
+		return
+	}
+}
@@ -0,0 +1,138 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cashflow/payment-gateway/internal/core"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// KeyPrefix namespaces the cache's keys in a shared Redis database
+	KeyPrefix = "payment:cache:"
+
+	// RedisTimeout bounds each Redis call, so a slow Redis degrades to database reads
+	RedisTimeout = 500 * time.Millisecond
+
+	// clearBatchSize is the number of keys scanned and deleted per round-trip by Clear
+	clearBatchSize = 500
+)
+
+// RedisCache is a secondary adapter that implements the PaymentCache output port on Redis
+// Payments are stored as JSON; the API and workers share the cache, so status changes
+// made by a worker invalidate the API's entries
+type RedisCache struct {
+	client *redis.Client
+}
+
+// NewRedisCache connects to the Redis server at redisURL (redis://[:password@]host:port/db)
+func NewRedisCache(redisURL string) (*RedisCache, error) {
+	opts, err := redis.ParseURL(redisURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse Redis URL: %w", err)
+	}
+	client := redis.NewClient(opts)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to connect to Redis: %w", err)
+	}
+	return &RedisCache{client: client}, nil
+}
+
+// Get returns the cached payment, or false when it is not cached
+func (c *RedisCache) Get(id uuid.UUID) (*core.Payment, bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), RedisTimeout)
+	defer cancel()
+
+	data, err := c.client.Get(ctx, cacheKey(id)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read cached payment: %w", err)
+	}
+
+	var payment core.Payment
+	if err := json.Unmarshal(data, &payment); err != nil {
+		return nil, false, fmt.Errorf("failed to decode cached payment: %w", err)
+	}
+	return &payment, true, nil
+}
+
+// Set caches the payment; a zero ttl keeps it until it is deleted
+func (c *RedisCache) Set(payment *core.Payment, ttl time.Duration) error {
+	data, err := json.Marshal(payment)
+	if err != nil {
+		return fmt.Errorf("failed to encode payment for cache: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), RedisTimeout)
+	defer cancel()
+	if err := c.client.Set(ctx, cacheKey(payment.ID), data, ttl).Err(); err != nil {
+		return fmt.Errorf("failed to cache payment: %w", err)
+	}
+	return nil
+}
+
+// Delete removes the payments from the cache
+func (c *RedisCache) Delete(ids ...uuid.UUID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	keys := make([]string, 0, len(ids))
+	for _, id := range ids {
+		keys = append(keys, cacheKey(id))
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), RedisTimeout)
+	defer cancel()
+	if err := c.client.Del(ctx, keys...).Err(); err != nil {
+		return fmt.Errorf("failed to invalidate cached payments: %w", err)
+	}
+	return nil
+}
+
+// Clear removes every cached payment, scanning the cache's keys in batches
+// It only runs after admin purges, so it is not bound by RedisTimeout
+func (c *RedisCache) Clear() error {
+	ctx := context.Background()
+	iter := c.client.Scan(ctx, 0, KeyPrefix+"*", clearBatchSize).Iterator()
+
+	keys := make([]string, 0, clearBatchSize)
+	for iter.Next(ctx) {
+		keys = append(keys, iter.Val())
+		if len(keys) == clearBatchSize {
+			if err := c.client.Unlink(ctx, keys...).Err(); err != nil {
+				return fmt.Errorf("failed to clear payment cache: %w", err)
+			}
+			keys = keys[:0]
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return fmt.Errorf("failed to clear payment cache: %w", err)
+	}
+	if len(keys) > 0 {
+		if err := c.client.Unlink(ctx, keys...).Err(); err != nil {
+			return fmt.Errorf("failed to clear payment cache: %w", err)
+		}
+	}
+	return nil
+}
+
+// Close closes the Redis connection
+func (c *RedisCache) Close() error {
+	return c.client.Close()
+}
+
+// cacheKey returns the Redis key caching a payment
+func cacheKey(id uuid.UUID) string {
+	return KeyPrefix + id.String()
+}
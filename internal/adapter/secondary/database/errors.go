@@ -0,0 +1,16 @@
+package database
+
+import (
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// uniqueViolationCode is the PostgreSQL SQLSTATE for unique_violation
+const uniqueViolationCode = "23505"
+
+// isUniqueViolation checks if err was caused by a unique index rejecting a write
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode
+}
@@ -0,0 +1,118 @@
+package database
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cashflow/payment-gateway/internal/constant/model/db"
+	"github.com/cashflow/payment-gateway/internal/core"
+	"github.com/cashflow/payment-gateway/internal/port/output"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// GormExportRepository is a secondary adapter that implements ExportRepository output port
+type GormExportRepository struct {
+	gormDB *gorm.DB
+}
+
+// NewGormExportRepository creates a new GORM export repository
+func NewGormExportRepository(gormDB *gorm.DB) output.ExportRepository {
+	return &GormExportRepository{gormDB: gormDB}
+}
+
+// exportToCore converts db.Export to core.Export
+func exportToCore(e *db.Export) *core.Export {
+	return &core.Export{
+		ID:          e.ID,
+		Day:         e.Day,
+		Status:      core.ExportStatus(e.Status),
+		ObjectKey:   e.ObjectKey,
+		RowCount:    e.RowCount,
+		Error:       e.Error,
+		CreatedAt:   e.CreatedAt,
+		CompletedAt: e.CompletedAt,
+	}
+}
+
+// exportFromCore converts core.Export to db.Export
+func exportFromCore(e *core.Export) *db.Export {
+	return &db.Export{
+		ID:          e.ID,
+		Day:         e.Day,
+		Status:      db.ExportStatus(e.Status),
+		ObjectKey:   e.ObjectKey,
+		RowCount:    e.RowCount,
+		Error:       e.Error,
+		CreatedAt:   e.CreatedAt,
+		CompletedAt: e.CompletedAt,
+	}
+}
+
+// CreateExport saves a new export
+func (r *GormExportRepository) CreateExport(export *core.Export) error {
+	dbExport := exportFromCore(export)
+	if err := r.gormDB.Create(dbExport).Error; err != nil {
+		return fmt.Errorf("failed to create export: %w", err)
+	}
+	export.ID = dbExport.ID
+	export.CreatedAt = dbExport.CreatedAt
+	return nil
+}
+
+// GetExport retrieves an export by ID
+// It reads from the primary, since exports are polled right after they are started
+func (r *GormExportRepository) GetExport(id uuid.UUID) (*core.Export, error) {
+	var dbExport db.Export
+	if err := primary(r.gormDB).Where("id = ?", id).First(&dbExport).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, core.ErrExportNotFound
+		}
+		return nil, fmt.Errorf("failed to get export: %w", err)
+	}
+	return exportToCore(&dbExport), nil
+}
+
+// UpdateExport saves an export's status, row count, error and completion time
+func (r *GormExportRepository) UpdateExport(export *core.Export) error {
+	err := r.gormDB.Model(&db.Export{}).
+		Where("id = ?", export.ID).
+		Updates(map[string]interface{}{
+			"status":       db.ExportStatus(export.Status),
+			"row_count":    export.RowCount,
+			"error":        export.Error,
+			"completed_at": export.CompletedAt,
+		}).Error
+	if err != nil {
+		return fmt.Errorf("failed to update export: %w", err)
+	}
+	return nil
+}
+
+// StreamPayments calls fn for every payment created in [from, to), oldest first
+// Rows are scanned one at a time from an open cursor, which holds a connection (the replica's,
+// when one is configured) for as long as the export runs; the range is served by idx_payments_created_at
+func (r *GormExportRepository) StreamPayments(from, to time.Time, fn func(*core.Payment) error) error {
+	rows, err := r.gormDB.Model(&db.Payment{}).
+		Where("created_at >= ? AND created_at < ?", from, to).
+		Order("created_at ASC, id ASC").
+		Rows()
+	if err != nil {
+		return fmt.Errorf("failed to query payments: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var dbPayment db.Payment
+		if err := r.gormDB.ScanRows(rows, &dbPayment); err != nil {
+			return fmt.Errorf("failed to scan payment: %w", err)
+		}
+		if err := fn(toCore(&dbPayment)); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read payments: %w", err)
+	}
+	return nil
+}
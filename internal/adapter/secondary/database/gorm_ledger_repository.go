@@ -0,0 +1,75 @@
+package database
+
+import (
+	"fmt"
+
+	"github.com/cashflow/payment-gateway/internal/constant/model/db"
+	"github.com/cashflow/payment-gateway/internal/core"
+	"github.com/cashflow/payment-gateway/internal/port/output"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// GormLedgerRepository is a secondary adapter that implements LedgerRepository output port
+type GormLedgerRepository struct {
+	gormDB *gorm.DB
+}
+
+// NewGormLedgerRepository creates a new GORM ledger repository
+func NewGormLedgerRepository(gormDB *gorm.DB) output.LedgerRepository {
+	return &GormLedgerRepository{gormDB: gormDB}
+}
+
+// ledgerEntryToCore converts db.LedgerEntry to core.LedgerEntry
+func ledgerEntryToCore(e *db.LedgerEntry) core.LedgerEntry {
+	return core.LedgerEntry{
+		ID:        e.ID,
+		PaymentID: e.PaymentID,
+		Account:   e.Account,
+		Direction: core.LedgerDirection(e.Direction),
+		Amount:    e.Amount,
+		Currency:  core.Currency(e.Currency),
+		CreatedAt: e.CreatedAt,
+	}
+}
+
+// ledgerEntryFromCore converts core.LedgerEntry to db.LedgerEntry
+func ledgerEntryFromCore(e *core.LedgerEntry) *db.LedgerEntry {
+	return &db.LedgerEntry{
+		ID:        e.ID,
+		PaymentID: e.PaymentID,
+		Account:   e.Account,
+		Direction: db.LedgerDirection(e.Direction),
+		Amount:    e.Amount,
+		Currency:  db.Currency(e.Currency),
+		CreatedAt: e.CreatedAt,
+	}
+}
+
+// createLedgerEntries inserts ledger entries using the given transaction
+func createLedgerEntries(tx *gorm.DB, entries []core.LedgerEntry) error {
+	dbEntries := make([]*db.LedgerEntry, 0, len(entries))
+	for i := range entries {
+		dbEntries = append(dbEntries, ledgerEntryFromCore(&entries[i]))
+	}
+	if err := tx.Create(&dbEntries).Error; err != nil {
+		return fmt.Errorf("failed to create ledger entries: %w", err)
+	}
+	return nil
+}
+
+// ListByPaymentID retrieves the ledger entries for a payment in recording order
+func (r *GormLedgerRepository) ListByPaymentID(paymentID uuid.UUID) ([]core.LedgerEntry, error) {
+	var dbEntries []db.LedgerEntry
+	if err := r.gormDB.Where("payment_id = ?", paymentID).
+		Order("created_at ASC").
+		Find(&dbEntries).Error; err != nil {
+		return nil, fmt.Errorf("failed to list ledger entries: %w", err)
+	}
+
+	entries := make([]core.LedgerEntry, 0, len(dbEntries))
+	for i := range dbEntries {
+		entries = append(entries, ledgerEntryToCore(&dbEntries[i]))
+	}
+	return entries, nil
+}
@@ -0,0 +1,159 @@
+package database
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/cashflow/payment-gateway/internal/constant/model/db"
+	"github.com/cashflow/payment-gateway/internal/core"
+	"github.com/cashflow/payment-gateway/internal/port/output"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GormRefundRepository is a secondary adapter that implements RefundRepository output port
+type GormRefundRepository struct {
+	gormDB *gorm.DB
+}
+
+// NewGormRefundRepository creates a new GORM refund repository
+func NewGormRefundRepository(gormDB *gorm.DB) output.RefundRepository {
+	return &GormRefundRepository{gormDB: gormDB}
+}
+
+// refundToCore converts db.Refund to core.Refund
+func refundToCore(r *db.Refund) *core.Refund {
+	return &core.Refund{
+		ID:        r.ID,
+		PaymentID: r.PaymentID,
+		RefundID:  r.RefundID,
+		Amount:    r.Amount,
+		Currency:  core.Currency(r.Currency),
+		Status:    core.RefundStatus(r.Status),
+		CreatedAt: r.CreatedAt,
+	}
+}
+
+// CreateRefund atomically refunds a payment
+// The payment row is locked with SELECT FOR UPDATE so concurrent refunds (including retries
+// of the same refund ID) are serialized and the refundable balance can't be overdrawn
+func (r *GormRefundRepository) CreateRefund(paymentID uuid.UUID, refundID string, amount float64) (*core.Refund, bool, error) {
+	var result *core.Refund
+	replayed := false
+
+	err := primary(r.gormDB).Transaction(func(tx *gorm.DB) error {
+		var dbPayment db.Payment
+
+		// Lock the payment row
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", paymentID).
+			First(&dbPayment).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return core.ErrPaymentNotFound
+			}
+			return fmt.Errorf("failed to lock payment: %w", err)
+		}
+
+		payment := toCore(&dbPayment)
+
+		// Return the existing refund if this refund ID was already processed
+		var existing db.Refund
+		err := tx.Where("payment_id = ? AND refund_id = ?", paymentID, refundID).First(&existing).Error
+		if err == nil {
+			if amount != 0 && toCents(existing.Amount, payment.Currency) != toCents(amount, payment.Currency) {
+				return core.ErrRefundIDConflict
+			}
+			result = refundToCore(&existing)
+			replayed = true
+			return nil
+		}
+		if err != gorm.ErrRecordNotFound {
+			return fmt.Errorf("failed to check refund: %w", err)
+		}
+
+		if !payment.IsRefundable() {
+			return fmt.Errorf("%w: current status is %s", core.ErrPaymentNotRefundable, payment.Status)
+		}
+
+		// The refundable balance is derived from the refunds table, never a mutable column
+		refunded, err := refundedTotal(tx, paymentID)
+		if err != nil {
+			return err
+		}
+		balanceCents := toCents(payment.Amount, payment.Currency) - toCents(refunded, payment.Currency)
+		if amount == 0 {
+			amount = fromCents(balanceCents, payment.Currency)
+		}
+		if balanceCents <= 0 || toCents(amount, payment.Currency) > balanceCents {
+			return core.ErrRefundExceedsBalance
+		}
+
+		dbRefund := &db.Refund{
+			PaymentID: paymentID,
+			RefundID:  refundID,
+			Amount:    amount,
+			Currency:  dbPayment.Currency,
+			Status:    db.RefundStatusSuccess,
+		}
+		if err := tx.Create(dbRefund).Error; err != nil {
+			return fmt.Errorf("failed to create refund: %w", err)
+		}
+
+		// Reverse the refunded part of the settlement in the ledger
+		if err := createLedgerEntries(tx, core.ReversalEntries(payment, amount)); err != nil {
+			return err
+		}
+
+		result = refundToCore(dbRefund)
+		return nil
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return result, replayed, nil
+}
+
+// RefundedTotal returns the sum of successful refunds for a payment
+func (r *GormRefundRepository) RefundedTotal(paymentID uuid.UUID) (float64, error) {
+	return refundedTotal(r.gormDB, paymentID)
+}
+
+// ListByPaymentID retrieves a payment's refunds in creation order
+func (r *GormRefundRepository) ListByPaymentID(paymentID uuid.UUID) ([]*core.Refund, error) {
+	var dbRefunds []db.Refund
+	if err := r.gormDB.Where("payment_id = ?", paymentID).
+		Order("created_at ASC").
+		Find(&dbRefunds).Error; err != nil {
+		return nil, fmt.Errorf("failed to list refunds: %w", err)
+	}
+
+	refunds := make([]*core.Refund, 0, len(dbRefunds))
+	for i := range dbRefunds {
+		refunds = append(refunds, refundToCore(&dbRefunds[i]))
+	}
+	return refunds, nil
+}
+
+// refundedTotal sums successful refunds for a payment using the given connection or transaction
+func refundedTotal(tx *gorm.DB, paymentID uuid.UUID) (float64, error) {
+	var total float64
+	if err := tx.Model(&db.Refund{}).
+		Select("COALESCE(SUM(amount), 0)").
+		Where("payment_id = ? AND status = ?", paymentID, db.RefundStatusSuccess).
+		Scan(&total).Error; err != nil {
+		return 0, fmt.Errorf("failed to sum refunds: %w", err)
+	}
+	return total, nil
+}
+
+// toCents converts an amount to integer minor units of its currency (cents for two decimal
+// places) for exact comparison
+func toCents(amount float64, currency core.Currency) int64 {
+	return int64(math.Round(amount * math.Pow10(currency.Decimals())))
+}
+
+// fromCents converts integer minor units of a currency back to an amount
+func fromCents(cents int64, currency core.Currency) float64 {
+	return float64(cents) / math.Pow10(currency.Decimals())
+}
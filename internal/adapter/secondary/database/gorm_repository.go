@@ -2,6 +2,7 @@ package database
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -14,45 +15,167 @@ import (
 
 // GormPaymentRepository is a secondary adapter that implements PaymentRepository output port
 type GormPaymentRepository struct {
-	gormDB *gorm.DB
+	gormDB   *gorm.DB
+	webhooks output.WebhookFactory
 }
 
 // NewGormPaymentRepository creates a new GORM payment repository
-func NewGormPaymentRepository(gormDB *gorm.DB) output.PaymentRepository {
-	return &GormPaymentRepository{gormDB: gormDB}
+// webhooks builds the merchant webhook stored with each status change merchants are notified of;
+// nil stores none
+func NewGormPaymentRepository(gormDB *gorm.DB, webhooks output.WebhookFactory) output.PaymentRepository {
+	return &GormPaymentRepository{gormDB: gormDB, webhooks: webhooks}
 }
 
 // toCore converts db.Payment to core.Payment
 func toCore(p *db.Payment) *core.Payment {
-	return &core.Payment{
-		ID:        p.ID,
-		Amount:    p.Amount,
-		Currency:  core.Currency(p.Currency),
-		Reference: p.Reference,
-		Status:    core.PaymentStatus(p.Status),
-		CreatedAt: p.CreatedAt,
-		UpdatedAt: p.UpdatedAt,
+	payment := &core.Payment{
+		ID:               p.ID,
+		MerchantID:       p.MerchantID,
+		Amount:           p.Amount,
+		Currency:         core.Currency(p.Currency),
+		Reference:        p.Reference,
+		Description:      p.Description,
+		CustomerID:       p.CustomerID,
+		CustomerEmail:    p.CustomerEmail,
+		Status:           core.PaymentStatus(p.Status),
+		Source:           core.PaymentSource(p.Source),
+		Method:           core.PaymentMethod(p.Method),
+		IsTest:           p.IsTest,
+		AuthOnly:         p.AuthOnly,
+		AuthorizedAmount: p.AuthorizedAmount,
+		Tags:             p.Tags,
+		ExpiresAt:        p.ExpiresAt,
+		Attempts:         p.Attempts,
+		ParentPaymentID:  p.ParentPaymentID,
+		CreatedAt:        p.CreatedAt,
+		UpdatedAt:        p.UpdatedAt,
 	}
+	for i := range p.Events {
+		payment.Events = append(payment.Events, eventToCore(&p.Events[i]))
+	}
+	for i := range p.LedgerEntries {
+		payment.LedgerEntries = append(payment.LedgerEntries, ledgerEntryToCore(&p.LedgerEntries[i]))
+	}
+	return payment
+}
+
+// eventToCore converts db.PaymentEvent to core.PaymentEvent
+func eventToCore(e *db.PaymentEvent) core.PaymentEvent {
+	return core.PaymentEvent{
+		ID:         e.ID,
+		PaymentID:  e.PaymentID,
+		FromStatus: core.PaymentStatus(e.FromStatus),
+		ToStatus:   core.PaymentStatus(e.ToStatus),
+		Note:       e.Note,
+		Actor:      e.Actor,
+		CreatedAt:  e.CreatedAt,
+	}
+}
+
+// databaseNow reads the database's clock using the given transaction
+// Expiry decisions use it instead of the local clock, so every worker agrees on
+// when a payment expired however far its own clock has drifted
+func databaseNow(tx *gorm.DB) (time.Time, error) {
+	var now time.Time
+	if err := tx.Raw("SELECT now()").Row().Scan(&now); err != nil {
+		return time.Time{}, fmt.Errorf("failed to read database time: %w", err)
+	}
+	return now, nil
+}
+
+// createPaymentEvent records a status transition and the note explaining it using the given transaction
+// Every status change goes through here, so a transition the state machine does not allow
+// fails the transaction instead of being recorded; from is empty for the creation event
+func createPaymentEvent(tx *gorm.DB, paymentID uuid.UUID, from, to core.PaymentStatus, note string) error {
+	if from != "" && !from.CanTransitionTo(to) {
+		return fmt.Errorf("%w: %s to %s", core.ErrInvalidTransition, from, to)
+	}
+	event := &db.PaymentEvent{
+		PaymentID:  paymentID,
+		FromStatus: db.PaymentStatus(from),
+		ToStatus:   db.PaymentStatus(to),
+		Note:       note,
+	}
+	if err := tx.Create(event).Error; err != nil {
+		return fmt.Errorf("failed to record payment event: %w", err)
+	}
+	return nil
 }
 
 // fromCore converts core.Payment to db.Payment
 func fromCore(p *core.Payment) *db.Payment {
 	return &db.Payment{
-		ID:        p.ID,
-		Amount:    p.Amount,
-		Currency:  db.Currency(p.Currency),
-		Reference: p.Reference,
-		Status:    db.PaymentStatus(p.Status),
-		CreatedAt: p.CreatedAt,
-		UpdatedAt: p.UpdatedAt,
+		ID:               p.ID,
+		MerchantID:       p.MerchantID,
+		Amount:           p.Amount,
+		Currency:         db.Currency(p.Currency),
+		Reference:        p.Reference,
+		Description:      p.Description,
+		CustomerID:       p.CustomerID,
+		CustomerEmail:    p.CustomerEmail,
+		Status:           db.PaymentStatus(p.Status),
+		Source:           db.PaymentSource(p.Source),
+		Method:           db.PaymentMethod(p.Method),
+		IsTest:           p.IsTest,
+		AuthOnly:         p.AuthOnly,
+		AuthorizedAmount: p.AuthorizedAmount,
+		Tags:             p.Tags,
+		ExpiresAt:        p.ExpiresAt,
+		Attempts:         p.Attempts,
+		ParentPaymentID:  p.ParentPaymentID,
+		CreatedAt:        p.CreatedAt,
+		UpdatedAt:        p.UpdatedAt,
 	}
 }
 
-// Create creates a new payment
+// Create creates a new payment and records its initial status event
 func (r *GormPaymentRepository) Create(payment *core.Payment) error {
+	return r.create(payment, 0)
+}
+
+// CreateWithinDailyLimit creates a new payment if it fits under the merchant's daily limit
+func (r *GormPaymentRepository) CreateWithinDailyLimit(payment *core.Payment, limit float64) error {
+	return r.create(payment, limit)
+}
+
+// create inserts the payment, its initial status event and, while it is PENDING, its outbox entry,
+// first checking it against dailyLimit when the limit is positive and against its parent's amount
+// when it is an installment, in the same transaction
+func (r *GormPaymentRepository) create(payment *core.Payment, dailyLimit float64) error {
 	dbPayment := fromCore(payment)
-	if err := r.gormDB.Create(dbPayment).Error; err != nil {
-		return fmt.Errorf("failed to create payment: %w", err)
+	err := r.gormDB.Transaction(func(tx *gorm.DB) error {
+		if dailyLimit > 0 {
+			if err := checkDailyLimit(tx, payment, dailyLimit); err != nil {
+				return err
+			}
+		}
+		if payment.IsInstallment() {
+			if err := checkInstallment(tx, payment); err != nil {
+				return err
+			}
+		}
+		if err := tx.Create(dbPayment).Error; err != nil {
+			// A concurrent insert can slip past the service's reference pre-check; report it the same way
+			if isUniqueViolation(err) {
+				return core.ErrReferenceExists
+			}
+			return fmt.Errorf("failed to create payment: %w", err)
+		}
+		if err := createPaymentEvent(tx, dbPayment.ID, "", payment.Status, ""); err != nil {
+			return err
+		}
+		// A payment held for review is published when it is approved, not now
+		if payment.Status != core.PaymentStatusPending {
+			return nil
+		}
+		outbox := db.PaymentOutbox{PaymentID: dbPayment.ID, NextAttemptAt: dbPayment.CreatedAt, CreatedAt: dbPayment.CreatedAt}
+		if err := tx.Create(&outbox).Error; err != nil {
+			return fmt.Errorf("failed to create outbox entry: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 	// Update core entity with timestamps set by GORM hooks
 	payment.CreatedAt = dbPayment.CreatedAt
@@ -60,22 +183,506 @@ func (r *GormPaymentRepository) Create(payment *core.Payment) error {
 	return nil
 }
 
+// checkDailyLimit returns core.ErrLimitExceeded if payment would take the merchant's total for
+// today in its currency past limit
+// A transaction-scoped advisory lock on the merchant serializes the check-and-insert of concurrent
+// limited creates; under READ COMMITTED both would otherwise sum the same rows and fit
+// The sum is served by idx_payments_merchant_created_at
+func checkDailyLimit(tx *gorm.DB, payment *core.Payment, limit float64) error {
+	if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", "daily_limit:"+payment.MerchantID).Error; err != nil {
+		return fmt.Errorf("failed to lock merchant daily limit: %w", err)
+	}
+
+	now, err := databaseNow(tx)
+	if err != nil {
+		return err
+	}
+	startOfDay := now.UTC().Truncate(24 * time.Hour)
+
+	var total float64
+	err = tx.Model(&db.Payment{}).
+		Select("COALESCE(SUM(amount), 0)").
+		Where("merchant_id = ? AND currency = ? AND created_at >= ?", payment.MerchantID, payment.Currency, startOfDay).
+		Where("status NOT IN ?", []db.PaymentStatus{db.PaymentStatusFailed, db.PaymentStatusExpired, db.PaymentStatusCancelled}).
+		Scan(&total).Error
+	if err != nil {
+		return fmt.Errorf("failed to sum today's payments: %w", err)
+	}
+
+	if toCents(total, payment.Currency)+toCents(payment.Amount, payment.Currency) > toCents(limit, payment.Currency) {
+		return fmt.Errorf("%w: %s %s already created today, the limit is %s",
+			core.ErrLimitExceeded, core.FormatAmount(total, payment.Currency), payment.Currency, core.FormatAmount(limit, payment.Currency))
+	}
+	return nil
+}
+
+// checkInstallment returns core.ErrInvalidParentPayment unless payment's parent is a payment of the
+// same merchant that is not an installment itself, core.ErrInvalidCurrency unless they share a currency,
+// and core.ErrInstallmentsExceedTotal if payment would take its siblings past the parent's amount
+// Locking the parent row serializes the check-and-insert of concurrent installments, like the
+// daily limit's advisory lock; the sum is served by idx_payments_parent_payment_id
+func checkInstallment(tx *gorm.DB, payment *core.Payment) error {
+	var parent db.Payment
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ? AND merchant_id = ?", *payment.ParentPaymentID, payment.MerchantID).
+		First(&parent).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return fmt.Errorf("%w: payment %s not found", core.ErrInvalidParentPayment, *payment.ParentPaymentID)
+		}
+		return fmt.Errorf("failed to lock parent payment: %w", err)
+	}
+	if parent.ParentPaymentID != nil {
+		return fmt.Errorf("%w: payment %s is an installment itself", core.ErrInvalidParentPayment, parent.ID)
+	}
+	if core.Currency(parent.Currency) != payment.Currency {
+		return fmt.Errorf("%w: installments must be in the parent payment's currency, %s", core.ErrInvalidCurrency, parent.Currency)
+	}
+
+	var total float64
+	err := tx.Model(&db.Payment{}).
+		Select("COALESCE(SUM(amount), 0)").
+		Where("parent_payment_id = ?", parent.ID).
+		Where("status NOT IN ?", []db.PaymentStatus{db.PaymentStatusFailed, db.PaymentStatusExpired, db.PaymentStatusCancelled}).
+		Scan(&total).Error
+	if err != nil {
+		return fmt.Errorf("failed to sum installments: %w", err)
+	}
+
+	if toCents(total, payment.Currency)+toCents(payment.Amount, payment.Currency) > toCents(parent.Amount, payment.Currency) {
+		return fmt.Errorf("%w: %s %s of %s already collected or in progress",
+			core.ErrInstallmentsExceedTotal, core.FormatAmount(total, payment.Currency), payment.Currency, core.FormatAmount(parent.Amount, payment.Currency))
+	}
+	return nil
+}
+
+// MarkPublished deletes the payments' outbox entries
+func (r *GormPaymentRepository) MarkPublished(ids []uuid.UUID) error {
+	if err := r.gormDB.Where("payment_id IN ?", ids).Delete(&db.PaymentOutbox{}).Error; err != nil {
+		return fmt.Errorf("failed to clear outbox entries: %w", err)
+	}
+	return nil
+}
+
+// ClaimUnpublished leases up to limit due outbox entries and returns their PENDING payments
+// SKIP LOCKED lets several relays claim disjoint batches without blocking each other
+func (r *GormPaymentRepository) ClaimUnpublished(now time.Time, minAge, lease time.Duration, limit int) ([]*core.Payment, error) {
+	var dbPayments []db.Payment
+	err := primary(r.gormDB).Transaction(func(tx *gorm.DB) error {
+		var entries []db.PaymentOutbox
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("next_attempt_at <= ? AND created_at <= ?", now, now.Add(-minAge)).
+			Order("next_attempt_at ASC").
+			Limit(limit).
+			Find(&entries).Error; err != nil {
+			return fmt.Errorf("failed to claim outbox entries: %w", err)
+		}
+		if len(entries) == 0 {
+			return nil
+		}
+
+		ids := make([]uuid.UUID, 0, len(entries))
+		for _, entry := range entries {
+			ids = append(ids, entry.PaymentID)
+		}
+		if err := tx.Where("id IN ? AND status = ?", ids, db.PaymentStatusPending).Find(&dbPayments).Error; err != nil {
+			return fmt.Errorf("failed to load unpublished payments: %w", err)
+		}
+
+		// A payment that left PENDING was processed (or cancelled), so its message needs no republishing
+		pending := make(map[uuid.UUID]bool, len(dbPayments))
+		for _, p := range dbPayments {
+			pending[p.ID] = true
+		}
+		var settled, leased []uuid.UUID
+		for _, id := range ids {
+			if pending[id] {
+				leased = append(leased, id)
+			} else {
+				settled = append(settled, id)
+			}
+		}
+		if len(settled) > 0 {
+			if err := tx.Where("payment_id IN ?", settled).Delete(&db.PaymentOutbox{}).Error; err != nil {
+				return fmt.Errorf("failed to clear outbox entries: %w", err)
+			}
+		}
+		if len(leased) > 0 {
+			if err := tx.Model(&db.PaymentOutbox{}).
+				Where("payment_id IN ?", leased).
+				Updates(map[string]interface{}{"next_attempt_at": now.Add(lease), "attempts": gorm.Expr("attempts + 1")}).Error; err != nil {
+				return fmt.Errorf("failed to lease outbox entries: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	payments := make([]*core.Payment, 0, len(dbPayments))
+	for i := range dbPayments {
+		payments = append(payments, toCore(&dbPayments[i]))
+	}
+	return payments, nil
+}
+
+// DeleteUnpublished hard-deletes the unprocessed payment, so its reference is free again, along
+// with its status event and outbox entry
+func (r *GormPaymentRepository) DeleteUnpublished(id uuid.UUID) error {
+	return r.gormDB.Transaction(func(tx *gorm.DB) error {
+		dbPayment, err := lockPendingPayment(tx, id)
+		if err != nil {
+			return err
+		}
+		if dbPayment.Attempts > 0 {
+			return fmt.Errorf("%w: processing already started", core.ErrPaymentAlreadyProcessed)
+		}
+
+		if err := tx.Where("payment_id = ?", id).Delete(&db.PaymentOutbox{}).Error; err != nil {
+			return fmt.Errorf("failed to delete outbox entry: %w", err)
+		}
+		if err := tx.Where("payment_id = ?", id).Delete(&db.PaymentEvent{}).Error; err != nil {
+			return fmt.Errorf("failed to delete payment events: %w", err)
+		}
+		if err := tx.Unscoped().Delete(&db.Payment{}, "id = ?", id).Error; err != nil {
+			return fmt.Errorf("failed to delete payment: %w", err)
+		}
+		return nil
+	})
+}
+
 // GetByID retrieves a payment by its ID
 func (r *GormPaymentRepository) GetByID(id uuid.UUID) (*core.Payment, error) {
 	var dbPayment db.Payment
 	if err := r.gormDB.Where("id = ?", id).First(&dbPayment).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return nil, fmt.Errorf("payment not found")
+			return nil, core.ErrPaymentNotFound
+		}
+		return nil, fmt.Errorf("failed to get payment: %w", err)
+	}
+	return toCore(&dbPayment), nil
+}
+
+// GetGroup retrieves a parent payment and its installments, oldest first
+// Listing the installments is served by idx_payments_parent_payment_id
+func (r *GormPaymentRepository) GetGroup(parentID uuid.UUID) (*core.PaymentGroup, error) {
+	parent, err := r.GetByID(parentID)
+	if err != nil {
+		return nil, err
+	}
+
+	var dbChildren []db.Payment
+	if err := r.gormDB.Where("parent_payment_id = ?", parentID).
+		Order("created_at ASC, id ASC").
+		Find(&dbChildren).Error; err != nil {
+		return nil, fmt.Errorf("failed to list installments: %w", err)
+	}
+
+	group := &core.PaymentGroup{Parent: parent, Children: make([]*core.Payment, 0, len(dbChildren))}
+	for i := range dbChildren {
+		group.Children = append(group.Children, toCore(&dbChildren[i]))
+	}
+	return group, nil
+}
+
+// GetUpdatedAt returns the payment's updated_at without loading the row
+func (r *GormPaymentRepository) GetUpdatedAt(id uuid.UUID, merchantID string) (time.Time, error) {
+	query := r.gormDB.Model(&db.Payment{}).Where("id = ?", id)
+	if merchantID != "" {
+		query = query.Where("merchant_id = ?", merchantID)
+	}
+	var updatedAt []time.Time
+	if err := query.Limit(1).Pluck("updated_at", &updatedAt).Error; err != nil {
+		return time.Time{}, fmt.Errorf("failed to check payment: %w", err)
+	}
+	if len(updatedAt) == 0 {
+		return time.Time{}, core.ErrPaymentNotFound
+	}
+	return updatedAt[0], nil
+}
+
+// GetByReference retrieves a payment by its reference
+// It reads from the primary, since reference retries arrive moments after the original
+// and a lagging replica may not have it yet
+func (r *GormPaymentRepository) GetByReference(reference string) (*core.Payment, error) {
+	var dbPayment db.Payment
+	if err := primary(r.gormDB).Where("reference = ?", reference).First(&dbPayment).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, core.ErrPaymentNotFound
+		}
+		return nil, fmt.Errorf("failed to get payment by reference: %w", err)
+	}
+	return toCore(&dbPayment), nil
+}
+
+// GetByIDWithRelations retrieves a payment by its ID, eager-loading the requested relations
+func (r *GormPaymentRepository) GetByIDWithRelations(id uuid.UUID, relations output.PaymentRelations) (*core.Payment, error) {
+	query := r.gormDB
+	if relations.Events {
+		query = query.Preload("Events", func(tx *gorm.DB) *gorm.DB {
+			return tx.Order("created_at ASC")
+		})
+	}
+	if relations.Ledger {
+		query = query.Preload("LedgerEntries", func(tx *gorm.DB) *gorm.DB {
+			return tx.Order("created_at ASC")
+		})
+	}
+
+	var dbPayment db.Payment
+	if err := query.Where("id = ?", id).First(&dbPayment).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, core.ErrPaymentNotFound
 		}
 		return nil, fmt.Errorf("failed to get payment: %w", err)
 	}
 	return toCore(&dbPayment), nil
 }
 
+// ListEvents retrieves a page of a payment's status events
+// Events recorded at the same instant are ordered by ID so pages don't overlap
+func (r *GormPaymentRepository) ListEvents(page output.EventPage) ([]core.PaymentEvent, error) {
+	order := "created_at DESC, id DESC"
+	if page.OldestFirst {
+		order = "created_at ASC, id ASC"
+	}
+
+	var dbEvents []db.PaymentEvent
+	err := r.gormDB.Where("payment_id = ?", page.PaymentID).
+		Order(order).
+		Limit(page.Limit).
+		Offset(page.Offset).
+		Find(&dbEvents).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list payment events: %w", err)
+	}
+
+	events := make([]core.PaymentEvent, 0, len(dbEvents))
+	for i := range dbEvents {
+		events = append(events, eventToCore(&dbEvents[i]))
+	}
+	return events, nil
+}
+
 // ProcessPayment atomically processes a payment if it's in PENDING status
 // Uses SELECT FOR UPDATE to prevent concurrent processing
-func (r *GormPaymentRepository) ProcessPayment(id uuid.UUID, newStatus core.PaymentStatus) error {
-	return r.gormDB.Transaction(func(tx *gorm.DB) error {
+// Expiry is checked under the same lock, so a message handled just after expires_at
+// can't settle a payment the sweeper is about to expire
+// The check uses the database's clock, so a worker with a skewed clock can't expire
+// a payment early or settle one late
+func (r *GormPaymentRepository) ProcessPayment(id uuid.UUID, newStatus core.PaymentStatus, note string) (core.PaymentStatus, error) {
+	err := primary(r.gormDB).Transaction(func(tx *gorm.DB) error {
+		dbPayment, err := lockPendingPayment(tx, id)
+		if err != nil {
+			return err
+		}
+		now, err := databaseNow(tx)
+		if err != nil {
+			return err
+		}
+		newStatus, err = applyProcessedStatus(tx, r.webhooks, dbPayment, now, newStatus, note)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+	return newStatus, nil
+}
+
+// ProcessPayments applies the updates like ProcessPayment, in one transaction for all of them
+// The rows are locked in id order, so concurrent batches can't deadlock on each other
+func (r *GormPaymentRepository) ProcessPayments(updates []output.StatusUpdate) (map[uuid.UUID]core.PaymentStatus, error) {
+	ids := make([]uuid.UUID, 0, len(updates))
+	for _, update := range updates {
+		ids = append(ids, update.PaymentID)
+	}
+
+	applied := make(map[uuid.UUID]core.PaymentStatus, len(updates))
+	err := primary(r.gormDB).Transaction(func(tx *gorm.DB) error {
+		pending, now, err := lockPendingPayments(tx, ids)
+		if err != nil {
+			return err
+		}
+		for _, update := range updates {
+			dbPayment, ok := pending[update.PaymentID]
+			if !ok {
+				continue
+			}
+			delete(pending, update.PaymentID)
+			status, err := applyProcessedStatus(tx, r.webhooks, dbPayment, now, update.Status, update.Note)
+			if err != nil {
+				return err
+			}
+			applied[update.PaymentID] = status
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return applied, nil
+}
+
+// lockPendingPayment locks the payment's row with SELECT FOR UPDATE, returning
+// core.ErrPaymentAlreadyProcessed unless it is PENDING
+func lockPendingPayment(tx *gorm.DB, id uuid.UUID) (*db.Payment, error) {
+	var dbPayment db.Payment
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", id).
+		First(&dbPayment).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, core.ErrPaymentNotFound
+		}
+		return nil, fmt.Errorf("failed to lock payment: %w", err)
+	}
+	if dbPayment.Status != db.PaymentStatusPending {
+		return nil, fmt.Errorf("%w: current status is %s", core.ErrPaymentAlreadyProcessed, dbPayment.Status)
+	}
+	return &dbPayment, nil
+}
+
+// lockPendingPayments locks the rows of the PENDING payments among ids in id order, returning
+// them by ID with the database's time; missing and no longer PENDING payments are left out
+func lockPendingPayments(tx *gorm.DB, ids []uuid.UUID) (map[uuid.UUID]*db.Payment, time.Time, error) {
+	var dbPayments []db.Payment
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id IN ? AND status = ?", ids, db.PaymentStatusPending).
+		Order("id").
+		Find(&dbPayments).Error; err != nil {
+		return nil, time.Time{}, fmt.Errorf("failed to lock payments: %w", err)
+	}
+	now, err := databaseNow(tx)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	pending := make(map[uuid.UUID]*db.Payment, len(dbPayments))
+	for i := range dbPayments {
+		pending[dbPayments[i].ID] = &dbPayments[i]
+	}
+	return pending, now, nil
+}
+
+// applyProcessedStatus moves the locked PENDING payment to newStatus, or to EXPIRED when it is past
+// its expiry at now, recording the event, the merchant webhook and, for SUCCESS, the ledger entries;
+// it returns the status applied
+func applyProcessedStatus(tx *gorm.DB, webhooks output.WebhookFactory, dbPayment *db.Payment, now time.Time, newStatus core.PaymentStatus, note string) (core.PaymentStatus, error) {
+	// A payment past its expiry expires instead of settling
+	if toCore(dbPayment).IsExpiredAt(now) {
+		newStatus = core.PaymentStatusExpired
+		note = core.NoteExpired
+	}
+
+	// Update the payment status
+	dbPayment.Status = db.PaymentStatus(newStatus)
+	dbPayment.UpdatedAt = now
+
+	if err := tx.Save(dbPayment).Error; err != nil {
+		return "", fmt.Errorf("failed to update payment: %w", err)
+	}
+
+	// Record the transition in the payment's status history
+	if err := createPaymentEvent(tx, dbPayment.ID, core.PaymentStatusPending, newStatus, note); err != nil {
+		return "", err
+	}
+	if err := createWebhookDelivery(tx, webhooks, dbPayment); err != nil {
+		return "", err
+	}
+
+	// Record balanced ledger entries in the same transaction as the status update
+	if newStatus == core.PaymentStatusSuccess {
+		if err := createLedgerEntries(tx, core.SettlementEntries(toCore(dbPayment))); err != nil {
+			return "", err
+		}
+	}
+	return newStatus, nil
+}
+
+// StartAttempt increments the payment's attempts under a row lock, or gives up on it once the
+// budget is spent, so the count survives redeliveries and broker restarts alike
+func (r *GormPaymentRepository) StartAttempt(id uuid.UUID, maxAttempts int) (*core.Payment, error) {
+	var result *core.Payment
+	err := primary(r.gormDB).Transaction(func(tx *gorm.DB) error {
+		dbPayment, err := lockPendingPayment(tx, id)
+		if err != nil {
+			return err
+		}
+		now, err := databaseNow(tx)
+		if err != nil {
+			return err
+		}
+		if err := startAttempt(tx, r.webhooks, dbPayment, now, maxAttempts); err != nil {
+			return err
+		}
+		result = toCore(dbPayment)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// StartAttempts counts an attempt on each PENDING payment among ids like StartAttempt, in one transaction
+func (r *GormPaymentRepository) StartAttempts(ids []uuid.UUID, maxAttempts int) ([]*core.Payment, error) {
+	var started []*core.Payment
+	err := primary(r.gormDB).Transaction(func(tx *gorm.DB) error {
+		pending, now, err := lockPendingPayments(tx, ids)
+		if err != nil {
+			return err
+		}
+		for _, id := range ids {
+			dbPayment, ok := pending[id]
+			if !ok {
+				continue
+			}
+			// A redelivered ID appears twice; count it once
+			delete(pending, id)
+			if err := startAttempt(tx, r.webhooks, dbPayment, now, maxAttempts); err != nil {
+				return err
+			}
+			started = append(started, toCore(dbPayment))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return started, nil
+}
+
+// startAttempt counts an attempt on the locked PENDING payment, or once maxAttempts are used up
+// fails it (expires it, if it is past its expiry at now) rather than let the broker redeliver it forever
+func startAttempt(tx *gorm.DB, webhooks output.WebhookFactory, dbPayment *db.Payment, now time.Time, maxAttempts int) error {
+	dbPayment.UpdatedAt = now
+	if dbPayment.Attempts < maxAttempts {
+		dbPayment.Attempts++
+		if err := tx.Save(dbPayment).Error; err != nil {
+			return fmt.Errorf("failed to count attempt: %w", err)
+		}
+		return nil
+	}
+
+	status, note := core.PaymentStatusFailed, core.NoteMaxAttempts
+	if toCore(dbPayment).IsExpiredAt(now) {
+		status, note = core.PaymentStatusExpired, core.NoteExpired
+	}
+	dbPayment.Status = db.PaymentStatus(status)
+	if err := tx.Save(dbPayment).Error; err != nil {
+		return fmt.Errorf("failed to update payment: %w", err)
+	}
+	if err := createPaymentEvent(tx, dbPayment.ID, core.PaymentStatusPending, status, note); err != nil {
+		return err
+	}
+	return createWebhookDelivery(tx, webhooks, dbPayment)
+}
+
+// Capture settles an AUTHORIZED payment for amount (0 captures the full authorized amount)
+// The row is locked so concurrent captures of the same payment are serialized; the payment's
+// amount becomes the captured amount, and the ledger entries and merchant webhook are recorded for it
+// in the same transaction
+func (r *GormPaymentRepository) Capture(id uuid.UUID, amount float64) (*core.Payment, error) {
+	var result *core.Payment
+	err := primary(r.gormDB).Transaction(func(tx *gorm.DB) error {
 		var dbPayment db.Payment
 
 		// Lock the row and check status using SELECT FOR UPDATE
@@ -83,36 +690,501 @@ func (r *GormPaymentRepository) ProcessPayment(id uuid.UUID, newStatus core.Paym
 			Where("id = ?", id).
 			First(&dbPayment).Error; err != nil {
 			if err == gorm.ErrRecordNotFound {
-				return fmt.Errorf("payment not found")
+				return core.ErrPaymentNotFound
+			}
+			return fmt.Errorf("failed to lock payment: %w", err)
+		}
+
+		if dbPayment.Status != db.PaymentStatusAuthorized {
+			return fmt.Errorf("%w: current status is %s", core.ErrPaymentNotCapturable, dbPayment.Status)
+		}
+
+		currency := core.Currency(dbPayment.Currency)
+		authorizedCents := toCents(dbPayment.AuthorizedAmount, currency)
+		note := core.NoteCaptured
+		if amount == 0 {
+			amount = dbPayment.AuthorizedAmount
+		}
+		if toCents(amount, currency) > authorizedCents {
+			return core.ErrCaptureExceedsAuthorization
+		}
+		if toCents(amount, currency) < authorizedCents {
+			note = core.NotePartialCapture
+		}
+
+		now, err := databaseNow(tx)
+		if err != nil {
+			return err
+		}
+		dbPayment.Amount = amount
+		dbPayment.Status = db.PaymentStatusSuccess
+		dbPayment.UpdatedAt = now
+		if err := tx.Save(&dbPayment).Error; err != nil {
+			return fmt.Errorf("failed to update payment: %w", err)
+		}
+
+		if err := createPaymentEvent(tx, dbPayment.ID, core.PaymentStatusAuthorized, core.PaymentStatusSuccess, note); err != nil {
+			return err
+		}
+
+		if err := createWebhookDelivery(tx, r.webhooks, &dbPayment); err != nil {
+			return err
+		}
+
+		// Only the captured amount is settled
+		result = toCore(&dbPayment)
+		return createLedgerEntries(tx, core.SettlementEntries(result))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Void moves an AUTHORIZED payment to CANCELLED
+// The row is locked so a void can't race a capture of the same payment; an authorization has no
+// ledger entries, so there is nothing to reverse
+func (r *GormPaymentRepository) Void(id uuid.UUID) (*core.Payment, error) {
+	var result *core.Payment
+	err := primary(r.gormDB).Transaction(func(tx *gorm.DB) error {
+		var dbPayment db.Payment
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", id).
+			First(&dbPayment).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return core.ErrPaymentNotFound
+			}
+			return fmt.Errorf("failed to lock payment: %w", err)
+		}
+
+		if dbPayment.Status != db.PaymentStatusAuthorized {
+			return fmt.Errorf("%w: current status is %s", core.ErrPaymentNotVoidable, dbPayment.Status)
+		}
+
+		now, err := databaseNow(tx)
+		if err != nil {
+			return err
+		}
+		dbPayment.Status = db.PaymentStatusCancelled
+		dbPayment.UpdatedAt = now
+		if err := tx.Save(&dbPayment).Error; err != nil {
+			return fmt.Errorf("failed to update payment: %w", err)
+		}
+
+		if err := createPaymentEvent(tx, dbPayment.ID, core.PaymentStatusAuthorized, core.PaymentStatusCancelled, core.NoteVoided); err != nil {
+			return err
+		}
+
+		result = toCore(&dbPayment)
+		return createWebhookDelivery(tx, r.webhooks, &dbPayment)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ResolveReview moves a REVIEW payment to the operator's decision, PENDING or FAILED
+// The row is locked so two operators deciding the same payment can't both record a transition
+// An approved payment gets its outbox entry in the same transaction, so the relay enqueues it
+// even if the caller's publish fails
+func (r *GormPaymentRepository) ResolveReview(id uuid.UUID, decision core.PaymentStatus, note string) (*core.Payment, error) {
+	var result *core.Payment
+	err := primary(r.gormDB).Transaction(func(tx *gorm.DB) error {
+		var dbPayment db.Payment
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", id).
+			First(&dbPayment).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return core.ErrPaymentNotFound
 			}
 			return fmt.Errorf("failed to lock payment: %w", err)
 		}
 
-		// Only process if status is PENDING
-		if dbPayment.Status != db.PaymentStatusPending {
-			return fmt.Errorf("payment already processed: current status is %s", dbPayment.Status)
+		if dbPayment.Status != db.PaymentStatusReview {
+			return fmt.Errorf("%w: current status is %s", core.ErrPaymentNotInReview, dbPayment.Status)
+		}
+
+		now, err := databaseNow(tx)
+		if err != nil {
+			return err
+		}
+		dbPayment.Status = db.PaymentStatus(decision)
+		dbPayment.UpdatedAt = now
+		if err := tx.Save(&dbPayment).Error; err != nil {
+			return fmt.Errorf("failed to update payment: %w", err)
+		}
+		if err := createPaymentEvent(tx, dbPayment.ID, core.PaymentStatusReview, decision, note); err != nil {
+			return err
+		}
+
+		result = toCore(&dbPayment)
+		if decision == core.PaymentStatusPending {
+			return enqueueOutbox(tx, dbPayment.ID, now)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// OverrideStatus forces the payment to status under a row lock, bypassing the transition table
+// The recorded event keeps the actor, so overrides stand out from regular transitions
+// The ledger is brought in line with the new status in the same transaction, and a payment sent
+// back to PENDING gets a fresh attempt budget and an outbox entry, so the relay re-enqueues it
+// even if the caller's publish fails
+func (r *GormPaymentRepository) OverrideStatus(id uuid.UUID, status core.PaymentStatus, actor, note string) (*core.Payment, error) {
+	var result *core.Payment
+	err := primary(r.gormDB).Transaction(func(tx *gorm.DB) error {
+		var dbPayment db.Payment
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", id).
+			First(&dbPayment).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return core.ErrPaymentNotFound
+			}
+			return fmt.Errorf("failed to lock payment: %w", err)
 		}
 
-		// Update the payment status
-		dbPayment.Status = db.PaymentStatus(newStatus)
-		dbPayment.UpdatedAt = time.Now()
+		from := core.PaymentStatus(dbPayment.Status)
+		if from == status {
+			return fmt.Errorf("%w: payment is already %s", core.ErrPaymentStatusUnchanged, status)
+		}
 
+		now, err := databaseNow(tx)
+		if err != nil {
+			return err
+		}
+		dbPayment.Status = db.PaymentStatus(status)
+		dbPayment.UpdatedAt = now
+		if status == core.PaymentStatusPending {
+			dbPayment.Attempts = 0
+		}
 		if err := tx.Save(&dbPayment).Error; err != nil {
 			return fmt.Errorf("failed to update payment: %w", err)
 		}
+		event := &db.PaymentEvent{
+			PaymentID:  dbPayment.ID,
+			FromStatus: db.PaymentStatus(from),
+			ToStatus:   db.PaymentStatus(status),
+			Note:       note,
+			Actor:      actor,
+		}
+		if err := tx.Create(event).Error; err != nil {
+			return fmt.Errorf("failed to record payment event: %w", err)
+		}
 
+		result = toCore(&dbPayment)
+		if err := settleToStatus(tx, result); err != nil {
+			return err
+		}
+		if status == core.PaymentStatusPending {
+			return enqueueOutbox(tx, dbPayment.ID, now)
+		}
 		return nil
 	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// settleToStatus posts the ledger entries that bring the payment's net settlement in line with
+// its status: what was paid less refunds for SUCCESS, nothing for any other status
+// The current settlement is summed from the ledger itself, so earlier overrides, captures and
+// refunds are all accounted for
+func settleToStatus(tx *gorm.DB, payment *core.Payment) error {
+	settled, err := settledTotal(tx, payment.ID)
+	if err != nil {
+		return err
+	}
+
+	var target float64
+	if payment.Status == core.PaymentStatusSuccess {
+		refunded, err := refundedTotal(tx, payment.ID)
+		if err != nil {
+			return err
+		}
+		target = payment.Amount - refunded
+	}
+
+	diffCents := toCents(target, payment.Currency) - toCents(settled, payment.Currency)
+	switch {
+	case diffCents > 0:
+		return createLedgerEntries(tx, core.SettlementAdjustmentEntries(payment, fromCents(diffCents, payment.Currency)))
+	case diffCents < 0:
+		return createLedgerEntries(tx, core.ReversalEntries(payment, fromCents(-diffCents, payment.Currency)))
+	}
+	return nil
+}
+
+// settledTotal returns a payment's net balance in the merchant settlement account: settlements
+// credited less reversals debited
+func settledTotal(tx *gorm.DB, paymentID uuid.UUID) (float64, error) {
+	var total float64
+	if err := tx.Model(&db.LedgerEntry{}).
+		Select("COALESCE(SUM(CASE WHEN direction = ? THEN amount ELSE -amount END), 0)", db.LedgerDirectionCredit).
+		Where("payment_id = ? AND account = ?", paymentID, core.LedgerAccountMerchantSettlement).
+		Scan(&total).Error; err != nil {
+		return 0, fmt.Errorf("failed to sum ledger entries: %w", err)
+	}
+	return total, nil
+}
+
+// createWebhookDelivery stores the merchant webhook for the payment's new status using the given
+// transaction, so a delivery is never lost between committing a status and enqueueing its webhook
+func createWebhookDelivery(tx *gorm.DB, webhooks output.WebhookFactory, dbPayment *db.Payment) error {
+	if webhooks == nil {
+		return nil
+	}
+	delivery, err := webhooks.NewDelivery(toCore(dbPayment))
+	if err != nil {
+		return fmt.Errorf("failed to build webhook delivery: %w", err)
+	}
+	if delivery == nil {
+		return nil
+	}
+	if err := tx.Create(webhookFromCore(delivery)).Error; err != nil {
+		return fmt.Errorf("failed to create webhook delivery: %w", err)
+	}
+	return nil
+}
+
+// enqueueOutbox marks the payment's payment.created message as unpublished, due at now
+// An entry left over from an earlier enqueue is reset rather than duplicated
+func enqueueOutbox(tx *gorm.DB, paymentID uuid.UUID, now time.Time) error {
+	outbox := db.PaymentOutbox{PaymentID: paymentID, NextAttemptAt: now, CreatedAt: now}
+	if err := tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "payment_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{"attempts": 0, "next_attempt_at": now, "created_at": now}),
+	}).Create(&outbox).Error; err != nil {
+		return fmt.Errorf("failed to create outbox entry: %w", err)
+	}
+	return nil
+}
+
+// List retrieves payments matching the filter, newest first
+// Merchant-scoped time-window queries are served by idx_payments_merchant_created_at
+func (r *GormPaymentRepository) List(filter output.PaymentFilter) ([]*core.Payment, error) {
+	query := applyPaymentFilter(r.gormDB.Model(&db.Payment{}), filter)
+
+	var dbPayments []db.Payment
+	if err := query.Order("created_at DESC").
+		Limit(filter.Limit).
+		Offset(filter.Offset).
+		Find(&dbPayments).Error; err != nil {
+		return nil, fmt.Errorf("failed to list payments: %w", err)
+	}
+
+	payments := make([]*core.Payment, 0, len(dbPayments))
+	for i := range dbPayments {
+		payments = append(payments, toCore(&dbPayments[i]))
+	}
+	return payments, nil
+}
+
+// ListAfter retrieves a page of matching payments after the cursor, oldest first
+// The row comparison keeps pages stable while payments are created or change status mid-scan
+func (r *GormPaymentRepository) ListAfter(filter output.PaymentFilter, after output.PaymentCursor) ([]*core.Payment, error) {
+	query := applyPaymentFilter(r.gormDB.Model(&db.Payment{}), filter)
+	if !after.IsZero() {
+		query = query.Where("(created_at, id) > (?, ?)", after.CreatedAt, after.ID)
+	}
+
+	var dbPayments []db.Payment
+	if err := query.Order("created_at ASC, id ASC").
+		Limit(filter.Limit).
+		Find(&dbPayments).Error; err != nil {
+		return nil, fmt.Errorf("failed to list payments: %w", err)
+	}
+
+	payments := make([]*core.Payment, 0, len(dbPayments))
+	for i := range dbPayments {
+		payments = append(payments, toCore(&dbPayments[i]))
+	}
+	return payments, nil
+}
+
+// ListPendingBefore retrieves up to limit PENDING payments created before cutoff, oldest first
+// Served by the partial index idx_payments_pending_created_at
+func (r *GormPaymentRepository) ListPendingBefore(cutoff time.Time, limit int) ([]*core.Payment, error) {
+	var dbPayments []db.Payment
+	if err := r.gormDB.Where("status = ? AND created_at < ?", db.PaymentStatusPending, cutoff).
+		Order("created_at ASC").
+		Limit(limit).
+		Find(&dbPayments).Error; err != nil {
+		return nil, fmt.Errorf("failed to list pending payments: %w", err)
+	}
+
+	payments := make([]*core.Payment, 0, len(dbPayments))
+	for i := range dbPayments {
+		payments = append(payments, toCore(&dbPayments[i]))
+	}
+	return payments, nil
+}
+
+// ExpireDue moves due PENDING payments to EXPIRED in one transaction
+// Due is judged by the database's clock, the same one ProcessPayment checks expiry against
+// Served by the partial idx_payments_pending_expires_at index; SKIP LOCKED lets
+// concurrent sweepers and the processor's row lock proceed without waiting on each other
+func (r *GormPaymentRepository) ExpireDue(limit int) ([]*core.Payment, error) {
+	var dbPayments []db.Payment
+
+	err := primary(r.gormDB).Transaction(func(tx *gorm.DB) error {
+		now, err := databaseNow(tx)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND expires_at IS NOT NULL AND expires_at <= ?", db.PaymentStatusPending, now).
+			Order("expires_at ASC").
+			Limit(limit).
+			Find(&dbPayments).Error; err != nil {
+			return fmt.Errorf("failed to lock expired payments: %w", err)
+		}
+
+		for i := range dbPayments {
+			dbPayments[i].Status = db.PaymentStatusExpired
+			dbPayments[i].UpdatedAt = now
+			if err := tx.Save(&dbPayments[i]).Error; err != nil {
+				return fmt.Errorf("failed to expire payment: %w", err)
+			}
+			if err := createPaymentEvent(tx, dbPayments[i].ID, core.PaymentStatusPending, core.PaymentStatusExpired, core.NoteExpired); err != nil {
+				return err
+			}
+			if err := createWebhookDelivery(tx, r.webhooks, &dbPayments[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	payments := make([]*core.Payment, 0, len(dbPayments))
+	for i := range dbPayments {
+		payments = append(payments, toCore(&dbPayments[i]))
+	}
+	return payments, nil
+}
+
+// Count counts payments matching the filter, ignoring Limit and Offset
+func (r *GormPaymentRepository) Count(filter output.PaymentFilter) (int64, error) {
+	var count int64
+	if err := applyPaymentFilter(r.gormDB.Model(&db.Payment{}), filter).
+		Count(&count).Error; err != nil {
+		return 0, fmt.Errorf("failed to count payments: %w", err)
+	}
+	return count, nil
+}
+
+// ResetForReprocess moves eligible FAILED payments back to PENDING in one transaction, with a fresh attempt budget
+// and an outbox entry, so the relay enqueues them even if the caller's publish fails
+// SELECT FOR UPDATE keeps a concurrent reset from recording the transition twice
+func (r *GormPaymentRepository) ResetForReprocess(ids []uuid.UUID, note string) ([]*core.Payment, error) {
+	var reset []*core.Payment
+
+	err := primary(r.gormDB).Transaction(func(tx *gorm.DB) error {
+		now, err := databaseNow(tx)
+		if err != nil {
+			return err
+		}
+
+		var dbPayments []db.Payment
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id IN ? AND status = ?", ids, db.PaymentStatusFailed).
+			Order("created_at ASC").
+			Find(&dbPayments).Error; err != nil {
+			return fmt.Errorf("failed to lock payments: %w", err)
+		}
+
+		for i := range dbPayments {
+			if !toCore(&dbPayments[i]).CanReprocessAt(now) {
+				continue
+			}
+			dbPayments[i].Status = db.PaymentStatusPending
+			dbPayments[i].Attempts = 0
+			dbPayments[i].UpdatedAt = now
+			if err := tx.Save(&dbPayments[i]).Error; err != nil {
+				return fmt.Errorf("failed to reset payment: %w", err)
+			}
+			if err := createPaymentEvent(tx, dbPayments[i].ID, core.PaymentStatusFailed, core.PaymentStatusPending, note); err != nil {
+				return err
+			}
+			if err := enqueueOutbox(tx, dbPayments[i].ID, now); err != nil {
+				return err
+			}
+			reset = append(reset, toCore(&dbPayments[i]))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reset, nil
+}
+
+// SoftDelete marks payments matching the filter as deleted in a single bulk UPDATE ... SET deleted_at
+func (r *GormPaymentRepository) SoftDelete(filter output.PaymentFilter) (int64, error) {
+	result := applyPaymentFilter(r.gormDB, filter).Delete(&db.Payment{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to delete payments: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// likeEscaper escapes LIKE's wildcards, which are allowed in references, so they match literally
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// applyPaymentFilter adds the filter's WHERE conditions to a query
+func applyPaymentFilter(query *gorm.DB, filter output.PaymentFilter) *gorm.DB {
+	if filter.IDs != nil {
+		query = query.Where("id IN ?", filter.IDs)
+	}
+	if filter.MerchantID != "" {
+		query = query.Where("merchant_id = ?", filter.MerchantID)
+	}
+	if filter.IsTest != nil {
+		query = query.Where("is_test = ?", *filter.IsTest)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.Method != "" {
+		query = query.Where("method = ?", filter.Method)
+	}
+	if !filter.CreatedAfter.IsZero() {
+		query = query.Where("created_at >= ?", filter.CreatedAfter)
+	}
+	if !filter.CreatedBefore.IsZero() {
+		query = query.Where("created_at < ?", filter.CreatedBefore)
+	}
+	if filter.CustomerID != "" {
+		query = query.Where("customer_id = ?", filter.CustomerID)
+	}
+	if filter.RefPrefix != "" {
+		// A left-anchored LIKE is served by idx_payments_reference_pattern
+		query = query.Where(`reference LIKE ? ESCAPE '\'`, likeEscaper.Replace(filter.RefPrefix)+"%")
+	}
+	if filter.Tag != "" {
+		// JSONB containment is served by the GIN index idx_payments_tags
+		query = query.Where("tags @> ?", db.Tags{filter.Tag})
+	}
+	return query
 }
 
 // ReferenceExists checks if a reference already exists
+// Soft-deleted payments are included since the unique index still covers them
 func (r *GormPaymentRepository) ReferenceExists(reference string) (bool, error) {
 	var count int64
-	if err := r.gormDB.Model(&db.Payment{}).
+	if err := r.gormDB.Unscoped().Model(&db.Payment{}).
 		Where("reference = ?", reference).
 		Count(&count).Error; err != nil {
 		return false, fmt.Errorf("failed to check reference: %w", err)
 	}
 	return count > 0, nil
 }
-
@@ -0,0 +1,585 @@
+//go:build integration
+
+package database
+
+import (
+	"errors"
+	"log"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cashflow/payment-gateway/internal/constant/model/db"
+	"github.com/cashflow/payment-gateway/internal/core"
+	"github.com/cashflow/payment-gateway/internal/port/output"
+	"github.com/cashflow/payment-gateway/internal/testutil/dbtest"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// databaseURL is the Postgres the tests run against, set by TestMain
+var databaseURL string
+
+// TestMain provides Postgres for the package's tests, a container unless TEST_DATABASE_URL is set
+func TestMain(m *testing.M) {
+	url, stop, err := dbtest.Start()
+	if err != nil {
+		log.Fatalf("failed to provide Postgres (or set TEST_DATABASE_URL): %v", err)
+	}
+	databaseURL = url
+	code := m.Run()
+	stop()
+	os.Exit(code)
+}
+
+// openTestDB connects to the test Postgres in a schema of its own, dropped when the test ends
+func openTestDB(t *testing.T) *db.DB {
+	t.Helper()
+	return dbtest.Open(t, databaseURL)
+}
+
+// newPendingPayment returns a PENDING payment with a unique reference, ready to be created
+func newPendingPayment() *core.Payment {
+	return &core.Payment{
+		ID:         core.NewTimeOrderedID(),
+		MerchantID: "merchant-integration",
+		Amount:     75.25,
+		Currency:   core.CurrencyETB,
+		Reference:  "REF-" + uuid.NewString(),
+		Status:     core.PaymentStatusPending,
+		Source:     core.PaymentSourceAPI,
+		Method:     core.PaymentMethodUnknown,
+	}
+}
+
+// countRows counts the rows of model matching the query
+func countRows(t *testing.T, conn *db.DB, model interface{}, query string, args ...interface{}) int64 {
+	t.Helper()
+	var count int64
+	if err := conn.Model(model).Where(query, args...).Count(&count).Error; err != nil {
+		t.Fatalf("failed to count rows: %v", err)
+	}
+	return count
+}
+
+func TestProcessPaymentConcurrentCallsTransitionOnce(t *testing.T) {
+	const callers = 16
+
+	conn := openTestDB(t)
+	repo := NewGormPaymentRepository(conn.DB, nil)
+	payment := newPendingPayment()
+	if err := repo.Create(payment); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	// Release every caller at once, so they all contend for the row lock
+	start := make(chan struct{})
+	statuses := make([]core.PaymentStatus, callers)
+	errs := make([]error, callers)
+	var wg sync.WaitGroup
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			statuses[i], errs[i] = repo.ProcessPayment(payment.ID, core.PaymentStatusSuccess, core.NoteGatewayApproved)
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+
+	succeeded := 0
+	for i, err := range errs {
+		switch {
+		case err == nil:
+			succeeded++
+			if statuses[i] != core.PaymentStatusSuccess {
+				t.Errorf("caller %d: applied %s, want SUCCESS", i, statuses[i])
+			}
+		case !errors.Is(err, core.ErrPaymentAlreadyProcessed):
+			t.Errorf("caller %d: got %v, want ErrPaymentAlreadyProcessed", i, err)
+		}
+	}
+	if succeeded != 1 {
+		t.Fatalf("callers that transitioned the payment: got %d, want 1", succeeded)
+	}
+
+	stored, err := repo.GetByID(payment.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if stored.Status != core.PaymentStatusSuccess {
+		t.Errorf("status: got %s, want SUCCESS", stored.Status)
+	}
+	if n := countRows(t, conn, &db.PaymentEvent{}, "payment_id = ? AND from_status = ?", payment.ID, db.PaymentStatusPending); n != 1 {
+		t.Errorf("transitions out of PENDING: got %d, want 1", n)
+	}
+	// One posting is one balanced debit/credit pair
+	if n := countRows(t, conn, &db.LedgerEntry{}, "payment_id = ?", payment.ID); n != 2 {
+		t.Errorf("ledger entries: got %d, want one settlement pair", n)
+	}
+}
+
+func TestCreateDuplicateReference(t *testing.T) {
+	conn := openTestDB(t)
+	repo := NewGormPaymentRepository(conn.DB, nil)
+
+	first := newPendingPayment()
+	if err := repo.Create(first); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	// The second insert skips the service's pre-check, so only the unique index catches it
+	second := newPendingPayment()
+	second.Reference = first.Reference
+	if err := repo.Create(second); !errors.Is(err, core.ErrReferenceExists) {
+		t.Fatalf("second Create: got %v, want ErrReferenceExists", err)
+	}
+
+	// The rejected insert rolls back with its event and outbox entry
+	if _, err := repo.GetByID(second.ID); !errors.Is(err, core.ErrPaymentNotFound) {
+		t.Errorf("GetByID of the rejected payment: got %v, want ErrPaymentNotFound", err)
+	}
+	if n := countRows(t, conn, &db.PaymentOutbox{}, "payment_id = ?", second.ID); n != 0 {
+		t.Errorf("outbox entries of the rejected payment: got %d, want 0", n)
+	}
+}
+
+func TestProcessPaymentExpiryBoundary(t *testing.T) {
+	conn := openTestDB(t)
+	repo := NewGormPaymentRepository(conn.DB, nil)
+
+	tests := []struct {
+		name   string
+		offset int // expires_at relative to the database clock, in microseconds (its precision)
+		want   core.PaymentStatus
+	}{
+		{name: "just before", offset: -1, want: core.PaymentStatusExpired},
+		{name: "equal", offset: 0, want: core.PaymentStatusExpired},
+		{name: "just after", offset: 1, want: core.PaymentStatusSuccess},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payment := newPendingPayment()
+			if err := repo.Create(payment); err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+
+			// now() is fixed for the whole transaction, so setting expires_at from it in the same
+			// transaction as ProcessPayment's steps pins the boundary exactly
+			var applied core.PaymentStatus
+			err := conn.Transaction(func(tx *gorm.DB) error {
+				if err := tx.Exec("UPDATE payments SET expires_at = now() + CAST(? AS double precision) * interval '1 microsecond' WHERE id = ?", tt.offset, payment.ID).Error; err != nil {
+					return err
+				}
+				dbPayment, err := lockPendingPayment(tx, payment.ID)
+				if err != nil {
+					return err
+				}
+				now, err := databaseNow(tx)
+				if err != nil {
+					return err
+				}
+				applied, err = applyProcessedStatus(tx, nil, dbPayment, now, core.PaymentStatusSuccess, core.NoteGatewayApproved)
+				return err
+			})
+			if err != nil {
+				t.Fatalf("processing: %v", err)
+			}
+			if applied != tt.want {
+				t.Errorf("applied status: got %s, want %s", applied, tt.want)
+			}
+
+			stored, err := repo.GetByIDWithRelations(payment.ID, output.PaymentRelations{Events: true, Ledger: true})
+			if err != nil {
+				t.Fatalf("GetByIDWithRelations: %v", err)
+			}
+			if stored.Status != tt.want {
+				t.Errorf("stored status: got %s, want %s", stored.Status, tt.want)
+			}
+			wantNote, wantEntries := core.NoteGatewayApproved, 2
+			if tt.want == core.PaymentStatusExpired {
+				wantNote, wantEntries = core.NoteExpired, 0
+			}
+			if last := stored.Events[len(stored.Events)-1]; last.ToStatus != tt.want || last.Note != wantNote {
+				t.Errorf("last event: got %s (%s), want %s (%s)", last.ToStatus, last.Note, tt.want, wantNote)
+			}
+			if len(stored.LedgerEntries) != wantEntries {
+				t.Errorf("ledger entries: got %d, want %d", len(stored.LedgerEntries), wantEntries)
+			}
+		})
+	}
+}
+
+func TestProcessPaymentAfterExpiry(t *testing.T) {
+	conn := openTestDB(t)
+	repo := NewGormPaymentRepository(conn.DB, nil)
+
+	// Expired by the database clock even though it was valid when the message was published
+	payment := newPendingPayment()
+	if err := repo.Create(payment); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if err := conn.Exec("UPDATE payments SET expires_at = now() - interval '1 second' WHERE id = ?", payment.ID).Error; err != nil {
+		t.Fatalf("failed to expire payment: %v", err)
+	}
+
+	applied, err := repo.ProcessPayment(payment.ID, core.PaymentStatusSuccess, core.NoteGatewayApproved)
+	if err != nil {
+		t.Fatalf("ProcessPayment: %v", err)
+	}
+	if applied != core.PaymentStatusExpired {
+		t.Errorf("applied status: got %s, want EXPIRED", applied)
+	}
+	if n := countRows(t, conn, &db.LedgerEntry{}, "payment_id = ?", payment.ID); n != 0 {
+		t.Errorf("ledger entries: got %d, want none for an expired payment", n)
+	}
+}
+
+func TestOverrideStatusSettlesLedgerAndEnqueues(t *testing.T) {
+	conn := openTestDB(t)
+	repo := NewGormPaymentRepository(conn.DB, nil)
+	refunds := NewGormRefundRepository(conn.DB)
+	payment := newPendingPayment()
+	if err := repo.Create(payment); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if err := repo.MarkPublished([]uuid.UUID{payment.ID}); err != nil {
+		t.Fatalf("MarkPublished: %v", err)
+	}
+
+	// Each step overrides the payment left by the previous one; refund is issued after the override
+	steps := []struct {
+		status      core.PaymentStatus
+		refund      float64
+		wantSettled float64
+		wantOutbox  int64
+	}{
+		{status: core.PaymentStatusSuccess, refund: 25.25, wantSettled: 50},
+		{status: core.PaymentStatusFailed, wantSettled: 0},
+		{status: core.PaymentStatusSuccess, wantSettled: 50},
+		{status: core.PaymentStatusPending, wantSettled: 0, wantOutbox: 1},
+	}
+	for _, step := range steps {
+		if _, err := repo.OverrideStatus(payment.ID, step.status, "jane", core.NoteStatusOverride); err != nil {
+			t.Fatalf("override to %s: %v", step.status, err)
+		}
+		if step.refund > 0 {
+			if _, _, err := refunds.CreateRefund(payment.ID, "RF-"+uuid.NewString(), step.refund); err != nil {
+				t.Fatalf("CreateRefund: %v", err)
+			}
+		}
+
+		settled, err := settledTotal(conn.DB, payment.ID)
+		if err != nil {
+			t.Fatalf("settledTotal: %v", err)
+		}
+		if toCents(settled, payment.Currency) != toCents(step.wantSettled, payment.Currency) {
+			t.Errorf("override to %s: settled %v, want %v", step.status, settled, step.wantSettled)
+		}
+		if got := countRows(t, conn, &db.PaymentOutbox{}, "payment_id = ?", payment.ID); got != step.wantOutbox {
+			t.Errorf("override to %s: %d outbox entries, want %d", step.status, got, step.wantOutbox)
+		}
+	}
+	if got := countRows(t, conn, &db.PaymentEvent{}, "payment_id = ? AND actor = ?", payment.ID, "jane"); got != int64(len(steps)) {
+		t.Errorf("got %d override events by jane, want %d", got, len(steps))
+	}
+}
+
+func TestOperatorResetsWriteOutboxEntries(t *testing.T) {
+	tests := []struct {
+		name   string
+		status core.PaymentStatus
+		reset  func(repo output.PaymentRepository, id uuid.UUID) error
+	}{
+		{
+			name:   "approve after review",
+			status: core.PaymentStatusReview,
+			reset: func(repo output.PaymentRepository, id uuid.UUID) error {
+				_, err := repo.ResolveReview(id, core.PaymentStatusPending, core.NoteReviewApproved)
+				return err
+			},
+		},
+		{
+			name:   "reprocess",
+			status: core.PaymentStatusFailed,
+			reset: func(repo output.PaymentRepository, id uuid.UUID) error {
+				reset, err := repo.ResetForReprocess([]uuid.UUID{id}, core.NoteReprocess)
+				if err == nil && len(reset) != 1 {
+					t.Fatalf("ResetForReprocess reset %d payments, want 1", len(reset))
+				}
+				return err
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn := openTestDB(t)
+			repo := NewGormPaymentRepository(conn.DB, nil)
+			payment := newPendingPayment()
+			payment.Status = tt.status
+			if err := repo.Create(payment); err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+			if got := countRows(t, conn, &db.PaymentOutbox{}, "payment_id = ?", payment.ID); got != 0 {
+				t.Fatalf("%s payment has %d outbox entries, want 0", tt.status, got)
+			}
+
+			if err := tt.reset(repo, payment.ID); err != nil {
+				t.Fatalf("%s: %v", tt.name, err)
+			}
+			if got := countRows(t, conn, &db.PaymentOutbox{}, "payment_id = ?", payment.ID); got != 1 {
+				t.Fatalf("got %d outbox entries, want 1", got)
+			}
+
+			// The relay claims it like any unpublished payment, and publishing clears it
+			claimed, err := repo.ClaimUnpublished(time.Now().Add(time.Hour), 0, time.Minute, 10)
+			if err != nil {
+				t.Fatalf("ClaimUnpublished: %v", err)
+			}
+			if len(claimed) != 1 || claimed[0].ID != payment.ID {
+				t.Fatalf("claimed %d payments, want payment %s", len(claimed), payment.ID)
+			}
+			if err := repo.MarkPublished([]uuid.UUID{payment.ID}); err != nil {
+				t.Fatalf("MarkPublished: %v", err)
+			}
+			if got := countRows(t, conn, &db.PaymentOutbox{}, "payment_id = ?", payment.ID); got != 0 {
+				t.Errorf("got %d outbox entries after publishing, want 0", got)
+			}
+		})
+	}
+}
+
+func TestVoidAndCaptureRaceSettleOnce(t *testing.T) {
+	conn := openTestDB(t)
+	repo := NewGormPaymentRepository(conn.DB, nil)
+	payment := newPendingPayment()
+	payment.AuthorizedAmount = payment.Amount
+	if err := repo.Create(payment); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if _, err := repo.ProcessPayment(payment.ID, core.PaymentStatusAuthorized, core.NoteTestAuthorized); err != nil {
+		t.Fatalf("ProcessPayment: %v", err)
+	}
+
+	var (
+		start               = make(chan struct{})
+		wg                  sync.WaitGroup
+		captureErr, voidErr error
+	)
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		<-start
+		_, captureErr = repo.Capture(payment.ID, 0)
+	}()
+	go func() {
+		defer wg.Done()
+		<-start
+		_, voidErr = repo.Void(payment.ID)
+	}()
+	close(start)
+	wg.Wait()
+
+	// The row lock lets exactly one of them settle the authorization; the other sees its outcome
+	var wantStatus core.PaymentStatus
+	var wantEntries int64
+	switch {
+	case captureErr == nil && errors.Is(voidErr, core.ErrPaymentNotVoidable):
+		wantStatus, wantEntries = core.PaymentStatusSuccess, 2
+	case voidErr == nil && errors.Is(captureErr, core.ErrPaymentNotCapturable):
+		wantStatus, wantEntries = core.PaymentStatusCancelled, 0
+	default:
+		t.Fatalf("want exactly one of capture and void to succeed, got capture %v, void %v", captureErr, voidErr)
+	}
+
+	stored, err := repo.GetByID(payment.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if stored.Status != wantStatus {
+		t.Errorf("status: got %s, want %s", stored.Status, wantStatus)
+	}
+	if got := countRows(t, conn, &db.LedgerEntry{}, "payment_id = ?", payment.ID); got != wantEntries {
+		t.Errorf("got %d ledger entries, want %d", got, wantEntries)
+	}
+	if got := countRows(t, conn, &db.PaymentEvent{}, "payment_id = ? AND from_status = ?", payment.ID, db.PaymentStatusAuthorized); got != 1 {
+		t.Errorf("got %d transitions out of AUTHORIZED, want 1", got)
+	}
+}
+
+// stubWebhookFactory builds a delivery to a fixed URL for every notifiable status, or fails with err
+type stubWebhookFactory struct {
+	err error
+}
+
+func (f stubWebhookFactory) NewDelivery(payment *core.Payment) (*core.WebhookDelivery, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	if !payment.IsTerminal() && !payment.IsAuthorized() {
+		return nil, nil
+	}
+	return &core.WebhookDelivery{
+		ID:            uuid.New(),
+		PaymentID:     payment.ID,
+		MerchantID:    payment.MerchantID,
+		EventType:     core.EventTypePaymentSucceeded,
+		URL:           "https://merchant.example/webhooks",
+		Payload:       []byte(`{}`),
+		Status:        core.WebhookDeliveryPending,
+		NextAttemptAt: time.Now(),
+	}, nil
+}
+
+func TestStatusChangesStoreWebhookDeliveries(t *testing.T) {
+	tests := []struct {
+		name           string
+		change         func(t *testing.T, conn *db.DB, repo output.PaymentRepository, id uuid.UUID)
+		wantDeliveries int64
+	}{
+		{
+			name: "processed",
+			change: func(t *testing.T, conn *db.DB, repo output.PaymentRepository, id uuid.UUID) {
+				if _, err := repo.ProcessPayment(id, core.PaymentStatusSuccess, core.NoteGatewayApproved); err != nil {
+					t.Fatalf("ProcessPayment: %v", err)
+				}
+			},
+			wantDeliveries: 1,
+		},
+		{
+			name: "attempts used up",
+			change: func(t *testing.T, conn *db.DB, repo output.PaymentRepository, id uuid.UUID) {
+				if _, err := repo.StartAttempt(id, 0); err != nil {
+					t.Fatalf("StartAttempt: %v", err)
+				}
+			},
+			wantDeliveries: 1,
+		},
+		{
+			name: "expired",
+			change: func(t *testing.T, conn *db.DB, repo output.PaymentRepository, id uuid.UUID) {
+				if err := conn.Exec("UPDATE payments SET expires_at = now() - interval '1 second' WHERE id = ?", id).Error; err != nil {
+					t.Fatalf("failed to expire payment: %v", err)
+				}
+				if expired, err := repo.ExpireDue(10); err != nil || len(expired) != 1 {
+					t.Fatalf("ExpireDue: expired %d, err %v", len(expired), err)
+				}
+			},
+			wantDeliveries: 1,
+		},
+		{
+			// One delivery for the authorization, one for the capture
+			name: "captured",
+			change: func(t *testing.T, conn *db.DB, repo output.PaymentRepository, id uuid.UUID) {
+				if _, err := repo.ProcessPayment(id, core.PaymentStatusAuthorized, core.NoteGatewayAuthorized); err != nil {
+					t.Fatalf("ProcessPayment: %v", err)
+				}
+				if _, err := repo.Capture(id, 0); err != nil {
+					t.Fatalf("Capture: %v", err)
+				}
+			},
+			wantDeliveries: 2,
+		},
+		{
+			name: "voided",
+			change: func(t *testing.T, conn *db.DB, repo output.PaymentRepository, id uuid.UUID) {
+				if _, err := repo.ProcessPayment(id, core.PaymentStatusAuthorized, core.NoteGatewayAuthorized); err != nil {
+					t.Fatalf("ProcessPayment: %v", err)
+				}
+				if _, err := repo.Void(id); err != nil {
+					t.Fatalf("Void: %v", err)
+				}
+			},
+			wantDeliveries: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn := openTestDB(t)
+			repo := NewGormPaymentRepository(conn.DB, stubWebhookFactory{})
+			payment := newPendingPayment()
+			payment.AuthorizedAmount = payment.Amount
+			if err := repo.Create(payment); err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+
+			tt.change(t, conn, repo, payment.ID)
+
+			if got := countRows(t, conn, &db.WebhookDelivery{}, "payment_id = ?", payment.ID); got != tt.wantDeliveries {
+				t.Errorf("got %d webhook deliveries, want %d", got, tt.wantDeliveries)
+			}
+		})
+	}
+}
+
+func TestStatusChangeRollsBackWithoutWebhookDelivery(t *testing.T) {
+	conn := openTestDB(t)
+	repo := NewGormPaymentRepository(conn.DB, stubWebhookFactory{err: errors.New("boom")})
+	payment := newPendingPayment()
+	if err := repo.Create(payment); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	if _, err := repo.ProcessPayment(payment.ID, core.PaymentStatusSuccess, core.NoteGatewayApproved); err == nil {
+		t.Fatal("ProcessPayment: want the webhook error, got nil")
+	}
+
+	// Neither the status nor its ledger entries were committed without the delivery
+	stored, err := repo.GetByID(payment.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if stored.Status != core.PaymentStatusPending {
+		t.Errorf("status: got %s, want PENDING", stored.Status)
+	}
+	if got := countRows(t, conn, &db.LedgerEntry{}, "payment_id = ?", payment.ID); got != 0 {
+		t.Errorf("got %d ledger entries, want 0", got)
+	}
+}
+
+func TestUpdateDeliveryKeepsReplayOverStaleAttempt(t *testing.T) {
+	conn := openTestDB(t)
+	repo := NewGormPaymentRepository(conn.DB, stubWebhookFactory{})
+	webhooks := NewGormWebhookRepository(conn.DB)
+	payment := newPendingPayment()
+	if err := repo.Create(payment); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if _, err := repo.ProcessPayment(payment.ID, core.PaymentStatusSuccess, core.NoteGatewayApproved); err != nil {
+		t.Fatalf("ProcessPayment: %v", err)
+	}
+
+	claimed, err := webhooks.ClaimDue(time.Now().Add(time.Second), time.Minute, 10)
+	if err != nil || len(claimed) != 1 {
+		t.Fatalf("ClaimDue: claimed %d, err %v", len(claimed), err)
+	}
+	attempt := claimed[0]
+
+	// A replay reschedules the delivery while the dispatcher's attempt is still in flight
+	replay, err := webhooks.GetDelivery(attempt.ID)
+	if err != nil {
+		t.Fatalf("GetDelivery: %v", err)
+	}
+	replay.NextAttemptAt = time.Now()
+	if err := webhooks.UpdateDelivery(replay); err != nil {
+		t.Fatalf("replay UpdateDelivery: %v", err)
+	}
+
+	now := time.Now()
+	attempt.Status = core.WebhookDeliveryDelivered
+	attempt.Attempts = 1
+	attempt.DeliveredAt = &now
+	if err := webhooks.UpdateDelivery(attempt); !errors.Is(err, core.ErrDeliveryLeaseLost) {
+		t.Fatalf("stale UpdateDelivery: got %v, want %v", err, core.ErrDeliveryLeaseLost)
+	}
+
+	stored, err := webhooks.GetDelivery(attempt.ID)
+	if err != nil {
+		t.Fatalf("GetDelivery: %v", err)
+	}
+	if stored.Status != core.WebhookDeliveryPending || stored.Attempts != 0 {
+		t.Errorf("got %s after %d attempts, want the replay's PENDING after 0", stored.Status, stored.Attempts)
+	}
+}
@@ -0,0 +1,177 @@
+package database
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cashflow/payment-gateway/internal/constant/model/db"
+	"github.com/cashflow/payment-gateway/internal/core"
+	"github.com/cashflow/payment-gateway/internal/port/output"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GormWebhookRepository is a secondary adapter that implements WebhookRepository output port
+type GormWebhookRepository struct {
+	gormDB *gorm.DB
+}
+
+// NewGormWebhookRepository creates a new GORM webhook repository
+func NewGormWebhookRepository(gormDB *gorm.DB) output.WebhookRepository {
+	return &GormWebhookRepository{gormDB: gormDB}
+}
+
+// webhookToCore converts db.WebhookDelivery to core.WebhookDelivery
+func webhookToCore(d *db.WebhookDelivery) *core.WebhookDelivery {
+	return &core.WebhookDelivery{
+		ID:             d.ID,
+		PaymentID:      d.PaymentID,
+		MerchantID:     d.MerchantID,
+		EventType:      core.EventType(d.EventType),
+		URL:            d.URL,
+		Payload:        d.Payload,
+		Status:         core.WebhookDeliveryStatus(d.Status),
+		Attempts:       d.Attempts,
+		NextAttemptAt:  d.NextAttemptAt,
+		LastError:      d.LastError,
+		LastStatusCode: d.LastStatusCode,
+		DeliveredAt:    d.DeliveredAt,
+		CreatedAt:      d.CreatedAt,
+		UpdatedAt:      d.UpdatedAt,
+	}
+}
+
+// webhookFromCore converts core.WebhookDelivery to db.WebhookDelivery
+func webhookFromCore(d *core.WebhookDelivery) *db.WebhookDelivery {
+	return &db.WebhookDelivery{
+		ID:             d.ID,
+		PaymentID:      d.PaymentID,
+		MerchantID:     d.MerchantID,
+		EventType:      string(d.EventType),
+		URL:            d.URL,
+		Payload:        d.Payload,
+		Status:         db.WebhookDeliveryStatus(d.Status),
+		Attempts:       d.Attempts,
+		NextAttemptAt:  d.NextAttemptAt,
+		LastError:      d.LastError,
+		LastStatusCode: d.LastStatusCode,
+		DeliveredAt:    d.DeliveredAt,
+		CreatedAt:      d.CreatedAt,
+		UpdatedAt:      d.UpdatedAt,
+	}
+}
+
+// CreateDelivery saves a new delivery
+func (r *GormWebhookRepository) CreateDelivery(delivery *core.WebhookDelivery) error {
+	dbDelivery := webhookFromCore(delivery)
+	if err := r.gormDB.Create(dbDelivery).Error; err != nil {
+		return fmt.Errorf("failed to create webhook delivery: %w", err)
+	}
+
+	// Update core entity with generated values
+	delivery.ID = dbDelivery.ID
+	delivery.NextAttemptAt = dbDelivery.NextAttemptAt
+	delivery.CreatedAt = dbDelivery.CreatedAt
+	delivery.UpdatedAt = dbDelivery.UpdatedAt
+	return nil
+}
+
+// GetDelivery retrieves a delivery by ID
+func (r *GormWebhookRepository) GetDelivery(id uuid.UUID) (*core.WebhookDelivery, error) {
+	var dbDelivery db.WebhookDelivery
+	if err := r.gormDB.Where("id = ?", id).First(&dbDelivery).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, core.ErrDeliveryNotFound
+		}
+		return nil, fmt.Errorf("failed to get webhook delivery: %w", err)
+	}
+	return webhookToCore(&dbDelivery), nil
+}
+
+// ListByPaymentID retrieves a payment's deliveries in creation order
+func (r *GormWebhookRepository) ListByPaymentID(paymentID uuid.UUID) ([]*core.WebhookDelivery, error) {
+	var dbDeliveries []db.WebhookDelivery
+	if err := r.gormDB.Where("payment_id = ?", paymentID).
+		Order("created_at ASC").
+		Find(&dbDeliveries).Error; err != nil {
+		return nil, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+
+	deliveries := make([]*core.WebhookDelivery, 0, len(dbDeliveries))
+	for i := range dbDeliveries {
+		deliveries = append(deliveries, webhookToCore(&dbDeliveries[i]))
+	}
+	return deliveries, nil
+}
+
+// ClaimDue returns up to limit due PENDING deliveries and leases them to the caller
+// SKIP LOCKED lets several dispatchers claim disjoint batches without blocking each other
+// The lease is truncated to the column's microsecond precision, so UpdateDelivery can match it exactly
+func (r *GormWebhookRepository) ClaimDue(now time.Time, lease time.Duration, limit int) ([]*core.WebhookDelivery, error) {
+	var dbDeliveries []db.WebhookDelivery
+	leasedUntil := now.Add(lease).Truncate(time.Microsecond)
+
+	err := primary(r.gormDB).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", db.WebhookDeliveryPending, now).
+			Order("next_attempt_at ASC").
+			Limit(limit).
+			Find(&dbDeliveries).Error; err != nil {
+			return fmt.Errorf("failed to claim webhook deliveries: %w", err)
+		}
+		if len(dbDeliveries) == 0 {
+			return nil
+		}
+
+		ids := make([]uuid.UUID, 0, len(dbDeliveries))
+		for _, d := range dbDeliveries {
+			ids = append(ids, d.ID)
+		}
+		if err := tx.Model(&db.WebhookDelivery{}).
+			Where("id IN ?", ids).
+			Updates(map[string]interface{}{"next_attempt_at": leasedUntil, "updated_at": now}).Error; err != nil {
+			return fmt.Errorf("failed to lease webhook deliveries: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	deliveries := make([]*core.WebhookDelivery, 0, len(dbDeliveries))
+	for i := range dbDeliveries {
+		delivery := webhookToCore(&dbDeliveries[i])
+		delivery.NextAttemptAt = leasedUntil
+		delivery.LeasedUntil = leasedUntil
+		deliveries = append(deliveries, delivery)
+	}
+	return deliveries, nil
+}
+
+// UpdateDelivery saves a delivery's status, attempt count and schedule
+// A claimed delivery is only saved while it is still PENDING with the next attempt at its lease:
+// a replay or another dispatcher's claim since then moved it, and must not be overwritten
+func (r *GormWebhookRepository) UpdateDelivery(delivery *core.WebhookDelivery) error {
+	delivery.UpdatedAt = time.Now()
+	query := r.gormDB.Model(&db.WebhookDelivery{}).Where("id = ?", delivery.ID)
+	if !delivery.LeasedUntil.IsZero() {
+		query = query.Where("status = ? AND next_attempt_at = ?", db.WebhookDeliveryPending, delivery.LeasedUntil)
+	}
+	result := query.Updates(map[string]interface{}{
+		"status":           db.WebhookDeliveryStatus(delivery.Status),
+		"attempts":         delivery.Attempts,
+		"next_attempt_at":  delivery.NextAttemptAt,
+		"last_error":       delivery.LastError,
+		"last_status_code": delivery.LastStatusCode,
+		"delivered_at":     delivery.DeliveredAt,
+		"updated_at":       delivery.UpdatedAt,
+	})
+	if result.Error != nil {
+		return fmt.Errorf("failed to update webhook delivery: %w", result.Error)
+	}
+	if !delivery.LeasedUntil.IsZero() && result.RowsAffected == 0 {
+		return core.ErrDeliveryLeaseLost
+	}
+	return nil
+}
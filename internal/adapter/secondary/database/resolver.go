@@ -0,0 +1,13 @@
+package database
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+)
+
+// primary pins a transaction to the primary database when a read replica is configured
+// Transactions already default to the primary; locking transactions use this to make that
+// explicit, since a SELECT FOR UPDATE against a replica would not lock anything
+func primary(gormDB *gorm.DB) *gorm.DB {
+	return gormDB.Clauses(dbresolver.Write)
+}
@@ -0,0 +1,78 @@
+package idempotency
+
+import (
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// memoryEntry is a reserved (paymentID == uuid.Nil) or completed key
+type memoryEntry struct {
+	paymentID uuid.UUID
+	expiresAt time.Time
+}
+
+// MemoryStore is a secondary adapter that implements the IdempotencyStore output port in process memory
+// Keys are not shared between API instances, so it is meant for local development
+type MemoryStore struct {
+	ttl time.Duration
+
+	mu      sync.Mutex
+	entries map[string]memoryEntry
+}
+
+// NewMemoryStore creates a new in-memory idempotency store keeping completed keys for ttl
+func NewMemoryStore(ttl time.Duration) *MemoryStore {
+	return &MemoryStore{
+		ttl:     ttl,
+		entries: make(map[string]memoryEntry),
+	}
+}
+
+// Reserve claims key unless a live entry already holds it
+func (s *MemoryStore) Reserve(key string) (bool, uuid.UUID, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if entry, ok := s.entries[key]; ok && now.Before(entry.expiresAt) {
+		return false, entry.paymentID, nil
+	}
+	s.purgeExpired(now)
+	s.entries[key] = memoryEntry{expiresAt: now.Add(LockTTL)}
+	return true, uuid.Nil, nil
+}
+
+// Complete records the payment created under key
+func (s *MemoryStore) Complete(key string, paymentID uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries[key] = memoryEntry{paymentID: paymentID, expiresAt: time.Now().Add(s.ttl)}
+	return nil
+}
+
+// Release frees key
+func (s *MemoryStore) Release(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, key)
+	return nil
+}
+
+// Close implements Store; there is no connection to release
+func (s *MemoryStore) Close() error {
+	return nil
+}
+
+// purgeExpired drops expired entries so the map doesn't grow without bound
+// The caller must hold s.mu
+func (s *MemoryStore) purgeExpired(now time.Time) {
+	for key, entry := range s.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(s.entries, key)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// KeyPrefix namespaces the store's keys in a shared Redis database
+	KeyPrefix = "payment:idempotency:"
+
+	// RedisTimeout bounds each Redis call
+	RedisTimeout = time.Second
+
+	// reservedValue marks a key whose request is still in flight
+	reservedValue = "reserved"
+)
+
+// RedisStore is a secondary adapter that implements the IdempotencyStore output port on Redis
+// Keys are reserved with SET NX, so concurrent requests with the same key across all
+// API instances race on a single atomic operation
+type RedisStore struct {
+	client *redis.Client
+	ttl    time.Duration
+}
+
+// NewRedisStore connects to the Redis server at redisURL, keeping completed keys for ttl
+func NewRedisStore(redisURL string, ttl time.Duration) (*RedisStore, error) {
+	opts, err := redis.ParseURL(redisURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse Redis URL: %w", err)
+	}
+	client := redis.NewClient(opts)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to connect to Redis: %w", err)
+	}
+	return &RedisStore{client: client, ttl: ttl}, nil
+}
+
+// Reserve claims key with SET NX, returning the stored payment when it is already taken
+func (s *RedisStore) Reserve(key string) (bool, uuid.UUID, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), RedisTimeout)
+	defer cancel()
+
+	reserved, err := s.client.SetNX(ctx, KeyPrefix+key, reservedValue, LockTTL).Result()
+	if err != nil {
+		return false, uuid.Nil, fmt.Errorf("failed to reserve idempotency key: %w", err)
+	}
+	if reserved {
+		return true, uuid.Nil, nil
+	}
+
+	value, err := s.client.Get(ctx, KeyPrefix+key).Result()
+	if errors.Is(err, redis.Nil) {
+		// Released or expired between the two calls; report it as in flight so the client retries
+		return false, uuid.Nil, nil
+	}
+	if err != nil {
+		return false, uuid.Nil, fmt.Errorf("failed to read idempotency key: %w", err)
+	}
+	if value == reservedValue {
+		return false, uuid.Nil, nil
+	}
+
+	paymentID, err := uuid.Parse(value)
+	if err != nil {
+		return false, uuid.Nil, fmt.Errorf("failed to parse idempotency key value %q: %w", value, err)
+	}
+	return false, paymentID, nil
+}
+
+// Complete records the payment created under key, keeping it for the store's TTL
+func (s *RedisStore) Complete(key string, paymentID uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), RedisTimeout)
+	defer cancel()
+
+	if err := s.client.Set(ctx, KeyPrefix+key, paymentID.String(), s.ttl).Err(); err != nil {
+		return fmt.Errorf("failed to complete idempotency key: %w", err)
+	}
+	return nil
+}
+
+// Release frees key
+func (s *RedisStore) Release(key string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), RedisTimeout)
+	defer cancel()
+
+	if err := s.client.Del(ctx, KeyPrefix+key).Err(); err != nil {
+		return fmt.Errorf("failed to release idempotency key: %w", err)
+	}
+	return nil
+}
+
+// Close closes the Redis connection
+func (s *RedisStore) Close() error {
+	return s.client.Close()
+}
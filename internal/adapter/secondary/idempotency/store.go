@@ -0,0 +1,43 @@
+package idempotency
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cashflow/payment-gateway/internal/port/output"
+)
+
+const (
+	BackendMemory = "memory"
+	BackendRedis  = "redis"
+)
+
+// LockTTL bounds how long a reservation blocks retries when its request never completes
+// (e.g. the API crashed mid-request), so the key becomes usable again
+const LockTTL = time.Minute
+
+// Store is implemented by every idempotency store backend
+type Store interface {
+	output.IdempotencyStore
+	// Close releases the backend's connection
+	Close() error
+}
+
+// Config selects and configures the idempotency store backend
+type Config struct {
+	Backend  string // BackendMemory (default) or BackendRedis
+	RedisURL string
+	TTL      time.Duration // How long a completed key replays its payment
+}
+
+// New connects to the configured idempotency store backend
+func New(cfg Config) (Store, error) {
+	switch cfg.Backend {
+	case "", BackendMemory:
+		return NewMemoryStore(cfg.TTL), nil
+	case BackendRedis:
+		return NewRedisStore(cfg.RedisURL, cfg.TTL)
+	default:
+		return nil, fmt.Errorf("unknown idempotency backend %q", cfg.Backend)
+	}
+}
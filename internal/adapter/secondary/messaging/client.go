@@ -0,0 +1,72 @@
+package messaging
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cashflow/payment-gateway/internal/core"
+	"github.com/cashflow/payment-gateway/internal/port/output"
+)
+
+const (
+	BackendRabbitMQ = "rabbitmq"
+	BackendKafka    = "kafka"
+)
+
+// Client is implemented by every messaging backend
+// The API only publishes; the worker also consumes payment.created messages
+type Client interface {
+	output.EventPublisher
+	// ConsumePaymentMessages starts consuming payment.created messages in the background
+	ConsumePaymentMessages(opts ConsumeOptions, handler func(PaymentMessage) error) error
+	// ConsumePaymentBatches is ConsumePaymentMessages for a handler that takes several messages at
+	// once and returns an error for each of them, in order
+	ConsumePaymentBatches(opts ConsumeOptions, handler func([]PaymentMessage) []error) error
+	// StopConsuming stops taking new deliveries; handlers already running finish and acknowledge
+	StopConsuming() error
+}
+
+// Config selects and configures the messaging backend
+type Config struct {
+	Backend            string // BackendRabbitMQ (default) or BackendKafka
+	RabbitMQURL        string
+	KafkaBrokers       []string
+	KafkaTopic         string
+	KafkaConsumerGroup string
+	PublishTimeout     time.Duration // Bounds each Publish; DefaultPublishTimeout when zero
+	Compress           bool          // Gzip large RabbitMQ message bodies; consumers decompress any message
+	CompressMinBytes   int           // Smallest body Compress compresses; DefaultCompressMinBytes when zero
+	Clock              core.Clock    // Stamps published messages; the system clock when nil
+}
+
+// NewClient connects to the configured messaging backend
+func NewClient(cfg Config) (Client, error) {
+	switch cfg.Backend {
+	case "", BackendRabbitMQ:
+		client, err := NewRabbitMQClientConcrete(cfg.RabbitMQURL, cfg.PublishTimeout)
+		if err != nil {
+			return nil, err
+		}
+		if cfg.Clock != nil {
+			client.clock = cfg.Clock
+		}
+		if cfg.Compress {
+			client.compressMin = cfg.CompressMinBytes
+			if client.compressMin <= 0 {
+				client.compressMin = DefaultCompressMinBytes
+			}
+		}
+		return client, nil
+	case BackendKafka:
+		client, err := NewKafkaClient(cfg.KafkaBrokers, cfg.KafkaTopic, cfg.KafkaConsumerGroup, cfg.PublishTimeout)
+		if err != nil {
+			return nil, err
+		}
+		if cfg.Clock != nil {
+			client.clock = cfg.Clock
+		}
+		return client, nil
+	default:
+		return nil, fmt.Errorf("unknown messaging backend %q", cfg.Backend)
+	}
+}
@@ -0,0 +1,51 @@
+package messaging
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+)
+
+const (
+	// ContentEncodingGzip marks a message body that was gzip-compressed
+	ContentEncodingGzip = "gzip"
+
+	// DefaultCompressMinBytes is the smallest body compressed when compression is on and no threshold is set
+	DefaultCompressMinBytes = 1024
+)
+
+// compressBody gzips body
+func compressBody(body []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := writer.Write(body); err != nil {
+		return nil, fmt.Errorf("failed to compress message: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress message: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// decodeBody undoes the content encoding a message was published with
+// Messages without one, such as those published before compression existed, are returned as they are
+func decodeBody(body []byte, contentEncoding string) ([]byte, error) {
+	switch contentEncoding {
+	case "":
+		return body, nil
+	case ContentEncodingGzip:
+		reader, err := gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress message: %w", err)
+		}
+		defer reader.Close()
+		decoded, err := io.ReadAll(reader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress message: %w", err)
+		}
+		return decoded, nil
+	default:
+		return nil, fmt.Errorf("unsupported content encoding %q", contentEncoding)
+	}
+}
@@ -0,0 +1,78 @@
+package messaging
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// inequivalentArgPattern matches the reason RabbitMQ gives when an exchange or queue is
+// redeclared with settings that differ from the existing one, e.g.
+// "PRECONDITION_FAILED - inequivalent arg 'durable' for queue 'payment_processing' in vhost '/': received 'true' but current is 'false'"
+var inequivalentArgPattern = regexp.MustCompile(`inequivalent arg '([^']+)' for \w+ '[^']*' in vhost '[^']*': received (.+) but current is (.+)$`)
+
+// DeclarationConflictError reports an exchange or queue that already exists on the broker
+// with settings this service can't redeclare it with
+type DeclarationConflictError struct {
+	Kind     string // "exchange" or "queue"
+	Name     string
+	Setting  string // The inequivalent setting, e.g. "type", "durable" or "x-message-ttl"; empty when unknown
+	Expected string // The value this service declares
+	Actual   string // The value on the broker
+	Err      error  // The broker's error
+}
+
+// Error names the mismatch and how to resolve it
+func (e *DeclarationConflictError) Error() string {
+	fix := "delete it so it can be redeclared"
+	switch {
+	case e.Kind == "exchange" && e.Setting == "type":
+		fix = `see README "Upgrading from the direct exchange"`
+	case e.Kind == "queue":
+		fix = "drain and delete it so it can be redeclared (deleting a queue discards its messages)"
+	}
+
+	if e.Setting == "" {
+		return fmt.Sprintf("%s %q exists with different settings; %s: %v", e.Kind, e.Name, fix, e.Err)
+	}
+	if e.Setting == "type" {
+		return fmt.Sprintf("%s %q exists with type %s, expected %s; %s", e.Kind, e.Name, e.Actual, e.Expected, fix)
+	}
+	return fmt.Sprintf("%s %q exists with %s=%s, expected %s=%s; %s", e.Kind, e.Name, e.Setting, e.Actual, e.Setting, e.Expected, fix)
+}
+
+// Unwrap returns the broker's error
+func (e *DeclarationConflictError) Unwrap() error {
+	return e.Err
+}
+
+// declarationError converts a failed ExchangeDeclare or QueueDeclare into a
+// *DeclarationConflictError when the broker rejected it as inequivalent to an existing one
+// Other errors are returned unchanged
+func declarationError(kind, name string, err error) error {
+	var amqpErr *amqp.Error
+	if !errors.As(err, &amqpErr) || amqpErr.Code != amqp.PreconditionFailed {
+		return err
+	}
+
+	conflict := &DeclarationConflictError{Kind: kind, Name: name, Err: err}
+	if match := inequivalentArgPattern.FindStringSubmatch(amqpErr.Reason); match != nil {
+		conflict.Setting = match[1]
+		conflict.Expected = argValue(match[2])
+		conflict.Actual = argValue(match[3])
+	}
+	return conflict
+}
+
+// argValue extracts a value from RabbitMQ's description of it: 'true', none, or
+// "the value '60000' of type 'signedint'" for optional arguments
+func argValue(description string) string {
+	description = strings.TrimPrefix(description, "the value ")
+	if i := strings.Index(description, " of type "); i >= 0 {
+		description = description[:i]
+	}
+	return strings.Trim(description, "'")
+}
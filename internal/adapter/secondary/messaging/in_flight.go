@@ -0,0 +1,61 @@
+package messaging
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// InFlight counts the consume handlers that are running, so shutdown can wait for them to finish
+type InFlight struct {
+	wg    sync.WaitGroup
+	count atomic.Int64
+}
+
+// Track wraps handler so every call is counted while it runs
+func (f *InFlight) Track(handler func(PaymentMessage) error) func(PaymentMessage) error {
+	return func(msg PaymentMessage) error {
+		f.wg.Add(1)
+		f.count.Add(1)
+		defer func() {
+			f.count.Add(-1)
+			f.wg.Done()
+		}()
+		return handler(msg)
+	}
+}
+
+// TrackBatch is Track for batch handlers; every message of a batch counts while the batch runs
+func (f *InFlight) TrackBatch(handler func([]PaymentMessage) []error) func([]PaymentMessage) []error {
+	return func(msgs []PaymentMessage) []error {
+		f.wg.Add(1)
+		f.count.Add(int64(len(msgs)))
+		defer func() {
+			f.count.Add(-int64(len(msgs)))
+			f.wg.Done()
+		}()
+		return handler(msgs)
+	}
+}
+
+// Count returns the number of handlers running right now
+func (f *InFlight) Count() int64 {
+	return f.count.Load()
+}
+
+// Wait blocks until no handler is running or timeout passes, reporting whether they all finished
+// Stop the consumers first; handlers that start during the wait extend it
+func (f *InFlight) Wait(timeout time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		f.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
@@ -0,0 +1,254 @@
+package messaging
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/cashflow/payment-gateway/internal/core"
+	"github.com/cashflow/payment-gateway/internal/port/output"
+	"github.com/segmentio/kafka-go"
+)
+
+const (
+	// KafkaRetryBackoff is the initial delay before a failed message is handled, or a failed fetch tried, again
+	KafkaRetryBackoff = 500 * time.Millisecond
+	// KafkaMaxRetryBackoff caps the delay between retries of the same message or fetch
+	KafkaMaxRetryBackoff = 30 * time.Second
+)
+
+// KafkaClient is a secondary adapter that implements EventPublisher output port on Kafka
+// All events go to a single topic keyed by payment ID, so every event for a payment
+// lands on the same partition and is consumed in publish order
+type KafkaClient struct {
+	brokers        []string
+	topic          string
+	groupID        string
+	publishTimeout time.Duration
+	clock          core.Clock // Stamps messages whose event carries no time
+	writer         *kafka.Writer
+
+	ctx       context.Context
+	cancel    context.CancelFunc
+	fetchCtx  context.Context // Cancelled by StopConsuming; ctx stays live for commits and publishes
+	stopFetch context.CancelFunc
+	done      chan struct{}
+}
+
+// NewKafkaClient creates a new Kafka client
+// publishTimeout bounds each Publish, including the writer's internal retries
+func NewKafkaClient(brokers []string, topic, groupID string, publishTimeout time.Duration) (*KafkaClient, error) {
+	if len(brokers) == 0 {
+		return nil, fmt.Errorf("failed to connect to Kafka: no brokers configured")
+	}
+	if publishTimeout <= 0 {
+		publishTimeout = DefaultPublishTimeout
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	fetchCtx, stopFetch := context.WithCancel(ctx)
+	return &KafkaClient{
+		brokers:        brokers,
+		topic:          topic,
+		groupID:        groupID,
+		publishTimeout: publishTimeout,
+		clock:          core.SystemClock{},
+		writer: &kafka.Writer{
+			Addr:                   kafka.TCP(brokers...),
+			Topic:                  topic,
+			Balancer:               &kafka.Hash{}, // Partition by message key (payment ID)
+			RequiredAcks:           kafka.RequireAll,
+			AllowAutoTopicCreation: true,
+			WriteTimeout:           publishTimeout,
+		},
+		ctx:       ctx,
+		cancel:    cancel,
+		fetchCtx:  fetchCtx,
+		stopFetch: stopFetch,
+	}, nil
+}
+
+// Publish publishes a domain event to the topic
+// It returns only after all in-sync replicas have acknowledged the message
+func (c *KafkaClient) Publish(event core.DomainEvent) error {
+	message, err := toKafkaMessage(event, c.clock)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(c.ctx, c.publishTimeout)
+	defer cancel()
+
+	if err := c.writer.WriteMessages(ctx, message); err != nil {
+		// The writer already retries transient errors internally, so what's left is reported as unavailability
+		return fmt.Errorf("failed to publish message: %w: %v", output.ErrMessagingUnavailable, err)
+	}
+
+	log.Printf("Published %s message for payment ID: %s", event.EventType(), event.AggregateID())
+	return nil
+}
+
+// PublishBatch publishes several domain events in a single produce request per partition
+// PUBLISH_TIMEOUT bounds the whole batch
+func (c *KafkaClient) PublishBatch(events []core.DomainEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	messages := make([]kafka.Message, 0, len(events))
+	for _, event := range events {
+		message, err := toKafkaMessage(event, c.clock)
+		if err != nil {
+			return err
+		}
+		messages = append(messages, message)
+	}
+
+	ctx, cancel := context.WithTimeout(c.ctx, c.publishTimeout)
+	defer cancel()
+
+	if err := c.writer.WriteMessages(ctx, messages...); err != nil {
+		return fmt.Errorf("failed to publish batch: %w: %v", output.ErrMessagingUnavailable, err)
+	}
+
+	log.Printf("Published batch of %d messages", len(events))
+	return nil
+}
+
+// toKafkaMessage maps a domain event to a Kafka message keyed by payment ID
+func toKafkaMessage(event core.DomainEvent, clock core.Clock) (kafka.Message, error) {
+	message, err := toPaymentMessage(event, clock)
+	if err != nil {
+		return kafka.Message{}, err
+	}
+
+	body, err := json.Marshal(message)
+	if err != nil {
+		return kafka.Message{}, fmt.Errorf("failed to marshal message: %w", err)
+	}
+
+	return kafka.Message{
+		Key:   []byte(message.PaymentID.String()),
+		Value: body,
+		Time:  message.Timestamp,
+		Headers: []kafka.Header{
+			{Key: "event", Value: []byte(message.Event)},
+		},
+	}, nil
+}
+
+// ConsumePaymentMessages starts consuming payment.created messages as part of the consumer group
+// Offsets are committed only after the handler returns; a retryable error is retried in place
+// with backoff, since Kafka has no per-message requeue and skipping ahead would break ordering
+// opts.WorkerID is sent as the client ID, which shows in the group's member list
+// PrefetchCount and Queues do not apply: partitions, not prefetch, spread load across workers,
+// and the client always consumes its configured topic
+func (c *KafkaClient) ConsumePaymentMessages(opts ConsumeOptions, handler func(PaymentMessage) error) error {
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers: c.brokers,
+		Topic:   c.topic,
+		GroupID: c.groupID,
+		Dialer: &kafka.Dialer{
+			ClientID:  opts.WorkerID,
+			Timeout:   kafka.DefaultDialer.Timeout,
+			DualStack: true,
+		},
+	})
+
+	log.Printf("Started consuming payment messages as %s...", opts.WorkerID)
+
+	c.done = make(chan struct{})
+	go func() {
+		defer close(c.done)
+		defer reader.Close()
+
+		// Fetch errors such as an unreachable broker return at once, so they are retried with
+		// the same backoff as a failing handler rather than in a tight loop
+		fetchBackoff := KafkaRetryBackoff
+		for {
+			msg, err := reader.FetchMessage(c.fetchCtx)
+			if err != nil {
+				if errors.Is(err, context.Canceled) {
+					return
+				}
+				log.Printf("Error fetching message, retrying in %s: %v", fetchBackoff, err)
+				select {
+				case <-c.fetchCtx.Done():
+					return
+				case <-time.After(fetchBackoff):
+				}
+				fetchBackoff = min(fetchBackoff*2, KafkaMaxRetryBackoff)
+				continue
+			}
+			fetchBackoff = KafkaRetryBackoff
+
+			var paymentMsg PaymentMessage
+			if err := json.Unmarshal(msg.Value, &paymentMsg); err != nil {
+				// A malformed message would be redelivered forever, so it is skipped
+				log.Printf("Error unmarshaling message at offset %d: %v", msg.Offset, err)
+			} else if paymentMsg.Event == "" || paymentMsg.Event == core.EventTypePaymentCreated {
+				if !c.handleWithRetry(paymentMsg, handler) {
+					return
+				}
+			}
+
+			if err := reader.CommitMessages(c.ctx, msg); err != nil && !errors.Is(err, context.Canceled) {
+				log.Printf("Error committing offset %d: %v", msg.Offset, err)
+			}
+		}
+	}()
+
+	return nil
+}
+
+// ConsumePaymentBatches hands handler one message at a time, as ConsumePaymentMessages does
+// Messages of a partition are committed in order, so batching them would hold back every offset
+// behind the slowest one; BatchSize and BatchFlushInterval do not apply
+func (c *KafkaClient) ConsumePaymentBatches(opts ConsumeOptions, handler func([]PaymentMessage) []error) error {
+	return c.ConsumePaymentMessages(opts, func(msg PaymentMessage) error {
+		return handler([]PaymentMessage{msg})[0]
+	})
+}
+
+// handleWithRetry runs the handler until it succeeds or fails with a terminal error
+// It returns false if consuming was stopped before the message was handled, leaving its offset
+// uncommitted so the group redelivers it
+func (c *KafkaClient) handleWithRetry(paymentMsg PaymentMessage, handler func(PaymentMessage) error) bool {
+	backoff := KafkaRetryBackoff
+	for {
+		err := handler(paymentMsg)
+		if err == nil {
+			log.Printf("Successfully processed payment: %s", paymentMsg.PaymentID)
+			return true
+		}
+		logHandlerError(paymentMsg, err)
+		if isTerminalError(err) {
+			return true
+		}
+
+		select {
+		case <-c.fetchCtx.Done():
+			return false
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, KafkaMaxRetryBackoff)
+	}
+}
+
+// StopConsuming stops fetching messages; a handler already running finishes and its offset is committed
+func (c *KafkaClient) StopConsuming() error {
+	c.stopFetch()
+	return nil
+}
+
+// Close stops the consumer and closes the Kafka writer
+func (c *KafkaClient) Close() error {
+	c.cancel()
+	if c.done != nil {
+		<-c.done
+	}
+	return c.writer.Close()
+}
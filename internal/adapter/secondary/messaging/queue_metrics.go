@@ -0,0 +1,134 @@
+package messaging
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/cashflow/payment-gateway/internal/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// QueueStats is the subset of the management API's queue object exported as metrics
+type QueueStats struct {
+	Ready     int `json:"messages_ready"`
+	Unacked   int `json:"messages_unacknowledged"`
+	Consumers int `json:"consumers"`
+}
+
+// ManagementClient reads queue statistics from the RabbitMQ management HTTP API
+type ManagementClient struct {
+	baseURL  *url.URL
+	vhost    string
+	username string
+	password string
+	client   *http.Client
+}
+
+// NewManagementClient creates a management API client
+// The vhost, and the credentials unless managementURL carries its own, come from amqpURL
+func NewManagementClient(managementURL, amqpURL string, timeout time.Duration) (*ManagementClient, error) {
+	baseURL, err := url.Parse(managementURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid management URL: %w", err)
+	}
+	uri, err := amqp.ParseURI(amqpURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid RabbitMQ URL: %w", err)
+	}
+
+	client := &ManagementClient{
+		baseURL:  baseURL,
+		vhost:    uri.Vhost,
+		username: uri.Username,
+		password: uri.Password,
+		client:   &http.Client{Timeout: timeout},
+	}
+	if baseURL.User != nil {
+		client.username = baseURL.User.Username()
+		client.password, _ = baseURL.User.Password()
+		baseURL.User = nil
+	}
+	return client, nil
+}
+
+// QueueStats fetches the current statistics of a queue
+func (m *ManagementClient) QueueStats(queue string) (*QueueStats, error) {
+	endpoint := m.baseURL.JoinPath("api", "queues", url.PathEscape(m.vhost), url.PathEscape(queue))
+	req, err := http.NewRequest(http.MethodGet, endpoint.String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build queue stats request: %w", err)
+	}
+	req.SetBasicAuth(m.username, m.password)
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get queue stats: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to get queue stats: management API returned %d", resp.StatusCode)
+	}
+
+	var stats QueueStats
+	if err := json.NewDecoder(resp.Body).Decode(&stats); err != nil {
+		return nil, fmt.Errorf("failed to decode queue stats: %w", err)
+	}
+	return &stats, nil
+}
+
+// QueueCollector exports a queue's depth, fetched from the management API on every scrape
+type QueueCollector struct {
+	client    *ManagementClient
+	queue     string
+	ready     *prometheus.Desc
+	unacked   *prometheus.Desc
+	consumers *prometheus.Desc
+	up        *prometheus.Desc
+}
+
+// NewQueueCollector creates a collector for the given queue
+func NewQueueCollector(client *ManagementClient, queue string) *QueueCollector {
+	labels := prometheus.Labels{"queue": queue}
+	return &QueueCollector{
+		client: client,
+		queue:  queue,
+		ready: prometheus.NewDesc(metrics.Namespace+"_queue_messages_ready",
+			"Messages waiting to be delivered to a worker", nil, labels),
+		unacked: prometheus.NewDesc(metrics.Namespace+"_queue_messages_unacked",
+			"Messages delivered to a worker but not yet acknowledged", nil, labels),
+		consumers: prometheus.NewDesc(metrics.Namespace+"_queue_consumers",
+			"Workers consuming from the queue", nil, labels),
+		up: prometheus.NewDesc(metrics.Namespace+"_queue_stats_up",
+			"Whether the last queue stats scrape from the management API succeeded", nil, labels),
+	}
+}
+
+// Describe implements prometheus.Collector
+func (c *QueueCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.ready
+	ch <- c.unacked
+	ch <- c.consumers
+	ch <- c.up
+}
+
+// Collect implements prometheus.Collector
+// When the management API can't be reached only the up gauge is reported, as 0
+func (c *QueueCollector) Collect(ch chan<- prometheus.Metric) {
+	stats, err := c.client.QueueStats(c.queue)
+	if err != nil {
+		log.Printf("Failed to collect queue metrics for %s: %v", c.queue, err)
+		ch <- prometheus.MustNewConstMetric(c.up, prometheus.GaugeValue, 0)
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.ready, prometheus.GaugeValue, float64(stats.Ready))
+	ch <- prometheus.MustNewConstMetric(c.unacked, prometheus.GaugeValue, float64(stats.Unacked))
+	ch <- prometheus.MustNewConstMetric(c.consumers, prometheus.GaugeValue, float64(stats.Consumers))
+	ch <- prometheus.MustNewConstMetric(c.up, prometheus.GaugeValue, 1)
+}
@@ -1,43 +1,131 @@
 package messaging
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
 	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/cashflow/payment-gateway/internal/core"
 	"github.com/cashflow/payment-gateway/internal/port/output"
 )
 
 const (
-	ExchangeName   = "payments"
-	QueueName      = "payment_processing"
-	RoutingKey     = "payment.created"
-	PrefetchCount  = 1 // Process one message at a time per worker
+	ExchangeName  = "payments"
+	ExchangeType  = "topic"
+	QueueName     = "payment_processing"
+	RoutingKey    = "payment.created"
+	PrefetchCount = 1 // Default: process one message at a time per worker
+
+	// DefaultPublishTimeout bounds a publish when no timeout is configured
+	DefaultPublishTimeout = 5 * time.Second
+
+	// MaxPendingPublishes caps the publishes (single or batch) a RabbitMQ client has outstanding,
+	// counting those that timed out but are still blocked on the broker; past it, publishes fail at once
+	MaxPendingPublishes = 64
+
+	// DefaultBatchSize and DefaultBatchFlushInterval apply when ConsumePaymentBatches is given none
+	DefaultBatchSize          = 10
+	DefaultBatchFlushInterval = 100 * time.Millisecond
+
+	// CurrencyRoutingKeyPattern matches the per-currency routing keys (payment.created.{currency})
+	CurrencyRoutingKeyPattern = RoutingKey + ".*"
 )
 
-// PaymentMessage represents a payment processing message
+// RoutingKeyForEvent returns the routing key a domain event is published with
+// PaymentCreated is routed per currency so it reaches the processing queue; the other
+// events use their event type (payment.authorized, payment.succeeded, payment.failed, payment.refunded)
+func RoutingKeyForEvent(event core.DomainEvent) string {
+	if created, ok := event.(core.PaymentCreated); ok {
+		return RoutingKeyForCurrency(created.Currency)
+	}
+	return string(event.EventType())
+}
+
+// RoutingKeyForCurrency returns the routing key a payment in the given currency is published with
+// Consumers interested in a single currency can bind to it directly
+func RoutingKeyForCurrency(currency core.Currency) string {
+	return RoutingKey + "." + strings.ToLower(string(currency))
+}
+
+// PaymentMessage represents a published domain event
+// Every event carries payment_id and timestamp, so payment.created messages remain
+// readable by workers that predate the event fields
+// Timestamp comes from the producer's clock and is advisory only (logs, tracing);
+// expiry and staleness are decided from the timestamps stored with the payment
 type PaymentMessage struct {
-	PaymentID uuid.UUID `json:"payment_id"`
-	Timestamp time.Time `json:"timestamp"`
+	Event      core.EventType     `json:"event,omitempty"`
+	PaymentID  uuid.UUID          `json:"payment_id"`
+	MerchantID string             `json:"merchant_id,omitempty"`
+	Amount     float64            `json:"amount,omitempty"`
+	Currency   core.Currency      `json:"currency,omitempty"`
+	RefundID   string             `json:"refund_id,omitempty"`
+	Source     core.PaymentSource `json:"source,omitempty"`
+	Timestamp  time.Time          `json:"timestamp"`
+}
+
+// ConsumeOptions configures a consumer
+type ConsumeOptions struct {
+	// PrefetchCount is the maximum number of unacked messages delivered to this consumer
+	// Keep it at 1 for the fairest distribution across competing workers; higher values
+	// trade fairness for throughput since a busy worker can hold messages idle workers could take
+	PrefetchCount int
+
+	// Queues lists the queues to consume, each with its own consumer feeding the same handler
+	// Defaults to QueueName; other queues must already be declared and bound (RabbitMQ only)
+	// Call ConsumePaymentMessages once per handler to give queues different handlers
+	Queues []string
+
+	// WorkerID identifies this worker to the broker: RabbitMQ consumer tags start with it and Kafka
+	// uses it as the client ID, so the management UI and consumer group listings show which worker
+	// holds which consumer. Use the hostname or pod name
+	WorkerID string
+
+	// BatchSize is the most messages ConsumePaymentBatches hands its handler at once (RabbitMQ only)
+	// PrefetchCount must be at least BatchSize, or batches never fill and wait out the flush interval
+	BatchSize int
+
+	// BatchFlushInterval is how long ConsumePaymentBatches waits for a batch to fill before handling
+	// the messages it has; it bounds the latency batching adds when traffic is light
+	BatchFlushInterval time.Duration
 }
 
-// RabbitMQClient is a secondary adapter that implements PaymentMessaging output port
+// RabbitMQClient is a secondary adapter that implements EventPublisher output port
 type RabbitMQClient struct {
+	url            string
+	publishTimeout time.Duration
+	clock          core.Clock    // Stamps messages whose event carries no time
+	compressMin    int           // Bodies of at least this many bytes are gzip-compressed; 0 never compresses
+	pending        chan struct{} // One slot per outstanding publish, see MaxPendingPublishes
+
+	mu      sync.Mutex // Guards conn and channel while they are reopened
 	conn    *amqp.Connection
 	channel *amqp.Channel
+
+	consumeChannel *amqp.Channel // The channel the consumers were registered on
+	consumerTags   []string
+	stopped        atomic.Bool // Set by StopConsuming; later deliveries are requeued unhandled
 }
 
 // NewRabbitMQClient creates a new RabbitMQ client (returns interface for ports)
-func NewRabbitMQClient(amqpURL string) (output.PaymentMessaging, error) {
-	return NewRabbitMQClientConcrete(amqpURL)
+func NewRabbitMQClient(amqpURL string, publishTimeout time.Duration) (output.EventPublisher, error) {
+	return NewRabbitMQClientConcrete(amqpURL, publishTimeout)
 }
 
 // NewRabbitMQClientConcrete creates a new RabbitMQ client (returns concrete type for workers)
-func NewRabbitMQClientConcrete(amqpURL string) (*RabbitMQClient, error) {
+// publishTimeout bounds each Publish, including the wait for the broker's confirm
+func NewRabbitMQClientConcrete(amqpURL string, publishTimeout time.Duration) (*RabbitMQClient, error) {
+	if publishTimeout <= 0 {
+		publishTimeout = DefaultPublishTimeout
+	}
+
 	conn, err := amqp.Dial(amqpURL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to RabbitMQ: %w", err)
@@ -49,10 +137,17 @@ func NewRabbitMQClientConcrete(amqpURL string) (*RabbitMQClient, error) {
 		return nil, fmt.Errorf("failed to open channel: %w", err)
 	}
 
-	// Declare exchange
+	// Enable publisher confirms so publishes are only reported once the broker has the message
+	if err := channel.Confirm(false); err != nil {
+		channel.Close()
+		conn.Close()
+		return nil, fmt.Errorf("failed to enable publisher confirms: %w", err)
+	}
+
+	// Declare exchange (topic, so consumers can bind to currency-specific routing keys)
 	err = channel.ExchangeDeclare(
 		ExchangeName,
-		"direct",
+		ExchangeType,
 		true,  // durable
 		false, // auto-deleted
 		false, // internal
@@ -62,7 +157,8 @@ func NewRabbitMQClientConcrete(amqpURL string) (*RabbitMQClient, error) {
 	if err != nil {
 		channel.Close()
 		conn.Close()
-		return nil, fmt.Errorf("failed to declare exchange: %w", err)
+		// e.g. an exchange left over from the old "direct" setup can't be redeclared with a new type
+		return nil, fmt.Errorf("failed to declare exchange: %w", declarationError("exchange", ExchangeName, err))
 	}
 
 	// Declare queue
@@ -77,34 +173,78 @@ func NewRabbitMQClientConcrete(amqpURL string) (*RabbitMQClient, error) {
 	if err != nil {
 		channel.Close()
 		conn.Close()
-		return nil, fmt.Errorf("failed to declare queue: %w", err)
+		return nil, fmt.Errorf("failed to declare queue: %w", declarationError("queue", QueueName, err))
 	}
 
-	// Bind queue to exchange
-	err = channel.QueueBind(
-		QueueName,
-		RoutingKey,
-		ExchangeName,
-		false,
-		nil,
-	)
-	if err != nil {
-		channel.Close()
-		conn.Close()
-		return nil, fmt.Errorf("failed to bind queue: %w", err)
+	// Bind queue to exchange for every currency, plus the generic key for publishers without a currency suffix
+	for _, bindingKey := range []string{CurrencyRoutingKeyPattern, RoutingKey} {
+		err = channel.QueueBind(
+			QueueName,
+			bindingKey,
+			ExchangeName,
+			false,
+			nil,
+		)
+		if err != nil {
+			channel.Close()
+			conn.Close()
+			return nil, fmt.Errorf("failed to bind queue: %w", err)
+		}
 	}
 
 	return &RabbitMQClient{
-		conn:    conn,
-		channel: channel,
+		url:            amqpURL,
+		publishTimeout: publishTimeout,
+		clock:          core.SystemClock{},
+		pending:        make(chan struct{}, MaxPendingPublishes),
+		conn:           conn,
+		channel:        channel,
 	}, nil
 }
 
-// PublishPaymentMessage publishes a payment processing message
-func (c *RabbitMQClient) PublishPaymentMessage(paymentID uuid.UUID) error {
-	message := PaymentMessage{
-		PaymentID: paymentID,
-		Timestamp: time.Now(),
+// publishChannel returns an open channel for publishing, reopening it (and the connection,
+// if that closed too) when forced or when the current one was closed by the broker
+// reopened reports whether a new channel was opened
+func (c *RabbitMQClient) publishChannel(force bool) (channel *amqp.Channel, reopened bool, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !force && !c.channel.IsClosed() {
+		return c.channel, false, nil
+	}
+
+	if c.conn.IsClosed() {
+		conn, err := amqp.Dial(c.url)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to reconnect to RabbitMQ: %w", err)
+		}
+		c.conn = conn
+	}
+
+	channel, err = c.conn.Channel()
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to reopen channel: %w", err)
+	}
+	if err := channel.Confirm(false); err != nil {
+		channel.Close()
+		return nil, false, fmt.Errorf("failed to enable publisher confirms: %w", err)
+	}
+
+	log.Println("Reopened RabbitMQ channel for publishing")
+	c.channel.Close()
+	c.channel = channel
+	return channel, true, nil
+}
+
+// Publish publishes a domain event to the payments exchange
+// It returns only after the broker has confirmed the message, or with ErrMessagingUnavailable
+// once the publish timeout elapses (e.g. while the broker applies flow control)
+// A timed-out message may still reach the broker afterwards, so consumers must tolerate messages
+// for publishes reported as failed, as they already tolerate duplicates
+func (c *RabbitMQClient) Publish(event core.DomainEvent) error {
+	message, err := toPaymentMessage(event, c.clock)
+	if err != nil {
+		return err
 	}
 
 	body, err := json.Marshal(message)
@@ -112,31 +252,240 @@ func (c *RabbitMQClient) PublishPaymentMessage(paymentID uuid.UUID) error {
 		return fmt.Errorf("failed to marshal message: %w", err)
 	}
 
-	err = c.channel.Publish(
-		ExchangeName,
-		RoutingKey,
-		false, // mandatory
-		false, // immediate
-		amqp.Publishing{
-			ContentType:  "application/json",
-			DeliveryMode: amqp.Persistent, // Make message persistent
-			Body:         body,
-			Timestamp:    time.Now(),
-		},
-	)
+	err = c.runPublish(func(ctx context.Context) error {
+		return c.publish(ctx, event, body)
+	})
 	if err != nil {
 		return fmt.Errorf("failed to publish message: %w", err)
 	}
 
-	log.Printf("Published payment message for payment ID: %s", paymentID)
+	log.Printf("Published %s message for payment ID: %s", message.Event, message.PaymentID)
+	return nil
+}
+
+// PublishBatch publishes several domain events to the payments exchange
+// Every message is sent before waiting for any confirm, so the batch costs one confirm
+// round-trip instead of one per message; PUBLISH_TIMEOUT bounds the whole batch
+func (c *RabbitMQClient) PublishBatch(events []core.DomainEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	bodies := make([][]byte, 0, len(events))
+	for _, event := range events {
+		message, err := toPaymentMessage(event, c.clock)
+		if err != nil {
+			return err
+		}
+		body, err := json.Marshal(message)
+		if err != nil {
+			return fmt.Errorf("failed to marshal message: %w", err)
+		}
+		bodies = append(bodies, body)
+	}
+
+	err := c.runPublish(func(ctx context.Context) error {
+		return c.publishBatch(ctx, events, bodies)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to publish batch: %w", err)
+	}
+
+	log.Printf("Published batch of %d messages", len(events))
+	return nil
+}
+
+// runPublish runs send in its own goroutine, with a context that expires after the publish timeout
+// A blocked socket write can't be interrupted, so a send that outlives the timeout finishes (or
+// fails) in the background once the broker unblocks, holding its pending slot until then; with
+// MaxPendingPublishes outstanding, publishes fail at once instead of piling up more goroutines
+func (c *RabbitMQClient) runPublish(send func(ctx context.Context) error) error {
+	select {
+	case c.pending <- struct{}{}:
+	default:
+		return fmt.Errorf("%w: %d publishes still waiting on the broker", output.ErrMessagingUnavailable, MaxPendingPublishes)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.publishTimeout)
+	result := make(chan error, 1)
+	go func() {
+		defer func() { <-c.pending }()
+		defer cancel()
+		result <- send(ctx)
+	}()
+
+	select {
+	case err := <-result:
+		return err
+	case <-ctx.Done():
+		return fmt.Errorf("%w: no confirm within %s", output.ErrMessagingUnavailable, c.publishTimeout)
+	}
+}
+
+// publishBatch sends all messages pipelined on the publishing channel, then waits for every confirm
+func (c *RabbitMQClient) publishBatch(ctx context.Context, events []core.DomainEvent, bodies [][]byte) error {
+	channel, _, err := c.publishChannel(false)
+	if err != nil {
+		return fmt.Errorf("%w: %v", output.ErrMessagingUnavailable, err)
+	}
+
+	confirmations := make([]*amqp.DeferredConfirmation, 0, len(events))
+	for i, event := range events {
+		confirmation, err := channel.PublishWithDeferredConfirmWithContext(ctx, ExchangeName, RoutingKeyForEvent(event), false, false, c.newPublishing(bodies[i], c.clock.Now()))
+		if err != nil {
+			if errors.Is(err, amqp.ErrClosed) {
+				return fmt.Errorf("%w: %v", output.ErrMessagingUnavailable, err)
+			}
+			return err
+		}
+		confirmations = append(confirmations, confirmation)
+	}
+
+	for _, confirmation := range confirmations {
+		if err := waitConfirm(ctx, channel, confirmation); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
-// ConsumePaymentMessages starts consuming payment messages
-func (c *RabbitMQClient) ConsumePaymentMessages(handler func(PaymentMessage) error) error {
-	// Set QoS to process one message at a time
+// waitConfirm waits for the broker to confirm a message, until ctx expires
+func waitConfirm(ctx context.Context, channel *amqp.Channel, confirmation *amqp.DeferredConfirmation) error {
+	acked, err := confirmation.WaitContext(ctx)
+	if err != nil {
+		return fmt.Errorf("%w: %v", output.ErrMessagingUnavailable, err)
+	}
+	if !acked {
+		// The channel closing while we wait also ends the wait without a confirm
+		if channel.IsClosed() {
+			return fmt.Errorf("%w: channel closed before the broker confirmed", output.ErrMessagingUnavailable)
+		}
+		return errors.New("broker did not confirm delivery")
+	}
+	return nil
+}
+
+// newPublishing wraps a message body as a persistent JSON publishing sent at timestamp
+// A body of at least compressMin bytes is gzip-compressed and marked with its Content-Encoding;
+// should compressing fail, it is sent as it is
+func (c *RabbitMQClient) newPublishing(body []byte, timestamp time.Time) amqp.Publishing {
+	publishing := amqp.Publishing{
+		ContentType:  "application/json",
+		DeliveryMode: amqp.Persistent, // Make message persistent
+		Body:         body,
+		Timestamp:    timestamp,
+	}
+	if c.compressMin > 0 && len(body) >= c.compressMin {
+		compressed, err := compressBody(body)
+		if err != nil {
+			log.Printf("Publishing message uncompressed: %v", err)
+			return publishing
+		}
+		publishing.Body = compressed
+		publishing.ContentEncoding = ContentEncodingGzip
+	}
+	return publishing
+}
+
+// publish sends one message and waits for the broker's confirm, until ctx expires
+func (c *RabbitMQClient) publish(ctx context.Context, event core.DomainEvent, body []byte) error {
+	publishing := c.newPublishing(body, c.clock.Now())
+
+	// A channel closed by a broker blip is reopened once before giving up
+	channel, reopened, err := c.publishChannel(false)
+	if err != nil {
+		return fmt.Errorf("%w: %v", output.ErrMessagingUnavailable, err)
+	}
+	confirmation, err := channel.PublishWithDeferredConfirmWithContext(ctx, ExchangeName, RoutingKeyForEvent(event), false, false, publishing)
+	if errors.Is(err, amqp.ErrClosed) && !reopened {
+		if channel, _, err = c.publishChannel(true); err != nil {
+			return fmt.Errorf("%w: %v", output.ErrMessagingUnavailable, err)
+		}
+		confirmation, err = channel.PublishWithDeferredConfirmWithContext(ctx, ExchangeName, RoutingKeyForEvent(event), false, false, publishing)
+	}
+	if err != nil {
+		if errors.Is(err, amqp.ErrClosed) {
+			return fmt.Errorf("%w: %v", output.ErrMessagingUnavailable, err)
+		}
+		return err
+	}
+	return waitConfirm(ctx, channel, confirmation)
+}
+
+// toPaymentMessage maps a domain event to its wire format
+// Events without an occurrence time are stamped with clock's current time
+func toPaymentMessage(event core.DomainEvent, clock core.Clock) (PaymentMessage, error) {
+	message := PaymentMessage{
+		Event:     event.EventType(),
+		PaymentID: event.AggregateID(),
+	}
+
+	switch e := event.(type) {
+	case core.PaymentCreated:
+		message.MerchantID, message.Amount, message.Currency, message.Timestamp = e.MerchantID, e.Amount, e.Currency, e.OccurredAt
+		message.Source = e.Source
+	case core.PaymentAuthorized:
+		message.MerchantID, message.Amount, message.Currency, message.Timestamp = e.MerchantID, e.Amount, e.Currency, e.OccurredAt
+	case core.PaymentSucceeded:
+		message.MerchantID, message.Amount, message.Currency, message.Timestamp = e.MerchantID, e.Amount, e.Currency, e.OccurredAt
+	case core.PaymentFailed:
+		message.MerchantID, message.Amount, message.Currency, message.Timestamp = e.MerchantID, e.Amount, e.Currency, e.OccurredAt
+	case core.PaymentExpired:
+		message.MerchantID, message.Amount, message.Currency, message.Timestamp = e.MerchantID, e.Amount, e.Currency, e.OccurredAt
+	case core.PaymentCancelled:
+		message.MerchantID, message.Amount, message.Currency, message.Timestamp = e.MerchantID, e.Amount, e.Currency, e.OccurredAt
+	case core.PaymentRefunded:
+		message.MerchantID, message.Amount, message.Currency, message.Timestamp = e.MerchantID, e.Amount, e.Currency, e.OccurredAt
+		message.RefundID = e.RefundID
+	default:
+		return PaymentMessage{}, fmt.Errorf("failed to publish message: unsupported event type %q", event.EventType())
+	}
+
+	if message.Timestamp.IsZero() {
+		message.Timestamp = clock.Now()
+	}
+	return message, nil
+}
+
+// ConsumePaymentMessages starts consuming payment messages from each of opts.Queues
+// Messages are acked only after the handler returns, so with a per-consumer prefetch
+// RabbitMQ only delivers to workers that have capacity, spreading load fairly across N workers
+func (c *RabbitMQClient) ConsumePaymentMessages(opts ConsumeOptions, handler func(PaymentMessage) error) error {
+	return c.consume(opts, func(msgs <-chan amqp.Delivery) {
+		consumeDeliveries(msgs, handler, &c.stopped)
+	})
+}
+
+// ConsumePaymentBatches is ConsumePaymentMessages for handlers that process messages together
+// Each consumer collects up to opts.BatchSize deliveries, or as many as arrive within
+// opts.BatchFlushInterval of the first, and passes them to handler, which returns one error per
+// message; each delivery is then acked or requeued on its own error as ConsumePaymentMessages would
+func (c *RabbitMQClient) ConsumePaymentBatches(opts ConsumeOptions, handler func([]PaymentMessage) []error) error {
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = DefaultBatchSize
+	}
+	if opts.BatchFlushInterval <= 0 {
+		opts.BatchFlushInterval = DefaultBatchFlushInterval
+	}
+	return c.consume(opts, func(msgs <-chan amqp.Delivery) {
+		consumeBatches(msgs, opts.BatchSize, opts.BatchFlushInterval, handler, &c.stopped)
+	})
+}
+
+// consume sets the prefetch limit and registers a consumer on each of opts.Queues, running run
+// on each consumer's deliveries in the background
+func (c *RabbitMQClient) consume(opts ConsumeOptions, run func(<-chan amqp.Delivery)) error {
+	if opts.PrefetchCount <= 0 {
+		opts.PrefetchCount = PrefetchCount
+	}
+	if len(opts.Queues) == 0 {
+		opts.Queues = []string{QueueName}
+	}
+
+	// Limit unacked messages per consumer (global=false applies the limit to each consumer
+	// on the channel rather than sharing it across consumers)
 	err := c.channel.Qos(
-		PrefetchCount,
+		opts.PrefetchCount,
 		0,     // prefetch size
 		false, // global
 	)
@@ -144,54 +493,181 @@ func (c *RabbitMQClient) ConsumePaymentMessages(handler func(PaymentMessage) err
 		return fmt.Errorf("failed to set QoS: %w", err)
 	}
 
-	msgs, err := c.channel.Consume(
-		QueueName,
-		"",    // consumer tag
-		false, // auto-ack (we'll manually ack after processing)
-		false, // exclusive
-		false, // no-local
-		false, // no-wait
-		nil,
-	)
-	if err != nil {
-		return fmt.Errorf("failed to register consumer: %w", err)
+	// One consumer per queue; the prefetch limit applies to each of them separately
+	for _, queue := range opts.Queues {
+		tag := consumerTag(opts.WorkerID, queue)
+		msgs, err := c.channel.Consume(
+			queue,
+			tag,   // consumer tag, needed to cancel the consumer on shutdown
+			false, // auto-ack (we'll manually ack after processing)
+			false, // exclusive
+			false, // no-local
+			false, // no-wait
+			nil,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to register consumer for queue %s: %w", queue, err)
+		}
+
+		c.mu.Lock()
+		c.consumeChannel = c.channel
+		c.consumerTags = append(c.consumerTags, tag)
+		c.mu.Unlock()
+
+		log.Printf("Started consuming payment messages from %s as %s...", queue, tag)
+		go run(msgs)
 	}
 
-	log.Println("Started consuming payment messages...")
+	return nil
+}
 
-	go func() {
-		for msg := range msgs {
-			var paymentMsg PaymentMessage
-			if err := json.Unmarshal(msg.Body, &paymentMsg); err != nil {
-				log.Printf("Error unmarshaling message: %v", err)
+// consumerTag names a consumer of queue after the worker; the random suffix keeps tags unique
+// when two workers share an ID, and is all that names the consumer when workerID is empty
+func consumerTag(workerID, queue string) string {
+	suffix := uuid.NewString()[:8]
+	if workerID == "" {
+		return queue + "-" + suffix
+	}
+	return workerID + "-" + queue + "-" + suffix
+}
+
+// consumeDeliveries runs the handler for each delivery until the channel closes,
+// acking successes and terminal failures and requeueing everything else
+// Once stopped is set, deliveries still buffered from the prefetch are requeued without running the handler
+func consumeDeliveries(msgs <-chan amqp.Delivery, handler func(PaymentMessage) error, stopped *atomic.Bool) {
+	for msg := range msgs {
+		if stopped.Load() {
+			msg.Nack(false, true)
+			continue
+		}
+
+		paymentMsg, err := decodeDelivery(msg)
+		if err != nil {
+			log.Printf("Error decoding message: %v", err)
+			msg.Nack(false, true) // Requeue message
+			continue
+		}
+
+		// Process the message
+		settleDelivery(msg, paymentMsg, handler(paymentMsg))
+	}
+}
+
+// consumeBatches is consumeDeliveries for batch handlers: it hands the handler up to size
+// deliveries at a time, or fewer once flushInterval has passed since the first of them arrived
+func consumeBatches(msgs <-chan amqp.Delivery, size int, flushInterval time.Duration, handler func([]PaymentMessage) []error, stopped *atomic.Bool) {
+	deliveries := make([]amqp.Delivery, 0, size)
+	batch := make([]PaymentMessage, 0, size)
+	flush := func() {
+		if len(deliveries) == 0 {
+			return
+		}
+		if stopped.Load() {
+			for _, msg := range deliveries {
+				msg.Nack(false, true)
+			}
+		} else {
+			errs := handler(batch)
+			for i, msg := range deliveries {
+				settleDelivery(msg, batch[i], errs[i])
+			}
+		}
+		deliveries, batch = deliveries[:0], batch[:0]
+	}
+
+	timer := time.NewTimer(flushInterval)
+	timer.Stop()
+	for {
+		select {
+		case msg, ok := <-msgs:
+			if !ok {
+				flush()
+				return
+			}
+			if stopped.Load() {
+				msg.Nack(false, true)
+				continue
+			}
+
+			paymentMsg, err := decodeDelivery(msg)
+			if err != nil {
+				log.Printf("Error decoding message: %v", err)
 				msg.Nack(false, true) // Requeue message
 				continue
 			}
 
-			// Process the message
-			if err := handler(paymentMsg); err != nil {
-				log.Printf("Error processing payment %s: %v", paymentMsg.PaymentID, err)
-				// Check if message should be requeued
-				// If it's a terminal state error (already processed), don't requeue
-				if isTerminalError(err) {
-					msg.Ack(false) // Acknowledge to remove from queue
-				} else {
-					msg.Nack(false, true) // Requeue for retry
+			deliveries = append(deliveries, msg)
+			batch = append(batch, paymentMsg)
+			if len(deliveries) == 1 {
+				timer.Reset(flushInterval)
+			}
+			if len(deliveries) == size {
+				if !timer.Stop() {
+					<-timer.C // Drain the tick so it doesn't flush the next batch early
 				}
-				continue
+				flush()
 			}
+		case <-timer.C:
+			flush()
+		}
+	}
+}
 
-			// Successfully processed
-			msg.Ack(false)
-			log.Printf("Successfully processed payment: %s", paymentMsg.PaymentID)
+// decodeDelivery unmarshals a delivery's payment message, decompressing it first if it was compressed
+func decodeDelivery(msg amqp.Delivery) (PaymentMessage, error) {
+	var paymentMsg PaymentMessage
+	body, err := decodeBody(msg.Body, msg.ContentEncoding)
+	if err != nil {
+		return paymentMsg, err
+	}
+	if err := json.Unmarshal(body, &paymentMsg); err != nil {
+		return paymentMsg, fmt.Errorf("failed to unmarshal message: %w", err)
+	}
+	return paymentMsg, nil
+}
+
+// settleDelivery acks a delivery whose handler succeeded or failed terminally, and requeues it otherwise
+func settleDelivery(msg amqp.Delivery, paymentMsg PaymentMessage, err error) {
+	if err != nil {
+		logHandlerError(paymentMsg, err)
+		// Check if message should be requeued
+		// If it's a terminal state error (already processed), don't requeue
+		if isTerminalError(err) {
+			msg.Ack(false) // Acknowledge to remove from queue
+		} else {
+			msg.Nack(false, true) // Requeue for retry
 		}
-	}()
+		return
+	}
 
-	return nil
+	// Successfully processed
+	msg.Ack(false)
+	log.Printf("Successfully processed payment: %s", paymentMsg.PaymentID)
+}
+
+// StopConsuming cancels every consumer so the broker stops delivering to this worker
+// Handlers already running finish and ack; deliveries prefetched but not yet handled are requeued
+func (c *RabbitMQClient) StopConsuming() error {
+	c.stopped.Store(true)
+
+	c.mu.Lock()
+	channel, tags := c.consumeChannel, c.consumerTags
+	c.mu.Unlock()
+
+	var errs []error
+	for _, tag := range tags {
+		if err := channel.Cancel(tag, false); err != nil {
+			errs = append(errs, fmt.Errorf("failed to cancel consumer %s: %w", tag, err))
+		}
+	}
+	return errors.Join(errs...)
 }
 
 // Close closes the RabbitMQ connection
 func (c *RabbitMQClient) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	if c.channel != nil {
 		c.channel.Close()
 	}
@@ -201,13 +677,21 @@ func (c *RabbitMQClient) Close() error {
 	return nil
 }
 
+// logHandlerError logs a failed handler call; a payment that was already processed is reported
+// as the benign redelivery it is rather than as an error
+func logHandlerError(paymentMsg PaymentMessage, err error) {
+	if errors.Is(err, core.ErrPaymentAlreadyProcessed) {
+		log.Printf("Payment %s was already processed, acknowledging duplicate delivery", paymentMsg.PaymentID)
+		return
+	}
+	log.Printf("Error processing payment %s: %v", paymentMsg.PaymentID, err)
+}
+
 // isTerminalError checks if an error indicates a terminal state
 // (e.g., payment already processed)
 func isTerminalError(err error) bool {
 	if err == nil {
 		return false
 	}
-	errStr := err.Error()
-	return strings.Contains(errStr, "already processed") || strings.Contains(errStr, "payment not found")
+	return errors.Is(err, core.ErrPaymentAlreadyProcessed) || errors.Is(err, core.ErrPaymentNotFound)
 }
-
@@ -0,0 +1,58 @@
+package objectstore
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/cashflow/payment-gateway/internal/port/output"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+)
+
+// S3PartSize is the multipart upload part size used when an object's length isn't known
+// upfront; one part is buffered at a time, so it bounds the memory an upload uses
+// S3 requires parts of at least 5 MiB and allows 10,000 of them, so objects up to ~156 GiB fit
+const S3PartSize = 16 << 20
+
+// S3Config holds the settings of an S3-compatible object store
+type S3Config struct {
+	Endpoint  string // host[:port], e.g. s3.amazonaws.com or minio:9000
+	Bucket    string
+	Region    string // Empty lets the client discover the bucket's region
+	AccessKey string
+	SecretKey string
+	UseSSL    bool
+}
+
+// S3Store is a secondary adapter that implements ObjectStore output port on S3-compatible storage
+type S3Store struct {
+	client *minio.Client
+	bucket string
+}
+
+// NewS3Store creates an object store writing to cfg.Bucket
+// No request is made until the first Put, so a missing bucket only shows up then
+func NewS3Store(cfg S3Config) (output.ObjectStore, error) {
+	client, err := minio.New(cfg.Endpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(cfg.AccessKey, cfg.SecretKey, ""),
+		Secure: cfg.UseSSL,
+		Region: cfg.Region,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create S3 client: %w", err)
+	}
+	return &S3Store{client: client, bucket: cfg.Bucket}, nil
+}
+
+// Put streams body to key as a multipart upload of S3PartSize parts
+func (s *S3Store) Put(key string, body io.Reader, contentType string) error {
+	_, err := s.client.PutObject(context.Background(), s.bucket, key, body, -1, minio.PutObjectOptions{
+		ContentType: contentType,
+		PartSize:    S3PartSize,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to upload %s to bucket %s: %w", key, s.bucket, err)
+	}
+	return nil
+}
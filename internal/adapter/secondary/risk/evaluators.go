@@ -0,0 +1,49 @@
+package risk
+
+import (
+	"github.com/cashflow/payment-gateway/internal/core"
+	"github.com/cashflow/payment-gateway/internal/port/output"
+)
+
+// AlwaysApprove is a secondary adapter that implements the RiskEvaluator output port by approving
+// every payment; it is the default, so payments flow as before until rules are configured
+type AlwaysApprove struct{}
+
+// Evaluate approves the payment
+func (AlwaysApprove) Evaluate(payment *core.Payment) (core.RiskDecision, error) {
+	return core.Approved(), nil
+}
+
+// Chain runs several evaluators and keeps the strictest decision, so rules compose without
+// knowing about each other
+// Evaluation stops at the first decline or error
+type Chain []output.RiskEvaluator
+
+// NewChain combines evaluators; with none it approves every payment
+func NewChain(evaluators ...output.RiskEvaluator) output.RiskEvaluator {
+	if len(evaluators) == 0 {
+		return AlwaysApprove{}
+	}
+	if len(evaluators) == 1 {
+		return evaluators[0]
+	}
+	return Chain(evaluators)
+}
+
+// Evaluate runs the evaluators in order and returns the strictest decision
+func (c Chain) Evaluate(payment *core.Payment) (core.RiskDecision, error) {
+	result := core.Approved()
+	for _, evaluator := range c {
+		decision, err := evaluator.Evaluate(payment)
+		if err != nil {
+			return core.RiskDecision{}, err
+		}
+		if decision.Outcome.Severity() > result.Outcome.Severity() {
+			result = decision
+		}
+		if result.Outcome == core.RiskDecline {
+			break
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,78 @@
+package risk
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cashflow/payment-gateway/internal/core"
+	"github.com/cashflow/payment-gateway/internal/port/output"
+)
+
+// VelocityLimit configures the velocity rule
+type VelocityLimit struct {
+	MaxPayments  int           // Payments allowed per merchant and key within Window; 0 disables the rule
+	Window       time.Duration // How far back payments are counted
+	PrefixLength int           // Leading reference characters grouping payments together; 0 groups by customer only
+}
+
+// VelocityEvaluator is a secondary adapter that implements the RiskEvaluator output port by declining
+// a payment when the merchant already created MaxPayments payments within Window with the same
+// reference prefix, or for the same customer
+// Counts cover every payment created in the window whatever its status, since a burst of
+// declined attempts is what the rule is meant to stop
+type VelocityEvaluator struct {
+	paymentRepo output.PaymentRepository
+	limit       VelocityLimit
+	clock       core.Clock
+}
+
+// NewVelocityEvaluator creates the velocity rule, counting payments through paymentRepo
+func NewVelocityEvaluator(paymentRepo output.PaymentRepository, limit VelocityLimit, clock core.Clock) *VelocityEvaluator {
+	return &VelocityEvaluator{
+		paymentRepo: paymentRepo,
+		limit:       limit,
+		clock:       clock,
+	}
+}
+
+// Evaluate declines the payment with core.ErrVelocityLimitExceeded once a limit is reached
+func (e *VelocityEvaluator) Evaluate(payment *core.Payment) (core.RiskDecision, error) {
+	if e.limit.MaxPayments <= 0 {
+		return core.Approved(), nil
+	}
+	since := e.clock.Now().Add(-e.limit.Window)
+
+	if e.limit.PrefixLength > 0 {
+		prefix := payment.Reference
+		if len(prefix) > e.limit.PrefixLength {
+			prefix = prefix[:e.limit.PrefixLength]
+		}
+		decision, err := e.check(output.PaymentFilter{MerchantID: payment.MerchantID, RefPrefix: prefix, CreatedAfter: since},
+			fmt.Sprintf("reference prefix %q", prefix))
+		if err != nil || decision.Outcome == core.RiskDecline {
+			return decision, err
+		}
+	}
+
+	if payment.CustomerID != "" {
+		return e.check(output.PaymentFilter{MerchantID: payment.MerchantID, CustomerID: payment.CustomerID, CreatedAfter: since},
+			"this customer")
+	}
+	return core.Approved(), nil
+}
+
+// check counts the payments matching filter and declines once there are MaxPayments of them
+func (e *VelocityEvaluator) check(filter output.PaymentFilter, subject string) (core.RiskDecision, error) {
+	count, err := e.paymentRepo.Count(filter)
+	if err != nil {
+		return core.RiskDecision{}, fmt.Errorf("failed to count recent payments: %w", err)
+	}
+	if count < int64(e.limit.MaxPayments) {
+		return core.Approved(), nil
+	}
+	return core.RiskDecision{
+		Outcome: core.RiskDecline,
+		Reason: fmt.Errorf("%w: at most %d payments for %s per %s",
+			core.ErrVelocityLimitExceeded, e.limit.MaxPayments, subject, e.limit.Window),
+	}, nil
+}
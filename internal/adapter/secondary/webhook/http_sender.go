@@ -0,0 +1,43 @@
+package webhook
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/cashflow/payment-gateway/internal/port/output"
+)
+
+// HTTPSender is a secondary adapter that implements WebhookSender output port over HTTP
+type HTTPSender struct {
+	client *http.Client
+}
+
+// NewHTTPSender creates a new HTTP webhook sender with the given per-request timeout
+func NewHTTPSender(timeout time.Duration) output.WebhookSender {
+	return &HTTPSender{
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// Send posts the JSON payload to url and returns the response status code
+func (s *HTTPSender) Send(url string, payload []byte) (int, error) {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return 0, fmt.Errorf("failed to build webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "cashflow-payment-gateway/webhooks")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to send webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Drain (a bounded amount of) the body so the connection can be reused
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	return resp.StatusCode, nil
+}
@@ -1,10 +1,14 @@
 package db
 
 import (
+	"fmt"
+	"net/url"
 	"time"
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+	"gorm.io/plugin/dbresolver"
 )
 
 // DB wraps the GORM database connection
@@ -12,9 +16,29 @@ type DB struct {
 	*gorm.DB
 }
 
+// PoolConfig holds the connection pool settings
+type PoolConfig struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+}
+
+// DefaultSchema is the Postgres schema tables live in unless DB_SCHEMA names another
+const DefaultSchema = "public"
+
 // NewDB creates a new GORM database connection
-func NewDB(connectionString string) (*DB, error) {
-	db, err := gorm.Open(postgres.Open(connectionString), &gorm.Config{})
+// When replicaURL is set, plain reads go to the replica while writes, transactions and
+// locking reads (SELECT FOR UPDATE) stay on the primary; otherwise everything uses the primary
+// Every connection's search_path is set to schema, so the unqualified table names of the models,
+// their indexes and the migrations all resolve inside it; a schema other than public is created
+// if it doesn't exist. schema must be a lowercase identifier, as config validates DB_SCHEMA
+// queryLogger receives GORM's query logs; nil keeps GORM's default logger
+func NewDB(connectionString, replicaURL, schema string, pool PoolConfig, queryLogger logger.Interface) (*DB, error) {
+	connectionString, err := withSearchPath(connectionString, schema)
+	if err != nil {
+		return nil, err
+	}
+	db, err := gorm.Open(postgres.Open(connectionString), &gorm.Config{Logger: queryLogger})
 	if err != nil {
 		return nil, err
 	}
@@ -26,23 +50,62 @@ func NewDB(connectionString string) (*DB, error) {
 	}
 
 	// Set connection pool settings
-	sqlDB.SetMaxOpenConns(25)
-	sqlDB.SetMaxIdleConns(5)
-	sqlDB.SetConnMaxLifetime(5 * time.Minute)
+	sqlDB.SetMaxOpenConns(pool.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(pool.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(pool.ConnMaxLifetime)
 
 	// Test connection
 	if err := sqlDB.Ping(); err != nil {
 		return nil, err
 	}
 
+	// The search_path names the schema but does not create it
+	if schema != "" && schema != DefaultSchema {
+		if err := db.Exec("CREATE SCHEMA IF NOT EXISTS " + schema).Error; err != nil {
+			return nil, fmt.Errorf("failed to create schema %s: %w", schema, err)
+		}
+	}
+
 	// Auto-migrate the schema
-	if err := db.AutoMigrate(&Payment{}); err != nil {
+	if err := db.AutoMigrate(&Payment{}, &LedgerEntry{}, &PaymentEvent{}, &Refund{}, &WebhookDelivery{}, &Export{}, &PaymentOutbox{}); err != nil {
 		return nil, err
 	}
 
+	// Register the replica only after migrating, so the migrator's schema checks hit the primary
+	if replicaURL != "" {
+		if replicaURL, err = withSearchPath(replicaURL, schema); err != nil {
+			return nil, err
+		}
+		resolver := dbresolver.Register(dbresolver.Config{
+			Replicas: []gorm.Dialector{postgres.Open(replicaURL)},
+		}).
+			SetMaxOpenConns(pool.MaxOpenConns).
+			SetMaxIdleConns(pool.MaxIdleConns).
+			SetConnMaxLifetime(pool.ConnMaxLifetime)
+		if err := db.Use(resolver); err != nil {
+			return nil, err
+		}
+	}
+
 	return &DB{DB: db}, nil
 }
 
+// withSearchPath returns the postgres:// connection URL with its search_path runtime parameter set to
+// schema; an empty schema leaves the URL unchanged
+func withSearchPath(connectionURL, schema string) (string, error) {
+	if schema == "" {
+		return connectionURL, nil
+	}
+	parsed, err := url.Parse(connectionURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid database URL: %w", err)
+	}
+	query := parsed.Query()
+	query.Set("search_path", schema)
+	parsed.RawQuery = query.Encode()
+	return parsed.String(), nil
+}
+
 // Close closes the database connection
 func (db *DB) Close() error {
 	sqlDB, err := db.DB.DB()
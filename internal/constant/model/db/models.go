@@ -11,9 +11,32 @@ import (
 type PaymentStatus string
 
 const (
-	PaymentStatusPending PaymentStatus = "PENDING"
-	PaymentStatusSuccess PaymentStatus = "SUCCESS"
-	PaymentStatusFailed  PaymentStatus = "FAILED"
+	PaymentStatusReview     PaymentStatus = "REVIEW"
+	PaymentStatusPending    PaymentStatus = "PENDING"
+	PaymentStatusAuthorized PaymentStatus = "AUTHORIZED"
+	PaymentStatusSuccess    PaymentStatus = "SUCCESS"
+	PaymentStatusFailed     PaymentStatus = "FAILED"
+	PaymentStatusExpired    PaymentStatus = "EXPIRED"
+	PaymentStatusCancelled  PaymentStatus = "CANCELLED"
+)
+
+// PaymentSource represents the code path that created a payment
+type PaymentSource string
+
+const (
+	PaymentSourceAPI    PaymentSource = "api"
+	PaymentSourceImport PaymentSource = "import"
+	PaymentSourceReplay PaymentSource = "replay"
+)
+
+// PaymentMethod represents how a payment was paid
+type PaymentMethod string
+
+const (
+	PaymentMethodUnknown      PaymentMethod = "unknown"
+	PaymentMethodCard         PaymentMethod = "card"
+	PaymentMethodMobileMoney  PaymentMethod = "mobile_money"
+	PaymentMethodBankTransfer PaymentMethod = "bank_transfer"
 )
 
 // Currency represents supported currencies
@@ -26,13 +49,31 @@ const (
 
 // Payment represents a payment entity in the database
 type Payment struct {
-	ID        uuid.UUID     `gorm:"type:uuid;primary_key" json:"id"`
-	Amount    float64       `gorm:"type:decimal(15,2);not null" json:"amount"`
-	Currency  Currency      `gorm:"type:varchar(3);not null" json:"currency"`
-	Reference string        `gorm:"type:varchar(255);not null;uniqueIndex" json:"reference"`
-	Status    PaymentStatus `gorm:"type:varchar(20);not null" json:"status"`
-	CreatedAt time.Time     `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`
-	UpdatedAt time.Time     `gorm:"not null;default:CURRENT_TIMESTAMP" json:"updated_at"`
+	ID               uuid.UUID      `gorm:"type:uuid;primary_key" json:"id"`
+	MerchantID       string         `gorm:"type:varchar(64);not null;default:'';index:idx_payments_merchant_created_at,priority:1;index:idx_payments_merchant_customer,priority:1" json:"merchant_id"`
+	Amount           float64        `gorm:"type:decimal(15,2);not null" json:"amount"`
+	Currency         Currency       `gorm:"type:varchar(3);not null" json:"currency"`
+	Reference        string         `gorm:"type:varchar(255);not null;uniqueIndex;index:idx_payments_reference_pattern,expression:reference varchar_pattern_ops" json:"reference"`
+	Description      string         `gorm:"type:varchar(500);not null;default:''" json:"description"`
+	CustomerID       string         `gorm:"type:varchar(64);not null;default:'';index:idx_payments_merchant_customer,priority:2,where:customer_id <> ''" json:"customer_id"`
+	CustomerEmail    string         `gorm:"type:varchar(254);not null;default:''" json:"customer_email"`
+	Status           PaymentStatus  `gorm:"type:varchar(20);not null" json:"status"`
+	Source           PaymentSource  `gorm:"type:varchar(10);not null;default:'api'" json:"source"`
+	Method           PaymentMethod  `gorm:"type:varchar(20);not null;default:'unknown'" json:"method"`
+	IsTest           bool           `gorm:"not null;default:false" json:"is_test"`
+	AuthOnly         bool           `gorm:"not null;default:false" json:"auth_only"`
+	AuthorizedAmount float64        `gorm:"type:decimal(15,2);not null;default:0" json:"authorized_amount"`
+	Tags             Tags           `gorm:"type:jsonb;not null;default:'[]';index:idx_payments_tags,type:gin" json:"tags"`
+	ExpiresAt        *time.Time     `gorm:"index:idx_payments_pending_expires_at,where:status = 'PENDING' AND expires_at IS NOT NULL AND deleted_at IS NULL" json:"expires_at,omitempty"`
+	Attempts         int            `gorm:"not null;default:0" json:"attempts"`
+	ParentPaymentID  *uuid.UUID     `gorm:"type:uuid;index:idx_payments_parent_payment_id,where:parent_payment_id IS NOT NULL" json:"parent_payment_id,omitempty"`
+	CreatedAt        time.Time      `gorm:"not null;default:CURRENT_TIMESTAMP;index:idx_payments_merchant_created_at,priority:2;index:idx_payments_pending_created_at,where:status = 'PENDING' AND deleted_at IS NULL" json:"created_at"`
+	UpdatedAt        time.Time      `gorm:"not null;default:CURRENT_TIMESTAMP" json:"updated_at"`
+	DeletedAt        gorm.DeletedAt `gorm:"index" json:"-"`
+
+	// Associations, only loaded on request via Preload
+	Events        []PaymentEvent `gorm:"foreignKey:PaymentID" json:"events,omitempty"`
+	LedgerEntries []LedgerEntry  `gorm:"foreignKey:PaymentID" json:"ledger_entries,omitempty"`
 }
 
 // TableName specifies the table name for GORM
@@ -41,9 +82,14 @@ func (Payment) TableName() string {
 }
 
 // BeforeCreate is a GORM hook that runs before creating a record
+// Payments created without an ID get a time-ordered (version 7) one, like the service assigns
 func (p *Payment) BeforeCreate(tx *gorm.DB) error {
 	if p.ID == uuid.Nil {
-		p.ID = uuid.New()
+		id, err := uuid.NewV7()
+		if err != nil {
+			return err
+		}
+		p.ID = id
 	}
 	now := time.Now()
 	if p.CreatedAt.IsZero() {
@@ -68,5 +114,203 @@ func (p *Payment) IsPending() bool {
 
 // IsTerminal checks if payment is in a terminal state
 func (p *Payment) IsTerminal() bool {
-	return p.Status == PaymentStatusSuccess || p.Status == PaymentStatusFailed
+	return p.Status == PaymentStatusSuccess || p.Status == PaymentStatusFailed ||
+		p.Status == PaymentStatusExpired || p.Status == PaymentStatusCancelled
+}
+
+// LedgerDirection represents the side of a ledger entry
+type LedgerDirection string
+
+const (
+	LedgerDirectionDebit  LedgerDirection = "DEBIT"
+	LedgerDirectionCredit LedgerDirection = "CREDIT"
+)
+
+// LedgerEntry represents a ledger entry in the database
+type LedgerEntry struct {
+	ID        uuid.UUID       `gorm:"type:uuid;primary_key" json:"id"`
+	PaymentID uuid.UUID       `gorm:"type:uuid;not null;index" json:"payment_id"`
+	Account   string          `gorm:"type:varchar(50);not null" json:"account"`
+	Direction LedgerDirection `gorm:"type:varchar(6);not null" json:"direction"`
+	Amount    float64         `gorm:"type:decimal(15,2);not null" json:"amount"`
+	Currency  Currency        `gorm:"type:varchar(3);not null" json:"currency"`
+	CreatedAt time.Time       `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+// TableName specifies the table name for GORM
+func (LedgerEntry) TableName() string {
+	return "ledger_entries"
+}
+
+// BeforeCreate is a GORM hook that runs before creating a record
+func (e *LedgerEntry) BeforeCreate(tx *gorm.DB) error {
+	if e.ID == uuid.Nil {
+		e.ID = uuid.New()
+	}
+	if e.CreatedAt.IsZero() {
+		e.CreatedAt = time.Now()
+	}
+	return nil
+}
+
+// PaymentEvent represents a payment status transition in the database
+type PaymentEvent struct {
+	ID         uuid.UUID     `gorm:"type:uuid;primary_key" json:"id"`
+	PaymentID  uuid.UUID     `gorm:"type:uuid;not null;index" json:"payment_id"`
+	FromStatus PaymentStatus `gorm:"type:varchar(20);not null;default:''" json:"from_status"`
+	ToStatus   PaymentStatus `gorm:"type:varchar(20);not null" json:"to_status"`
+	Note       string        `gorm:"type:varchar(255);not null;default:''" json:"note,omitempty"`
+	Actor      string        `gorm:"type:varchar(64);not null;default:''" json:"actor,omitempty"`
+	CreatedAt  time.Time     `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+// TableName specifies the table name for GORM
+func (PaymentEvent) TableName() string {
+	return "payment_events"
+}
+
+// BeforeCreate is a GORM hook that runs before creating a record
+func (e *PaymentEvent) BeforeCreate(tx *gorm.DB) error {
+	if e.ID == uuid.Nil {
+		e.ID = uuid.New()
+	}
+	if e.CreatedAt.IsZero() {
+		e.CreatedAt = time.Now()
+	}
+	return nil
+}
+
+// RefundStatus represents the status of a refund
+type RefundStatus string
+
+const (
+	RefundStatusSuccess RefundStatus = "SUCCESS"
+	RefundStatusFailed  RefundStatus = "FAILED"
+)
+
+// Refund represents a refund in the database
+type Refund struct {
+	ID        uuid.UUID    `gorm:"type:uuid;primary_key" json:"id"`
+	PaymentID uuid.UUID    `gorm:"type:uuid;not null;uniqueIndex:idx_refunds_payment_refund_id,priority:1" json:"payment_id"`
+	RefundID  string       `gorm:"type:varchar(64);not null;uniqueIndex:idx_refunds_payment_refund_id,priority:2" json:"refund_id"`
+	Amount    float64      `gorm:"type:decimal(15,2);not null" json:"amount"`
+	Currency  Currency     `gorm:"type:varchar(3);not null" json:"currency"`
+	Status    RefundStatus `gorm:"type:varchar(20);not null" json:"status"`
+	CreatedAt time.Time    `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+// TableName specifies the table name for GORM
+func (Refund) TableName() string {
+	return "refunds"
+}
+
+// BeforeCreate is a GORM hook that runs before creating a record
+func (r *Refund) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	if r.CreatedAt.IsZero() {
+		r.CreatedAt = time.Now()
+	}
+	return nil
+}
+
+// WebhookDeliveryStatus represents the status of a webhook delivery
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "PENDING"
+	WebhookDeliveryDelivered WebhookDeliveryStatus = "DELIVERED"
+	WebhookDeliveryFailed    WebhookDeliveryStatus = "FAILED"
+)
+
+// WebhookDelivery represents a webhook delivery in the database
+type WebhookDelivery struct {
+	ID             uuid.UUID             `gorm:"type:uuid;primary_key" json:"id"`
+	PaymentID      uuid.UUID             `gorm:"type:uuid;not null;index" json:"payment_id"`
+	MerchantID     string                `gorm:"type:varchar(64);not null;default:''" json:"merchant_id"`
+	EventType      string                `gorm:"type:varchar(50);not null" json:"event_type"`
+	URL            string                `gorm:"type:text;not null" json:"url"`
+	Payload        []byte                `gorm:"type:jsonb;not null" json:"payload"`
+	Status         WebhookDeliveryStatus `gorm:"type:varchar(20);not null;index:idx_webhook_deliveries_status_next_attempt_at,priority:1" json:"status"`
+	Attempts       int                   `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt  time.Time             `gorm:"not null;default:CURRENT_TIMESTAMP;index:idx_webhook_deliveries_status_next_attempt_at,priority:2" json:"next_attempt_at"`
+	LastError      string                `gorm:"type:text;not null;default:''" json:"last_error"`
+	LastStatusCode int                   `gorm:"not null;default:0" json:"last_status_code"`
+	DeliveredAt    *time.Time            `json:"delivered_at"`
+	CreatedAt      time.Time             `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`
+	UpdatedAt      time.Time             `gorm:"not null;default:CURRENT_TIMESTAMP" json:"updated_at"`
+}
+
+// TableName specifies the table name for GORM
+func (WebhookDelivery) TableName() string {
+	return "webhook_deliveries"
+}
+
+// BeforeCreate is a GORM hook that runs before creating a record
+func (d *WebhookDelivery) BeforeCreate(tx *gorm.DB) error {
+	if d.ID == uuid.Nil {
+		d.ID = uuid.New()
+	}
+	now := time.Now()
+	if d.CreatedAt.IsZero() {
+		d.CreatedAt = now
+	}
+	if d.NextAttemptAt.IsZero() {
+		d.NextAttemptAt = now
+	}
+	d.UpdatedAt = now
+	return nil
+}
+
+// PaymentOutbox marks a PENDING payment whose payment.created message the broker has not confirmed
+// It is written with the payment and deleted once the message is confirmed; the worker's outbox
+// relay republishes the payments whose entries are left behind
+type PaymentOutbox struct {
+	PaymentID     uuid.UUID `gorm:"type:uuid;primary_key" json:"payment_id"`
+	Attempts      int       `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt time.Time `gorm:"not null;default:CURRENT_TIMESTAMP;index" json:"next_attempt_at"`
+	CreatedAt     time.Time `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`
+}
+
+// TableName specifies the table name for GORM
+func (PaymentOutbox) TableName() string {
+	return "payment_outbox"
+}
+
+// ExportStatus represents the status of a payments export
+type ExportStatus string
+
+const (
+	ExportStatusRunning   ExportStatus = "RUNNING"
+	ExportStatusSucceeded ExportStatus = "SUCCEEDED"
+	ExportStatusFailed    ExportStatus = "FAILED"
+)
+
+// Export represents a bulk payments export in the database
+type Export struct {
+	ID          uuid.UUID    `gorm:"type:uuid;primary_key" json:"id"`
+	Day         time.Time    `gorm:"type:date;not null;index" json:"day"`
+	Status      ExportStatus `gorm:"type:varchar(20);not null" json:"status"`
+	ObjectKey   string       `gorm:"type:text;not null" json:"object_key"`
+	RowCount    int64        `gorm:"not null;default:0" json:"row_count"`
+	Error       string       `gorm:"type:text;not null;default:''" json:"error"`
+	CreatedAt   time.Time    `gorm:"not null;default:CURRENT_TIMESTAMP" json:"created_at"`
+	CompletedAt *time.Time   `json:"completed_at"`
+}
+
+// TableName specifies the table name for GORM
+func (Export) TableName() string {
+	return "exports"
+}
+
+// BeforeCreate is a GORM hook that runs before creating a record
+func (e *Export) BeforeCreate(tx *gorm.DB) error {
+	if e.ID == uuid.Nil {
+		e.ID = uuid.New()
+	}
+	if e.CreatedAt.IsZero() {
+		e.CreatedAt = time.Now()
+	}
+	return nil
 }
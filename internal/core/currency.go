@@ -0,0 +1,101 @@
+package core
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// CurrencyInfo describes a supported currency and the payment amounts it accepts
+type CurrencyInfo struct {
+	Code           Currency
+	Decimals       int     // Decimal places amounts are kept to
+	MinAmount      float64 // Smallest payment amount, one minor unit
+	MaxAmount      float64 // Largest payment amount
+	MinProcessable float64 // Smallest amount the payment rails settle; 0 when MinAmount is enough
+}
+
+// maxStoredAmount is the largest amount the decimal(15,2) amount column can hold
+const maxStoredAmount = 9999999999999.99
+
+// SupportedCurrencies is the currency registry, in the order clients should list them
+var SupportedCurrencies = []CurrencyInfo{
+	{Code: CurrencyETB, Decimals: 2, MinAmount: 0.01, MaxAmount: maxStoredAmount},
+	{Code: CurrencyUSD, Decimals: 2, MinAmount: 0.01, MaxAmount: maxStoredAmount},
+}
+
+// LookupCurrency returns the registry entry for a currency, or false if it is not supported
+func LookupCurrency(code Currency) (CurrencyInfo, bool) {
+	for _, info := range SupportedCurrencies {
+		if info.Code == code {
+			return info, true
+		}
+	}
+	return CurrencyInfo{}, false
+}
+
+// Decimals returns the number of decimal places amounts in the currency are kept to
+// Unsupported currencies use two, matching the decimal(15,2) column type
+func (c Currency) Decimals() int {
+	if info, ok := LookupCurrency(c); ok {
+		return info.Decimals
+	}
+	return 2
+}
+
+// RoundAmount rounds amount to the currency's decimal places
+func RoundAmount(amount float64, currency Currency) float64 {
+	scale := math.Pow10(currency.Decimals())
+	return math.Round(amount*scale) / scale
+}
+
+// RoundingMode decides what happens to an amount with more decimal places than its currency keeps
+type RoundingMode string
+
+const (
+	RoundingReject   RoundingMode = "reject"   // The amount is invalid
+	RoundingHalfUp   RoundingMode = "half_up"  // Round to the nearest minor unit, halves away from zero (10.005 is 10.01)
+	RoundingTruncate RoundingMode = "truncate" // Drop the extra digits (10.009 is 10.00)
+)
+
+// IsValid checks if the mode is one of the known modes
+func (m RoundingMode) IsValid() bool {
+	switch m {
+	case RoundingReject, RoundingHalfUp, RoundingTruncate:
+		return true
+	}
+	return false
+}
+
+// Apply returns amount at the currency's decimal places
+// Amounts that already fit are returned unchanged; otherwise false is returned under RoundingReject
+// The digits are those of the shortest decimal that parses to amount, so 10.005 is handled as
+// written rather than as its binary approximation 10.00499999...
+func (m RoundingMode) Apply(amount float64, currency Currency) (float64, bool) {
+	decimals := currency.Decimals()
+	digits := strconv.FormatFloat(amount, 'f', -1, 64)
+	whole, fraction, _ := strings.Cut(digits, ".")
+	if len(fraction) <= decimals {
+		return amount, true
+	}
+
+	switch m {
+	case RoundingHalfUp, RoundingTruncate:
+	default:
+		return amount, false
+	}
+
+	truncated, err := strconv.ParseFloat(whole+"."+fraction[:decimals], 64)
+	if err != nil {
+		return amount, false
+	}
+	if m == RoundingHalfUp && fraction[decimals] >= '5' {
+		truncated += math.Copysign(math.Pow10(-decimals), amount)
+	}
+	return RoundAmount(truncated, currency), true
+}
+
+// FormatAmount formats amount with exactly the currency's decimal places (10.5 USD is "10.50")
+func FormatAmount(amount float64, currency Currency) string {
+	return strconv.FormatFloat(RoundAmount(amount, currency), 'f', currency.Decimals(), 64)
+}
@@ -0,0 +1,172 @@
+package core
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EventType identifies a domain event
+type EventType string
+
+const (
+	EventTypePaymentCreated    EventType = "payment.created"
+	EventTypePaymentAuthorized EventType = "payment.authorized"
+	EventTypePaymentSucceeded  EventType = "payment.succeeded"
+	EventTypePaymentFailed     EventType = "payment.failed"
+	EventTypePaymentExpired    EventType = "payment.expired"
+	EventTypePaymentCancelled  EventType = "payment.cancelled"
+	EventTypePaymentRefunded   EventType = "payment.refunded"
+)
+
+// DomainEvent is a business event published through the EventPublisher output port
+type DomainEvent interface {
+	EventType() EventType
+	AggregateID() uuid.UUID
+}
+
+// PaymentCreated is emitted once a payment has been persisted and awaits processing
+type PaymentCreated struct {
+	PaymentID  uuid.UUID
+	MerchantID string
+	Amount     float64
+	Currency   Currency
+	IsTest     bool
+	Source     PaymentSource
+	OccurredAt time.Time
+}
+
+// PaymentAuthorized is emitted when processing moves an auth-only payment to AUTHORIZED
+type PaymentAuthorized struct {
+	PaymentID  uuid.UUID
+	MerchantID string
+	Amount     float64
+	Currency   Currency
+	OccurredAt time.Time
+}
+
+// PaymentSucceeded is emitted when processing or a capture moves a payment to SUCCESS
+type PaymentSucceeded struct {
+	PaymentID  uuid.UUID
+	MerchantID string
+	Amount     float64
+	Currency   Currency
+	OccurredAt time.Time
+}
+
+// PaymentFailed is emitted when processing moves a payment to FAILED
+type PaymentFailed struct {
+	PaymentID  uuid.UUID
+	MerchantID string
+	Amount     float64
+	Currency   Currency
+	OccurredAt time.Time
+}
+
+// PaymentExpired is emitted when a PENDING payment passes its expires_at
+type PaymentExpired struct {
+	PaymentID  uuid.UUID
+	MerchantID string
+	Amount     float64
+	Currency   Currency
+	OccurredAt time.Time
+}
+
+// PaymentCancelled is emitted when an AUTHORIZED payment is voided without capture
+type PaymentCancelled struct {
+	PaymentID  uuid.UUID
+	MerchantID string
+	Amount     float64
+	Currency   Currency
+	OccurredAt time.Time
+}
+
+// PaymentRefunded is emitted for every new (non-replayed) refund
+// Amount is the refunded amount, not the payment amount
+type PaymentRefunded struct {
+	PaymentID  uuid.UUID
+	MerchantID string
+	RefundID   string
+	Amount     float64
+	Currency   Currency
+	OccurredAt time.Time
+}
+
+func (e PaymentCreated) EventType() EventType   { return EventTypePaymentCreated }
+func (e PaymentCreated) AggregateID() uuid.UUID { return e.PaymentID }
+
+func (e PaymentAuthorized) EventType() EventType   { return EventTypePaymentAuthorized }
+func (e PaymentAuthorized) AggregateID() uuid.UUID { return e.PaymentID }
+
+func (e PaymentSucceeded) EventType() EventType   { return EventTypePaymentSucceeded }
+func (e PaymentSucceeded) AggregateID() uuid.UUID { return e.PaymentID }
+
+func (e PaymentFailed) EventType() EventType   { return EventTypePaymentFailed }
+func (e PaymentFailed) AggregateID() uuid.UUID { return e.PaymentID }
+
+func (e PaymentExpired) EventType() EventType   { return EventTypePaymentExpired }
+func (e PaymentExpired) AggregateID() uuid.UUID { return e.PaymentID }
+
+func (e PaymentCancelled) EventType() EventType   { return EventTypePaymentCancelled }
+func (e PaymentCancelled) AggregateID() uuid.UUID { return e.PaymentID }
+
+func (e PaymentRefunded) EventType() EventType   { return EventTypePaymentRefunded }
+func (e PaymentRefunded) AggregateID() uuid.UUID { return e.PaymentID }
+
+// PaymentCreatedEvent returns the event that queues a PENDING payment for processing
+func PaymentCreatedEvent(payment *Payment, at time.Time) PaymentCreated {
+	return PaymentCreated{
+		PaymentID:  payment.ID,
+		MerchantID: payment.MerchantID,
+		Amount:     payment.Amount,
+		Currency:   payment.Currency,
+		IsTest:     payment.IsTest,
+		Source:     payment.Source,
+		OccurredAt: at,
+	}
+}
+
+// PaymentProcessedEvent returns the event for a payment that reached a terminal status or was authorized
+func PaymentProcessedEvent(payment *Payment, status PaymentStatus, at time.Time) DomainEvent {
+	switch status {
+	case PaymentStatusAuthorized:
+		return PaymentAuthorized{
+			PaymentID:  payment.ID,
+			MerchantID: payment.MerchantID,
+			Amount:     payment.Amount,
+			Currency:   payment.Currency,
+			OccurredAt: at,
+		}
+	case PaymentStatusSuccess:
+		return PaymentSucceeded{
+			PaymentID:  payment.ID,
+			MerchantID: payment.MerchantID,
+			Amount:     payment.Amount,
+			Currency:   payment.Currency,
+			OccurredAt: at,
+		}
+	case PaymentStatusExpired:
+		return PaymentExpired{
+			PaymentID:  payment.ID,
+			MerchantID: payment.MerchantID,
+			Amount:     payment.Amount,
+			Currency:   payment.Currency,
+			OccurredAt: at,
+		}
+	case PaymentStatusCancelled:
+		return PaymentCancelled{
+			PaymentID:  payment.ID,
+			MerchantID: payment.MerchantID,
+			Amount:     payment.Amount,
+			Currency:   payment.Currency,
+			OccurredAt: at,
+		}
+	}
+	return PaymentFailed{
+		PaymentID:  payment.ID,
+		MerchantID: payment.MerchantID,
+		Amount:     payment.Amount,
+		Currency:   payment.Currency,
+		OccurredAt: at,
+	}
+}
@@ -0,0 +1,69 @@
+package core
+
+import "errors"
+
+// Sentinel errors returned by services and repositories
+// Wrap them with %w to add context; callers match them with errors.Is
+var (
+	// Payments
+	ErrPaymentNotFound         = errors.New("payment not found")
+	ErrPaymentAlreadyProcessed = errors.New("payment already processed")
+	ErrPaymentNotProcessed     = errors.New("payment has not been processed yet")
+	ErrReferenceExists         = errors.New("reference already exists")
+	ErrLimitExceeded           = errors.New("daily amount limit exceeded")
+	ErrInvalidTransition       = errors.New("invalid payment status transition")
+	ErrPaymentStatusUnchanged  = errors.New("payment already has this status")
+	ErrPaymentNotEnqueued      = errors.New("payment could not be queued for processing")
+
+	// Captures and voids
+	ErrPaymentNotCapturable        = errors.New("payment is not authorized for capture")
+	ErrCaptureExceedsAuthorization = errors.New("capture amount exceeds authorized amount")
+	ErrPaymentNotVoidable          = errors.New("payment is not authorized, so there is nothing to void")
+
+	// Risk
+	ErrPaymentDeclined       = errors.New("payment declined by risk rules")
+	ErrPaymentNotInReview    = errors.New("payment is not held for review")
+	ErrVelocityLimitExceeded = errors.New("too many payments in a short time")
+
+	// Idempotency keys
+	ErrInvalidIdempotencyKey = errors.New("invalid Idempotency-Key")
+	ErrIdempotencyKeyInUse   = errors.New("a request with this Idempotency-Key is still in progress")
+	ErrIdempotencyKeyReused  = errors.New("Idempotency-Key was already used for a different payment")
+
+	// Request fields
+	ErrInvalidAmount        = errors.New("invalid amount")
+	ErrInvalidCurrency      = errors.New("invalid currency")
+	ErrAmountNotProcessable = errors.New("amount is below the smallest amount the currency's rails process")
+	ErrInvalidReference     = errors.New("invalid reference")
+	ErrInvalidRefundID      = errors.New("invalid refund_id")
+	ErrInvalidExpiry        = errors.New("invalid expiry")
+	ErrInvalidTags          = errors.New("invalid tags")
+	ErrInvalidDescription   = errors.New("invalid description")
+	ErrInvalidCustomerID    = errors.New("invalid customer_id")
+	ErrInvalidCustomerEmail = errors.New("invalid customer_email")
+	ErrInvalidSource        = errors.New("invalid source")
+	ErrInvalidMethod        = errors.New("invalid method")
+	ErrInvalidParameter     = errors.New("invalid parameter")
+
+	// Installments
+	ErrInvalidParentPayment    = errors.New("invalid parent_payment_id")
+	ErrInstallmentsExceedTotal = errors.New("installments exceed the parent payment's amount")
+
+	// Refunds
+	ErrPaymentNotRefundable = errors.New("payment is not refundable")
+	ErrRefundExceedsBalance = errors.New("refund amount exceeds refundable balance")
+	ErrRefundIDConflict     = errors.New("refund_id already used with a different amount")
+
+	// Webhooks
+	ErrDeliveryNotFound        = errors.New("webhook delivery not found")
+	ErrDeliveryPending         = errors.New("webhook delivery is already pending")
+	ErrDeliveryLeaseLost       = errors.New("webhook delivery changed since it was claimed")
+	ErrWebhookURLNotConfigured = errors.New("no webhook URL configured for merchant")
+
+	// Exports
+	ErrExportNotFound       = errors.New("export not found")
+	ErrExportsNotConfigured = errors.New("no object store configured for exports")
+
+	// Maintenance
+	ErrReadOnly = errors.New("payment gateway is in read-only mode, writes are temporarily disabled")
+)
@@ -0,0 +1,28 @@
+package core
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ExportStatus represents the status of a payments export
+type ExportStatus string
+
+const (
+	ExportStatusRunning   ExportStatus = "RUNNING"   // Payments are being streamed to the object store
+	ExportStatusSucceeded ExportStatus = "SUCCEEDED" // The object was written in full
+	ExportStatusFailed    ExportStatus = "FAILED"    // Reading payments or the upload failed; Error says why
+)
+
+// Export is a bulk export of the payments created on one UTC day to the object store
+type Export struct {
+	ID          uuid.UUID
+	Day         time.Time // Midnight UTC of the exported day
+	Status      ExportStatus
+	ObjectKey   string
+	RowCount    int64
+	Error       string
+	CreatedAt   time.Time
+	CompletedAt *time.Time
+}
@@ -0,0 +1,50 @@
+package core
+
+import "math"
+
+// PaymentGroup is a parent payment and the child payments (installments) collected toward its amount
+// The parent's amount is the group's total; children share its merchant and currency and can't
+// have children of their own
+type PaymentGroup struct {
+	Parent   *Payment
+	Children []*Payment // Oldest first
+}
+
+// CountsTowardParent checks if an installment takes up part of its parent's amount
+// FAILED, EXPIRED and CANCELLED children never collect anything, so they free their share again
+func (p *Payment) CountsTowardParent() bool {
+	return p.Status != PaymentStatusFailed && p.Status != PaymentStatusExpired && p.Status != PaymentStatusCancelled
+}
+
+// IsInstallment checks if the payment was created as a child of another payment
+func (p *Payment) IsInstallment() bool {
+	return p.ParentPaymentID != nil
+}
+
+// PaidAmount returns what the SUCCESS children have collected so far
+func (g *PaymentGroup) PaidAmount() float64 {
+	var paid float64
+	for _, child := range g.Children {
+		if child.Status == PaymentStatusSuccess {
+			paid += child.Amount
+		}
+	}
+	return RoundAmount(paid, g.Parent.Currency)
+}
+
+// PendingAmount returns what the children still in progress (REVIEW, PENDING or AUTHORIZED) may collect
+func (g *PaymentGroup) PendingAmount() float64 {
+	var pending float64
+	for _, child := range g.Children {
+		if child.CountsTowardParent() && child.Status != PaymentStatusSuccess {
+			pending += child.Amount
+		}
+	}
+	return RoundAmount(pending, g.Parent.Currency)
+}
+
+// RemainingAmount returns what new children may still add up to: the parent's amount less what
+// is paid and pending
+func (g *PaymentGroup) RemainingAmount() float64 {
+	return math.Max(0, RoundAmount(g.Parent.Amount-g.PaidAmount()-g.PendingAmount(), g.Parent.Currency))
+}
@@ -0,0 +1,73 @@
+package core
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LedgerDirection represents the side of a ledger entry
+type LedgerDirection string
+
+const (
+	LedgerDirectionDebit  LedgerDirection = "DEBIT"
+	LedgerDirectionCredit LedgerDirection = "CREDIT"
+)
+
+// Ledger accounts affected by payment state changes
+const (
+	LedgerAccountCustomer           = "customer"
+	LedgerAccountMerchantSettlement = "merchant_settlement"
+)
+
+// LedgerEntry represents a single debit or credit line in the ledger
+type LedgerEntry struct {
+	ID        uuid.UUID
+	PaymentID uuid.UUID
+	Account   string
+	Direction LedgerDirection
+	Amount    float64
+	Currency  Currency
+	CreatedAt time.Time
+}
+
+// SettlementEntries returns the balanced entries recorded when a payment succeeds:
+// the customer is debited and the merchant settlement account is credited
+func SettlementEntries(p *Payment) []LedgerEntry {
+	return balancedEntries(p, p.Amount, LedgerAccountCustomer, LedgerAccountMerchantSettlement)
+}
+
+// SettlementAdjustmentEntries returns the balanced entries that settle amount of a payment on top
+// of what is already settled, e.g. when an operator overrides a payment to SUCCESS
+func SettlementAdjustmentEntries(p *Payment, amount float64) []LedgerEntry {
+	return balancedEntries(p, amount, LedgerAccountCustomer, LedgerAccountMerchantSettlement)
+}
+
+// ReversalEntries returns the balanced entries that reverse (part of) a settlement, on refund or
+// when an override takes a payment out of SUCCESS:
+// the merchant settlement account is debited and the customer is credited
+func ReversalEntries(p *Payment, amount float64) []LedgerEntry {
+	return balancedEntries(p, amount, LedgerAccountMerchantSettlement, LedgerAccountCustomer)
+}
+
+// balancedEntries builds a debit/credit pair for the given amount
+func balancedEntries(p *Payment, amount float64, debitAccount, creditAccount string) []LedgerEntry {
+	return []LedgerEntry{
+		{
+			ID:        uuid.New(),
+			PaymentID: p.ID,
+			Account:   debitAccount,
+			Direction: LedgerDirectionDebit,
+			Amount:    amount,
+			Currency:  p.Currency,
+		},
+		{
+			ID:        uuid.New(),
+			PaymentID: p.ID,
+			Account:   creditAccount,
+			Direction: LedgerDirectionCredit,
+			Amount:    amount,
+			Currency:  p.Currency,
+		},
+	}
+}
@@ -10,11 +10,45 @@ import (
 type PaymentStatus string
 
 const (
-	PaymentStatusPending PaymentStatus = "PENDING"
-	PaymentStatusSuccess PaymentStatus = "SUCCESS"
-	PaymentStatusFailed  PaymentStatus = "FAILED"
+	PaymentStatusReview     PaymentStatus = "REVIEW" // Held by a risk rule until an operator approves or rejects it
+	PaymentStatusPending    PaymentStatus = "PENDING"
+	PaymentStatusAuthorized PaymentStatus = "AUTHORIZED" // Auth-only payment approved and awaiting capture
+	PaymentStatusSuccess    PaymentStatus = "SUCCESS"
+	PaymentStatusFailed     PaymentStatus = "FAILED"
+	PaymentStatusExpired    PaymentStatus = "EXPIRED"
+	PaymentStatusCancelled  PaymentStatus = "CANCELLED" // Authorization voided without capture
 )
 
+// paymentTransitions lists the statuses each status may move to
+// Captures move AUTHORIZED to SUCCESS; FAILED returns to PENDING only when an operator reprocesses it
+// REVIEW is left only by an operator's decision
+var paymentTransitions = map[PaymentStatus][]PaymentStatus{
+	PaymentStatusReview:     {PaymentStatusPending, PaymentStatusFailed},
+	PaymentStatusPending:    {PaymentStatusAuthorized, PaymentStatusSuccess, PaymentStatusFailed, PaymentStatusExpired},
+	PaymentStatusAuthorized: {PaymentStatusSuccess, PaymentStatusCancelled},
+	PaymentStatusFailed:     {PaymentStatusPending},
+}
+
+// IsValid checks if the status is one of the known statuses
+func (s PaymentStatus) IsValid() bool {
+	switch s {
+	case PaymentStatusReview, PaymentStatusPending, PaymentStatusAuthorized, PaymentStatusSuccess,
+		PaymentStatusFailed, PaymentStatusExpired, PaymentStatusCancelled:
+		return true
+	}
+	return false
+}
+
+// CanTransitionTo checks if a payment in this status may move to next
+func (s PaymentStatus) CanTransitionTo(next PaymentStatus) bool {
+	for _, allowed := range paymentTransitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
 // Currency represents supported currencies
 type Currency string
 
@@ -23,15 +57,69 @@ const (
 	CurrencyUSD Currency = "USD"
 )
 
+// PaymentSource identifies the code path that created a payment
+type PaymentSource string
+
+const (
+	PaymentSourceAPI    PaymentSource = "api"    // Public create endpoint
+	PaymentSourceImport PaymentSource = "import" // Admin bulk import
+	PaymentSourceReplay PaymentSource = "replay" // Re-created from recorded events
+)
+
+// IsValid checks if the source is one of the known sources
+func (s PaymentSource) IsValid() bool {
+	switch s {
+	case PaymentSourceAPI, PaymentSourceImport, PaymentSourceReplay:
+		return true
+	}
+	return false
+}
+
+// PaymentMethod identifies how a payment was paid
+type PaymentMethod string
+
+const (
+	PaymentMethodUnknown      PaymentMethod = "unknown" // Not reported by the merchant
+	PaymentMethodCard         PaymentMethod = "card"
+	PaymentMethodMobileMoney  PaymentMethod = "mobile_money"
+	PaymentMethodBankTransfer PaymentMethod = "bank_transfer"
+)
+
+// IsValid checks if the method is one of the known methods
+func (m PaymentMethod) IsValid() bool {
+	switch m {
+	case PaymentMethodUnknown, PaymentMethodCard, PaymentMethodMobileMoney, PaymentMethodBankTransfer:
+		return true
+	}
+	return false
+}
+
 // Payment represents a payment domain entity
 type Payment struct {
-	ID        uuid.UUID
-	Amount    float64
-	Currency  Currency
-	Reference string
-	Status    PaymentStatus
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ID               uuid.UUID
+	MerchantID       string
+	Amount           float64
+	Currency         Currency
+	Reference        string
+	Description      string // Optional human-readable label, e.g. "Order #123"
+	CustomerID       string // Optional merchant-side customer identifier
+	CustomerEmail    string // Optional, validated as an email address
+	Status           PaymentStatus
+	Source           PaymentSource
+	Method           PaymentMethod
+	IsTest           bool
+	AuthOnly         bool       // Created with capture=false: processing authorizes it and a separate capture settles it
+	AuthorizedAmount float64    // Amount an auth-only payment was created for; Amount becomes the captured amount
+	Tags             []string   // Merchant-defined labels, e.g. "subscription"
+	ExpiresAt        *time.Time // nil means the payment never expires
+	Attempts         int        // Processing attempts started; an operator reprocess starts over at 0
+	ParentPaymentID  *uuid.UUID // Set on installments: the payment whose amount this one collects part of
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+
+	// Related records, populated only when explicitly loaded
+	Events        []PaymentEvent
+	LedgerEntries []LedgerEntry
 }
 
 // IsPending checks if payment is in pending status
@@ -41,6 +129,28 @@ func (p *Payment) IsPending() bool {
 
 // IsTerminal checks if payment is in a terminal state
 func (p *Payment) IsTerminal() bool {
-	return p.Status == PaymentStatusSuccess || p.Status == PaymentStatusFailed
+	return p.Status == PaymentStatusSuccess || p.Status == PaymentStatusFailed ||
+		p.Status == PaymentStatusExpired || p.Status == PaymentStatusCancelled
 }
 
+// IsAuthorized checks if an auth-only payment is awaiting capture
+func (p *Payment) IsAuthorized() bool {
+	return p.Status == PaymentStatusAuthorized
+}
+
+// InReview checks if the payment is held for manual review
+func (p *Payment) InReview() bool {
+	return p.Status == PaymentStatusReview
+}
+
+// IsExpiredAt checks if a pending payment has passed its expiry at the given time
+func (p *Payment) IsExpiredAt(now time.Time) bool {
+	return p.IsPending() && p.ExpiresAt != nil && !now.Before(*p.ExpiresAt)
+}
+
+// CanReprocessAt checks if an operator may send the payment back to PENDING at the given time
+// Only FAILED payments qualify: SUCCESS has settled ledger entries and EXPIRED is final.
+// A payment past its expires_at is not reprocessed either, since it would only expire
+func (p *Payment) CanReprocessAt(now time.Time) bool {
+	return p.Status == PaymentStatusFailed && (p.ExpiresAt == nil || now.Before(*p.ExpiresAt))
+}
@@ -0,0 +1,39 @@
+package core
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Notes recorded on payment events, explaining why the transition happened
+const (
+	NoteGatewayApproved   = "gateway approved"
+	NoteGatewayDeclined   = "gateway declined"
+	NoteGatewayAuthorized = "gateway authorized"
+	NoteTestSucceeded     = "test payment: scripted success"
+	NoteTestAuthorized    = "test payment: scripted authorization"
+	NoteTestFailed        = "test payment: scripted failure (FAIL- reference)"
+	NoteExpired           = "expired: expires_at passed before processing"
+	NoteMaxAttempts       = "max attempts exceeded"
+	NoteReprocess         = "reprocess requested by an operator"
+	NoteCaptured          = "captured in full"
+	NotePartialCapture    = "captured in part, the remaining authorization was released"
+	NoteVoided            = "voided, the authorization was released"
+	NoteReviewApproved    = "approved by an operator after review"
+	NoteReviewRejected    = "rejected by an operator after review"
+	NoteStatusOverride    = "status override"
+	NoteReplay            = "replayed by an operator"
+)
+
+// PaymentEvent records a payment status transition
+// FromStatus is empty for the event recorded when the payment is created
+type PaymentEvent struct {
+	ID         uuid.UUID
+	PaymentID  uuid.UUID
+	FromStatus PaymentStatus
+	ToStatus   PaymentStatus
+	Note       string // Why the transition happened; empty for creation
+	Actor      string // Operator who forced the transition; empty unless it was a status override
+	CreatedAt  time.Time
+}
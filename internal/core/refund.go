@@ -0,0 +1,32 @@
+package core
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RefundStatus represents the status of a refund
+type RefundStatus string
+
+const (
+	RefundStatusSuccess RefundStatus = "SUCCESS"
+	RefundStatusFailed  RefundStatus = "FAILED"
+)
+
+// Refund represents a (possibly partial) refund of a successful payment
+// RefundID is the client-supplied idempotency key, unique per payment
+type Refund struct {
+	ID        uuid.UUID
+	PaymentID uuid.UUID
+	RefundID  string
+	Amount    float64
+	Currency  Currency
+	Status    RefundStatus
+	CreatedAt time.Time
+}
+
+// IsRefundable checks if refunds may be issued against the payment
+func (p *Payment) IsRefundable() bool {
+	return p.Status == PaymentStatusSuccess
+}
@@ -0,0 +1,34 @@
+package core
+
+// RiskOutcome is what a risk evaluator decided for a new payment
+type RiskOutcome string
+
+const (
+	RiskApprove RiskOutcome = "approve" // Process the payment right away
+	RiskReview  RiskOutcome = "review"  // Hold the payment in REVIEW until an operator decides
+	RiskDecline RiskOutcome = "decline" // Refuse to create the payment
+)
+
+// RiskDecision is a risk evaluator's verdict on a new payment
+// Reason explains a review or decline; a decline is reported to the client with it, so it should
+// wrap ErrPaymentDeclined or a more specific sentinel. A decline without one reports ErrPaymentDeclined
+type RiskDecision struct {
+	Outcome RiskOutcome
+	Reason  error
+}
+
+// Approved is the decision to process a payment right away
+func Approved() RiskDecision {
+	return RiskDecision{Outcome: RiskApprove}
+}
+
+// Severity orders outcomes from approve to decline, so combined rules keep the strictest
+func (o RiskOutcome) Severity() int {
+	switch o {
+	case RiskReview:
+		return 1
+	case RiskDecline:
+		return 2
+	}
+	return 0
+}
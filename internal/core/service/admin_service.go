@@ -0,0 +1,262 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/cashflow/payment-gateway/internal/core"
+	"github.com/cashflow/payment-gateway/internal/port/input"
+	"github.com/cashflow/payment-gateway/internal/port/output"
+	"github.com/google/uuid"
+)
+
+// DefaultPendingAge is how long a payment must have been PENDING to be listed as stuck by default
+// Processing normally takes a few seconds, so anything older is worth a look
+const DefaultPendingAge = 5 * time.Minute
+
+// MaxReprocessBatch bounds the payments one reprocess request resets and re-enqueues
+const MaxReprocessBatch = 100
+
+// Bounds on the audit fields of a status override, sized to the payment_events columns
+const (
+	MaxOverrideActorLength  = 64
+	MaxOverrideReasonLength = 200
+)
+
+// AdminServiceImpl implements the AdminService input port
+type AdminServiceImpl struct {
+	paymentRepo output.PaymentRepository
+	publisher   output.EventPublisher
+	clock       core.Clock
+}
+
+// NewAdminService creates a new admin service
+// clock sets the pending cutoff, judges reprocess eligibility and stamps the events it publishes;
+// nil uses the system clock
+func NewAdminService(paymentRepo output.PaymentRepository, publisher output.EventPublisher, clock core.Clock) input.AdminService {
+	if clock == nil {
+		clock = core.SystemClock{}
+	}
+	return &AdminServiceImpl{
+		paymentRepo: paymentRepo,
+		publisher:   publisher,
+		clock:       clock,
+	}
+}
+
+// PurgePayments soft-deletes stale payments matching the request filter
+// Only PENDING payments may be purged; payments that reached a terminal state are kept for audit
+func (s *AdminServiceImpl) PurgePayments(req input.PurgePaymentsRequest) (*input.PurgePaymentsResponse, error) {
+	// Validate filter
+	if req.Status == "" {
+		req.Status = core.PaymentStatusPending
+	}
+	if req.Status != core.PaymentStatusPending {
+		return nil, fmt.Errorf("%w: status must be PENDING", core.ErrInvalidParameter)
+	}
+	if req.CreatedBefore.IsZero() {
+		return nil, fmt.Errorf("%w: created_before is required", core.ErrInvalidParameter)
+	}
+
+	filter := output.PaymentFilter{
+		Status:        req.Status,
+		CreatedBefore: req.CreatedBefore,
+	}
+
+	// Dry run only reports what would be deleted
+	if req.DryRun {
+		count, err := s.paymentRepo.Count(filter)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count payments: %w", err)
+		}
+		return &input.PurgePaymentsResponse{Count: count, DryRun: true}, nil
+	}
+
+	count, err := s.paymentRepo.SoftDelete(filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to purge payments: %w", err)
+	}
+	return &input.PurgePaymentsResponse{Count: count}, nil
+}
+
+// ListPendingPayments retrieves PENDING payments older than the request's age, oldest first
+func (s *AdminServiceImpl) ListPendingPayments(req input.ListPendingPaymentsRequest) (*input.ListPendingPaymentsResponse, error) {
+	// Apply defaults
+	if req.OlderThan == 0 {
+		req.OlderThan = DefaultPendingAge
+	}
+	if req.OlderThan < 0 {
+		return nil, fmt.Errorf("%w: older_than must not be negative", core.ErrInvalidParameter)
+	}
+	if req.Limit == 0 {
+		req.Limit = DefaultListLimit
+	}
+	if req.Limit < 0 || req.Limit > MaxListLimit {
+		return nil, fmt.Errorf("%w: limit must be between 1 and %d", core.ErrInvalidParameter, MaxListLimit)
+	}
+
+	cutoff := s.clock.Now().Add(-req.OlderThan)
+	payments, err := s.paymentRepo.ListPendingBefore(cutoff, req.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pending payments: %w", err)
+	}
+
+	responses := make([]input.PaymentResponse, 0, len(payments))
+	for _, payment := range payments {
+		responses = append(responses, *toPaymentResponse(payment))
+	}
+	return &input.ListPendingPaymentsResponse{
+		Payments: responses,
+		Cutoff:   cutoff,
+		Limit:    req.Limit,
+	}, nil
+}
+
+// ReprocessPayments sends eligible FAILED payments back to PENDING and re-publishes their
+// payment.created messages so the worker processes them again
+// Eligibility is core.Payment.CanReprocessAt; payments that don't qualify are silently skipped.
+// A dry run reports the payments that currently qualify without changing anything
+func (s *AdminServiceImpl) ReprocessPayments(req input.ReprocessPaymentsRequest) (*input.ReprocessPaymentsResponse, error) {
+	// Validate selection
+	byTime := !req.CreatedAfter.IsZero() || !req.CreatedBefore.IsZero()
+	if len(req.PaymentIDs) == 0 && !byTime {
+		return nil, fmt.Errorf("%w: payment_ids or created_after/created_before is required", core.ErrInvalidParameter)
+	}
+	if len(req.PaymentIDs) > 0 && byTime {
+		return nil, fmt.Errorf("%w: set either payment_ids or created_after/created_before, not both", core.ErrInvalidParameter)
+	}
+	if len(req.PaymentIDs) > MaxReprocessBatch {
+		return nil, fmt.Errorf("%w: at most %d payment_ids per request", core.ErrInvalidParameter, MaxReprocessBatch)
+	}
+
+	filter := output.PaymentFilter{
+		IDs:           req.PaymentIDs,
+		Status:        core.PaymentStatusFailed,
+		CreatedAfter:  req.CreatedAfter,
+		CreatedBefore: req.CreatedBefore,
+		Limit:         MaxReprocessBatch,
+	}
+	candidates, err := s.paymentRepo.List(filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list payments: %w", err)
+	}
+
+	now := s.clock.Now()
+	ids := make([]uuid.UUID, 0, len(candidates))
+	for _, payment := range candidates {
+		if payment.CanReprocessAt(now) {
+			ids = append(ids, payment.ID)
+		}
+	}
+
+	// Dry run only reports what would be reprocessed
+	if req.DryRun || len(ids) == 0 {
+		return &input.ReprocessPaymentsResponse{PaymentIDs: ids, DryRun: req.DryRun}, nil
+	}
+
+	// The repository re-checks eligibility under the row lock, so the reset set may be smaller
+	reset, err := s.paymentRepo.ResetForReprocess(ids, core.NoteReprocess)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reprocess payments: %w", err)
+	}
+
+	response := &input.ReprocessPaymentsResponse{PaymentIDs: make([]uuid.UUID, 0, len(reset))}
+	events := make([]core.DomainEvent, 0, len(reset))
+	for _, payment := range reset {
+		response.PaymentIDs = append(response.PaymentIDs, payment.ID)
+		events = append(events, core.PaymentCreatedEvent(payment, now))
+	}
+
+	// The reset and the payments' outbox entries are committed, so a publish failure is reported
+	// rather than returned; the outbox relay publishes them later
+	if err := s.publisher.PublishBatch(events); err != nil {
+		log.Printf("Failed to enqueue %d reprocessed payments: %v", len(events), err)
+		return response, nil
+	}
+	if err := s.paymentRepo.MarkPublished(response.PaymentIDs); err != nil {
+		// The relay republishes them later, and the worker ignores the duplicates
+		log.Printf("Failed to clear outbox entries of %d reprocessed payments: %v", len(response.PaymentIDs), err)
+	}
+	response.Enqueued = true
+	return response, nil
+}
+
+// ApprovePayment releases a REVIEW payment to PENDING and publishes its payment.created message
+// so the worker processes it like any other payment
+// The approval and the payment's outbox entry are committed before publishing, so a publish
+// failure leaves the payment PENDING and unenqueued (Enqueued=false) until the outbox relay publishes it
+func (s *AdminServiceImpl) ApprovePayment(id uuid.UUID) (*input.PaymentResponse, error) {
+	payment, err := s.paymentRepo.ResolveReview(id, core.PaymentStatusPending, core.NoteReviewApproved)
+	if err != nil {
+		return nil, fmt.Errorf("failed to approve payment: %w", err)
+	}
+
+	response := toPaymentResponse(payment)
+	response.Enqueued = s.enqueue(payment)
+	return response, nil
+}
+
+// RejectPayment moves a REVIEW payment to FAILED and publishes payment.failed
+// The rejection is committed before publishing, so a publish failure is only logged
+func (s *AdminServiceImpl) RejectPayment(id uuid.UUID) (*input.PaymentResponse, error) {
+	payment, err := s.paymentRepo.ResolveReview(id, core.PaymentStatusFailed, core.NoteReviewRejected)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reject payment: %w", err)
+	}
+
+	if err := s.publisher.Publish(core.PaymentProcessedEvent(payment, payment.Status, s.clock.Now())); err != nil {
+		log.Printf("Failed to publish %s event for payment %s: %v", payment.Status, payment.ID, err)
+	}
+	return toPaymentResponse(payment), nil
+}
+
+// OverrideStatus forces a payment to the requested status, bypassing the transition table
+// It exists to correct a payment after reconciling with the provider. The repository posts the
+// ledger entries that match the new status; no webhook is sent. A payment moved to PENDING is
+// re-enqueued like a reprocessed one: its payment.created message is published, and if that
+// fails the outbox relay publishes it later
+// The override is recorded as a payment event carrying the actor and reason
+func (s *AdminServiceImpl) OverrideStatus(req input.OverrideStatusRequest) (*input.PaymentResponse, error) {
+	// Validate request
+	if !req.Status.IsValid() {
+		return nil, fmt.Errorf("%w: unknown status %q", core.ErrInvalidParameter, req.Status)
+	}
+	req.Actor = strings.TrimSpace(req.Actor)
+	if req.Actor == "" || len(req.Actor) > MaxOverrideActorLength {
+		return nil, fmt.Errorf("%w: actor is required and must be at most %d characters", core.ErrInvalidParameter, MaxOverrideActorLength)
+	}
+	req.Reason = strings.TrimSpace(req.Reason)
+	if req.Reason == "" || len(req.Reason) > MaxOverrideReasonLength {
+		return nil, fmt.Errorf("%w: reason is required and must be at most %d characters", core.ErrInvalidParameter, MaxOverrideReasonLength)
+	}
+
+	note := core.NoteStatusOverride + ": " + req.Reason
+	payment, err := s.paymentRepo.OverrideStatus(req.PaymentID, req.Status, req.Actor, note)
+	if err != nil {
+		return nil, fmt.Errorf("failed to override payment status: %w", err)
+	}
+
+	log.Printf("Status of payment %s overridden to %s by %s: %s", payment.ID, payment.Status, req.Actor, req.Reason)
+	response := toPaymentResponse(payment)
+	if payment.Status == core.PaymentStatusPending {
+		response.Enqueued = s.enqueue(payment)
+	}
+	return response, nil
+}
+
+// enqueue publishes the payment.created message of a payment an operator moved to PENDING and
+// clears its outbox entry, reporting whether the broker confirmed it
+// The status change is committed, so a publish failure is only logged: the outbox relay retries it
+func (s *AdminServiceImpl) enqueue(payment *core.Payment) bool {
+	if err := s.publisher.Publish(core.PaymentCreatedEvent(payment, s.clock.Now())); err != nil {
+		log.Printf("Failed to enqueue payment %s: %v", payment.ID, err)
+		return false
+	}
+	if err := s.paymentRepo.MarkPublished([]uuid.UUID{payment.ID}); err != nil {
+		// The relay republishes it later, and the worker ignores the duplicate
+		log.Printf("Failed to clear outbox entry of payment %s: %v", payment.ID, err)
+	}
+	return true
+}
@@ -0,0 +1,208 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/cashflow/payment-gateway/internal/core"
+	"github.com/cashflow/payment-gateway/internal/port/input"
+	"github.com/cashflow/payment-gateway/internal/port/output"
+	"github.com/google/uuid"
+)
+
+func TestListPendingPaymentsCutoffFollowsClock(t *testing.T) {
+	clock := core.NewFakeClock(time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC))
+	repo := newMemoryPaymentRepository(clock)
+	publisher := &fakePublisher{}
+	payments := newTestPaymentService(repo, publisher, nil)
+	admin := NewAdminService(repo, publisher, clock)
+
+	created, err := payments.CreatePayment(input.CreatePaymentRequest{
+		MerchantID: "merchant-1",
+		Amount:     100,
+		Currency:   core.CurrencyETB,
+		Reference:  "STUCK-1",
+	})
+	if err != nil {
+		t.Fatalf("CreatePayment: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		elapsed time.Duration
+		want    []uuid.UUID
+	}{
+		{name: "younger than the default age", elapsed: DefaultPendingAge - time.Second},
+		{name: "older than the default age", elapsed: DefaultPendingAge + time.Second, want: []uuid.UUID{created.ID}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock.Set(created.CreatedAt.Add(tt.elapsed))
+
+			listed, err := admin.ListPendingPayments(input.ListPendingPaymentsRequest{})
+			if err != nil {
+				t.Fatalf("ListPendingPayments: %v", err)
+			}
+
+			if want := clock.Now().Add(-DefaultPendingAge); !listed.Cutoff.Equal(want) {
+				t.Errorf("cutoff: got %v, want %v", listed.Cutoff, want)
+			}
+			if len(listed.Payments) != len(tt.want) {
+				t.Fatalf("payments: got %d, want %d", len(listed.Payments), len(tt.want))
+			}
+			for i, id := range tt.want {
+				if listed.Payments[i].ID != id {
+					t.Errorf("payment %d: got %s, want %s", i, listed.Payments[i].ID, id)
+				}
+			}
+		})
+	}
+}
+
+func TestOverrideStatusKeepsLedgerInLineWithStatus(t *testing.T) {
+	repo := newMemoryPaymentRepository(nil)
+	publisher := &fakePublisher{}
+	admin := NewAdminService(repo, publisher, nil)
+
+	created, err := newTestPaymentService(repo, publisher, nil).CreatePayment(input.CreatePaymentRequest{
+		MerchantID: "merchant-1",
+		Amount:     100,
+		Currency:   core.CurrencyETB,
+		Reference:  "OVERRIDE-1",
+	})
+	if err != nil {
+		t.Fatalf("CreatePayment: %v", err)
+	}
+	if err := repo.MarkPublished([]uuid.UUID{created.ID}); err != nil {
+		t.Fatalf("MarkPublished: %v", err)
+	}
+
+	// Each step overrides the payment left by the previous one
+	steps := []struct {
+		status       core.PaymentStatus
+		wantSettled  float64
+		wantEnqueued bool
+	}{
+		{status: core.PaymentStatusSuccess, wantSettled: 100},
+		{status: core.PaymentStatusFailed, wantSettled: 0},
+		{status: core.PaymentStatusSuccess, wantSettled: 100},
+		{status: core.PaymentStatusPending, wantSettled: 0, wantEnqueued: true},
+	}
+	for _, step := range steps {
+		published := len(publisher.published())
+		response, err := admin.OverrideStatus(input.OverrideStatusRequest{
+			PaymentID: created.ID,
+			Status:    step.status,
+			Actor:     "jane",
+			Reason:    "reconciled with the provider",
+		})
+		if err != nil {
+			t.Fatalf("override to %s: %v", step.status, err)
+		}
+
+		payment, err := repo.GetByIDWithRelations(created.ID, output.PaymentRelations{Events: true, Ledger: true})
+		if err != nil {
+			t.Fatalf("GetByIDWithRelations: %v", err)
+		}
+		if settled := netSettlement(payment.LedgerEntries); settled != step.wantSettled {
+			t.Errorf("override to %s: settled %v, want %v", step.status, settled, step.wantSettled)
+		}
+		if actor := payment.Events[len(payment.Events)-1].Actor; actor != "jane" {
+			t.Errorf("override to %s: event actor %q, want %q", step.status, actor, "jane")
+		}
+		if response.Enqueued != step.wantEnqueued {
+			t.Errorf("override to %s: enqueued %t, want %t", step.status, response.Enqueued, step.wantEnqueued)
+		}
+		if step.wantEnqueued {
+			events := publisher.published()
+			if len(events) != published+1 || events[len(events)-1].EventType() != core.EventTypePaymentCreated {
+				t.Errorf("override to %s: want one payment.created published, got %v", step.status, events[published:])
+			}
+			if repo.unpublished[created.ID] {
+				t.Errorf("override to %s: outbox entry not cleared after publishing", step.status)
+			}
+		}
+	}
+}
+
+// netSettlement sums a payment's balance in the merchant settlement account
+func netSettlement(entries []core.LedgerEntry) float64 {
+	var settled float64
+	for _, entry := range entries {
+		if entry.Account != core.LedgerAccountMerchantSettlement {
+			continue
+		}
+		if entry.Direction == core.LedgerDirectionCredit {
+			settled += entry.Amount
+		} else {
+			settled -= entry.Amount
+		}
+	}
+	return settled
+}
+
+func TestOperatorEnqueuesLeaveOutboxEntryUntilPublished(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     core.PaymentStatus
+		enqueue    func(admin input.AdminService, id uuid.UUID) (bool, error)
+		publishErr error
+	}{
+		{
+			name:   "approve",
+			status: core.PaymentStatusReview,
+			enqueue: func(admin input.AdminService, id uuid.UUID) (bool, error) {
+				response, err := admin.ApprovePayment(id)
+				if err != nil {
+					return false, err
+				}
+				return response.Enqueued, nil
+			},
+		},
+		{
+			name:   "reprocess",
+			status: core.PaymentStatusFailed,
+			enqueue: func(admin input.AdminService, id uuid.UUID) (bool, error) {
+				response, err := admin.ReprocessPayments(input.ReprocessPaymentsRequest{PaymentIDs: []uuid.UUID{id}})
+				if err != nil {
+					return false, err
+				}
+				return response.Enqueued, nil
+			},
+		},
+	}
+	for _, tt := range tests {
+		for _, publishErr := range []error{nil, errors.New("broker unreachable")} {
+			t.Run(fmt.Sprintf("%s, publish error %v", tt.name, publishErr), func(t *testing.T) {
+				repo := newMemoryPaymentRepository(nil)
+				admin := NewAdminService(repo, &fakePublisher{err: publishErr}, nil)
+				payment := &core.Payment{
+					ID:         uuid.New(),
+					MerchantID: "merchant-1",
+					Amount:     100,
+					Currency:   core.CurrencyETB,
+					Reference:  "OPERATOR-1",
+					Status:     tt.status,
+				}
+				if err := repo.Create(payment); err != nil {
+					t.Fatalf("Create: %v", err)
+				}
+
+				enqueued, err := tt.enqueue(admin, payment.ID)
+				if err != nil {
+					t.Fatalf("%s: %v", tt.name, err)
+				}
+
+				// The outbox entry is written with the status change and only cleared once published
+				if enqueued != (publishErr == nil) {
+					t.Errorf("enqueued: got %t, want %t", enqueued, publishErr == nil)
+				}
+				if repo.unpublished[payment.ID] != (publishErr != nil) {
+					t.Errorf("outbox entry present: got %t, want %t", repo.unpublished[payment.ID], publishErr != nil)
+				}
+			})
+		}
+	}
+}
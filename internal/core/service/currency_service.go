@@ -0,0 +1,46 @@
+package service
+
+import (
+	"github.com/cashflow/payment-gateway/internal/core"
+	"github.com/cashflow/payment-gateway/internal/port/input"
+)
+
+// MinProcessableAmounts overrides the registry's smallest processable amount per currency
+type MinProcessableAmounts map[core.Currency]float64
+
+// For returns the smallest amount payments in the currency are processed for, 0 if there is no such floor
+func (m MinProcessableAmounts) For(info core.CurrencyInfo) float64 {
+	if amount, ok := m[info.Code]; ok {
+		return amount
+	}
+	return info.MinProcessable
+}
+
+// CurrencyServiceImpl implements the CurrencyService input port
+type CurrencyServiceImpl struct {
+	minProcessable MinProcessableAmounts
+}
+
+// NewCurrencyService creates a new currency service
+// minProcessable is reported as each currency's processable minimum; nil reports the registry's
+func NewCurrencyService(minProcessable MinProcessableAmounts) input.CurrencyService {
+	return &CurrencyServiceImpl{
+		minProcessable: minProcessable,
+	}
+}
+
+// ListCurrencies lists the currencies in the registry, with the amounts the validator accepts
+func (s *CurrencyServiceImpl) ListCurrencies() []input.CurrencyResponse {
+	currencies := make([]input.CurrencyResponse, 0, len(core.SupportedCurrencies))
+	for _, info := range core.SupportedCurrencies {
+		currencies = append(currencies, input.CurrencyResponse{
+			Code:      info.Code,
+			Decimals:  info.Decimals,
+			MinAmount: info.MinAmount,
+			MaxAmount: info.MaxAmount,
+
+			MinProcessable: s.minProcessable.For(info),
+		})
+	}
+	return currencies
+}
@@ -0,0 +1,195 @@
+package service
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"time"
+
+	"github.com/cashflow/payment-gateway/internal/core"
+	"github.com/cashflow/payment-gateway/internal/port/input"
+	"github.com/cashflow/payment-gateway/internal/port/output"
+	"github.com/google/uuid"
+)
+
+// ExportContentType is the content type of export objects: gzip-compressed newline-delimited JSON
+const ExportContentType = "application/gzip"
+
+// exportRecord is one line of an export object
+// customer_email is left out; the analytics pipeline has no use for contact details
+type exportRecord struct {
+	ID               uuid.UUID          `json:"id"`
+	MerchantID       string             `json:"merchant_id"`
+	Amount           json.Number        `json:"amount"`
+	Currency         core.Currency      `json:"currency"`
+	Reference        string             `json:"reference"`
+	Description      string             `json:"description,omitempty"`
+	CustomerID       string             `json:"customer_id,omitempty"`
+	Status           core.PaymentStatus `json:"status"`
+	Source           core.PaymentSource `json:"source"`
+	Method           core.PaymentMethod `json:"method"`
+	IsTest           bool               `json:"is_test"`
+	AuthorizedAmount json.Number        `json:"authorized_amount,omitempty"`
+	Tags             []string           `json:"tags"`
+	ExpiresAt        *time.Time         `json:"expires_at,omitempty"`
+	CreatedAt        time.Time          `json:"created_at"`
+	UpdatedAt        time.Time          `json:"updated_at"`
+}
+
+// ExportServiceImpl implements the ExportService input port
+type ExportServiceImpl struct {
+	exportRepo output.ExportRepository
+	store      output.ObjectStore
+	keyPrefix  string
+	clock      core.Clock
+}
+
+// NewExportService creates a new export service writing objects under keyPrefix
+// store is nil when no object store is configured; exports are then rejected
+func NewExportService(exportRepo output.ExportRepository, store output.ObjectStore, keyPrefix string, clock core.Clock) input.ExportService {
+	return &ExportServiceImpl{
+		exportRepo: exportRepo,
+		store:      store,
+		keyPrefix:  keyPrefix,
+		clock:      clock,
+	}
+}
+
+// StartExport records a RUNNING export of the payments created on the request's UTC day and
+// runs it in the background, so a large day doesn't outlive the HTTP request
+// Only days that have ended can be exported. Each export writes its own object, so exporting
+// a day again never overwrites an earlier file. An export interrupted by a restart stays RUNNING
+func (s *ExportServiceImpl) StartExport(req input.StartExportRequest) (*input.ExportResponse, error) {
+	if s.store == nil {
+		return nil, core.ErrExportsNotConfigured
+	}
+	if req.Day.IsZero() {
+		return nil, fmt.Errorf("%w: date is required", core.ErrInvalidParameter)
+	}
+	day := req.Day.UTC().Truncate(24 * time.Hour)
+	if day.Add(24 * time.Hour).After(s.clock.Now()) {
+		return nil, fmt.Errorf("%w: date must be a day that has already ended (UTC)", core.ErrInvalidParameter)
+	}
+
+	id := uuid.New()
+	export := &core.Export{
+		ID:        id,
+		Day:       day,
+		Status:    core.ExportStatusRunning,
+		ObjectKey: fmt.Sprintf("%spayments/date=%s/%s.ndjson.gz", s.keyPrefix, day.Format(time.DateOnly), id),
+	}
+	if err := s.exportRepo.CreateExport(export); err != nil {
+		return nil, fmt.Errorf("failed to start export: %w", err)
+	}
+
+	go s.run(*export)
+	return toExportResponse(export), nil
+}
+
+// GetExport retrieves an export by ID
+func (s *ExportServiceImpl) GetExport(id uuid.UUID) (*input.ExportResponse, error) {
+	export, err := s.exportRepo.GetExport(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get export: %w", err)
+	}
+	return toExportResponse(export), nil
+}
+
+// run streams the export's payments into its object and records the outcome
+// Payments are encoded into a pipe as they are read and the store uploads from the other end,
+// so neither the rows nor the object are ever held in memory as a whole
+func (s *ExportServiceImpl) run(export core.Export) {
+	reader, writer := io.Pipe()
+	written := make(chan int64, 1)
+	go func() {
+		count, err := s.writeRecords(writer, export.Day)
+		writer.CloseWithError(err)
+		written <- count
+	}()
+
+	err := s.store.Put(export.ObjectKey, reader, ExportContentType)
+	// Unblocks the writer if the upload stopped reading early
+	reader.CloseWithError(io.ErrClosedPipe)
+	export.RowCount = <-written
+
+	completedAt := s.clock.Now()
+	export.CompletedAt = &completedAt
+	export.Status = core.ExportStatusSucceeded
+	if err != nil {
+		export.Status = core.ExportStatusFailed
+		export.Error = err.Error()
+		log.Printf("Export %s of %s failed: %v", export.ID, export.Day.Format(time.DateOnly), err)
+	} else {
+		log.Printf("Exported %d payments of %s to %s", export.RowCount, export.Day.Format(time.DateOnly), export.ObjectKey)
+	}
+
+	if err := s.exportRepo.UpdateExport(&export); err != nil {
+		log.Printf("Failed to record the outcome of export %s: %v", export.ID, err)
+	}
+}
+
+// writeRecords writes the day's payments to w as gzip-compressed NDJSON and returns how many it wrote
+func (s *ExportServiceImpl) writeRecords(w io.Writer, day time.Time) (int64, error) {
+	gz := gzip.NewWriter(w)
+	encoder := json.NewEncoder(gz)
+
+	var count int64
+	err := s.exportRepo.StreamPayments(day, day.Add(24*time.Hour), func(payment *core.Payment) error {
+		if err := encoder.Encode(toExportRecord(payment)); err != nil {
+			return fmt.Errorf("failed to write payment %s: %w", payment.ID, err)
+		}
+		count++
+		return nil
+	})
+	if err != nil {
+		return count, err
+	}
+	if err := gz.Close(); err != nil {
+		return count, fmt.Errorf("failed to finish export object: %w", err)
+	}
+	return count, nil
+}
+
+// toExportRecord converts a payment to its export line
+func toExportRecord(payment *core.Payment) exportRecord {
+	record := exportRecord{
+		ID:          payment.ID,
+		MerchantID:  payment.MerchantID,
+		Amount:      json.Number(core.FormatAmount(payment.Amount, payment.Currency)),
+		Currency:    payment.Currency,
+		Reference:   payment.Reference,
+		Description: payment.Description,
+		CustomerID:  payment.CustomerID,
+		Status:      payment.Status,
+		Source:      payment.Source,
+		Method:      payment.Method,
+		IsTest:      payment.IsTest,
+		Tags:        payment.Tags,
+		ExpiresAt:   payment.ExpiresAt,
+		CreatedAt:   payment.CreatedAt,
+		UpdatedAt:   payment.UpdatedAt,
+	}
+	if payment.AuthOnly {
+		record.AuthorizedAmount = json.Number(core.FormatAmount(payment.AuthorizedAmount, payment.Currency))
+	}
+	if record.Tags == nil {
+		record.Tags = []string{}
+	}
+	return record
+}
+
+// toExportResponse converts an export to the service response
+func toExportResponse(export *core.Export) *input.ExportResponse {
+	return &input.ExportResponse{
+		ID:          export.ID,
+		Day:         export.Day,
+		Status:      export.Status,
+		ObjectKey:   export.ObjectKey,
+		RowCount:    export.RowCount,
+		Error:       export.Error,
+		CreatedAt:   export.CreatedAt,
+		CompletedAt: export.CompletedAt,
+	}
+}
@@ -0,0 +1,659 @@
+package service
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cashflow/payment-gateway/internal/core"
+	"github.com/cashflow/payment-gateway/internal/port/output"
+	"github.com/google/uuid"
+)
+
+// memoryPaymentRepository is an in-memory output.PaymentRepository for service tests and benchmarks
+// It keeps the guarantees services rely on (unique references, the PENDING guard, events and
+// ledger entries per transition) without a database; expiry is judged by clock, standing in for
+// the database's clock
+type memoryPaymentRepository struct {
+	clock core.Clock
+
+	mu          sync.Mutex
+	payments    map[uuid.UUID]*core.Payment
+	references  map[string]uuid.UUID
+	unpublished map[uuid.UUID]bool
+}
+
+// newMemoryPaymentRepository creates an empty repository; nil clock uses the system clock
+func newMemoryPaymentRepository(clock core.Clock) *memoryPaymentRepository {
+	if clock == nil {
+		clock = core.SystemClock{}
+	}
+	return &memoryPaymentRepository{
+		clock:       clock,
+		payments:    make(map[uuid.UUID]*core.Payment),
+		references:  make(map[string]uuid.UUID),
+		unpublished: make(map[uuid.UUID]bool),
+	}
+}
+
+// copyPayment returns a copy of p that callers may change without touching the stored payment
+func copyPayment(p *core.Payment) *core.Payment {
+	c := *p
+	c.Tags = append([]string(nil), p.Tags...)
+	c.Events = append([]core.PaymentEvent(nil), p.Events...)
+	c.LedgerEntries = append([]core.LedgerEntry(nil), p.LedgerEntries...)
+	return &c
+}
+
+// transition moves the stored payment to status, recording the event and stamping UpdatedAt
+func (r *memoryPaymentRepository) transition(p *core.Payment, status core.PaymentStatus, note, actor string) {
+	now := r.clock.Now()
+	p.Events = append(p.Events, core.PaymentEvent{
+		ID:         uuid.New(),
+		PaymentID:  p.ID,
+		FromStatus: p.Status,
+		ToStatus:   status,
+		Note:       note,
+		Actor:      actor,
+		CreatedAt:  now,
+	})
+	p.Status = status
+	p.UpdatedAt = now
+}
+
+// post appends ledger entries to the stored payment
+func (r *memoryPaymentRepository) post(p *core.Payment, entries []core.LedgerEntry) {
+	now := r.clock.Now()
+	for _, entry := range entries {
+		entry.CreatedAt = now
+		p.LedgerEntries = append(p.LedgerEntries, entry)
+	}
+}
+
+func (r *memoryPaymentRepository) Create(payment *core.Payment) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.create(payment)
+}
+
+func (r *memoryPaymentRepository) create(payment *core.Payment) error {
+	if _, ok := r.references[payment.Reference]; ok {
+		return core.ErrReferenceExists
+	}
+	now := r.clock.Now()
+	payment.CreatedAt, payment.UpdatedAt = now, now
+
+	stored := copyPayment(payment)
+	stored.Events = []core.PaymentEvent{{ID: uuid.New(), PaymentID: payment.ID, ToStatus: payment.Status, CreatedAt: now}}
+	r.payments[payment.ID] = stored
+	r.references[payment.Reference] = payment.ID
+	if payment.Status == core.PaymentStatusPending {
+		r.unpublished[payment.ID] = true
+	}
+	return nil
+}
+
+func (r *memoryPaymentRepository) CreateWithinDailyLimit(payment *core.Payment, limit float64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	startOfDay := r.clock.Now().UTC().Truncate(24 * time.Hour)
+	var total float64
+	for _, p := range r.payments {
+		if p.MerchantID == payment.MerchantID && p.Currency == payment.Currency &&
+			!p.CreatedAt.Before(startOfDay) && p.CountsTowardParent() {
+			total += p.Amount
+		}
+	}
+	if core.RoundAmount(total+payment.Amount, payment.Currency) > limit {
+		return core.ErrLimitExceeded
+	}
+	return r.create(payment)
+}
+
+func (r *memoryPaymentRepository) MarkPublished(ids []uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, id := range ids {
+		delete(r.unpublished, id)
+	}
+	return nil
+}
+
+// ClaimUnpublished ignores leases: every PENDING payment with an outbox entry is due
+func (r *memoryPaymentRepository) ClaimUnpublished(now time.Time, minAge, lease time.Duration, limit int) ([]*core.Payment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var claimed []*core.Payment
+	for id := range r.unpublished {
+		p := r.payments[id]
+		if p.Status != core.PaymentStatusPending {
+			delete(r.unpublished, id)
+			continue
+		}
+		if p.CreatedAt.After(now.Add(-minAge)) || len(claimed) == limit {
+			continue
+		}
+		claimed = append(claimed, copyPayment(p))
+	}
+	return claimed, nil
+}
+
+func (r *memoryPaymentRepository) DeleteUnpublished(id uuid.UUID) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	p, err := r.pending(id)
+	if err != nil {
+		return err
+	}
+	if p.Attempts > 0 {
+		return core.ErrPaymentAlreadyProcessed
+	}
+	delete(r.payments, id)
+	delete(r.references, p.Reference)
+	delete(r.unpublished, id)
+	return nil
+}
+
+// pending returns the stored payment, or the error the row-locking repository methods return
+// unless it is PENDING
+func (r *memoryPaymentRepository) pending(id uuid.UUID) (*core.Payment, error) {
+	p, ok := r.payments[id]
+	if !ok {
+		return nil, core.ErrPaymentNotFound
+	}
+	if p.Status != core.PaymentStatusPending {
+		return nil, core.ErrPaymentAlreadyProcessed
+	}
+	return p, nil
+}
+
+func (r *memoryPaymentRepository) GetByID(id uuid.UUID) (*core.Payment, error) {
+	payment, err := r.GetByIDWithRelations(id, output.PaymentRelations{})
+	if err != nil {
+		return nil, err
+	}
+	payment.Events, payment.LedgerEntries = nil, nil
+	return payment, nil
+}
+
+func (r *memoryPaymentRepository) GetGroup(parentID uuid.UUID) (*core.PaymentGroup, error) {
+	parent, err := r.GetByID(parentID)
+	if err != nil {
+		return nil, err
+	}
+	children, _ := r.List(output.PaymentFilter{})
+	group := &core.PaymentGroup{Parent: parent}
+	for i := len(children) - 1; i >= 0; i-- {
+		if children[i].ParentPaymentID != nil && *children[i].ParentPaymentID == parentID {
+			group.Children = append(group.Children, children[i])
+		}
+	}
+	return group, nil
+}
+
+func (r *memoryPaymentRepository) GetByIDWithRelations(id uuid.UUID, relations output.PaymentRelations) (*core.Payment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	p, ok := r.payments[id]
+	if !ok {
+		return nil, core.ErrPaymentNotFound
+	}
+	payment := copyPayment(p)
+	if !relations.Events {
+		payment.Events = nil
+	}
+	if !relations.Ledger {
+		payment.LedgerEntries = nil
+	}
+	return payment, nil
+}
+
+func (r *memoryPaymentRepository) GetUpdatedAt(id uuid.UUID, merchantID string) (time.Time, error) {
+	payment, err := r.GetByID(id)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if merchantID != "" && payment.MerchantID != merchantID {
+		return time.Time{}, core.ErrPaymentNotFound
+	}
+	return payment.UpdatedAt, nil
+}
+
+func (r *memoryPaymentRepository) ListEvents(page output.EventPage) ([]core.PaymentEvent, error) {
+	payment, err := r.GetByIDWithRelations(page.PaymentID, output.PaymentRelations{Events: true})
+	if err != nil {
+		return nil, err
+	}
+	events := payment.Events
+	if !page.OldestFirst {
+		for i, j := 0, len(events)-1; i < j; i, j = i+1, j-1 {
+			events[i], events[j] = events[j], events[i]
+		}
+	}
+	return paginate(events, page.Limit, page.Offset), nil
+}
+
+func (r *memoryPaymentRepository) ListAfter(filter output.PaymentFilter, after output.PaymentCursor) ([]*core.Payment, error) {
+	payments := r.matching(filter)
+	sort.Slice(payments, func(i, j int) bool { return cursorLess(output.CursorOf(payments[i]), output.CursorOf(payments[j])) })
+
+	var page []*core.Payment
+	for _, p := range payments {
+		if after.IsZero() || cursorLess(after, output.CursorOf(p)) {
+			page = append(page, p)
+		}
+	}
+	return paginate(page, filter.Limit, 0), nil
+}
+
+// cursorLess orders cursors by (created_at, id)
+func cursorLess(a, b output.PaymentCursor) bool {
+	if !a.CreatedAt.Equal(b.CreatedAt) {
+		return a.CreatedAt.Before(b.CreatedAt)
+	}
+	return a.ID.String() < b.ID.String()
+}
+
+func (r *memoryPaymentRepository) ProcessPayment(id uuid.UUID, newStatus core.PaymentStatus, note string) (core.PaymentStatus, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	p, err := r.pending(id)
+	if err != nil {
+		return "", err
+	}
+	return r.applyProcessedStatus(p, newStatus, note), nil
+}
+
+func (r *memoryPaymentRepository) ProcessPayments(updates []output.StatusUpdate) (map[uuid.UUID]core.PaymentStatus, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	applied := make(map[uuid.UUID]core.PaymentStatus, len(updates))
+	for _, update := range updates {
+		p, err := r.pending(update.PaymentID)
+		if err != nil {
+			continue
+		}
+		applied[update.PaymentID] = r.applyProcessedStatus(p, update.Status, update.Note)
+	}
+	return applied, nil
+}
+
+// applyProcessedStatus mirrors the database repository: an expired payment expires instead
+func (r *memoryPaymentRepository) applyProcessedStatus(p *core.Payment, status core.PaymentStatus, note string) core.PaymentStatus {
+	if p.IsExpiredAt(r.clock.Now()) {
+		status, note = core.PaymentStatusExpired, core.NoteExpired
+	}
+	r.transition(p, status, note, "")
+	if status == core.PaymentStatusSuccess {
+		r.post(p, core.SettlementEntries(p))
+	}
+	return status
+}
+
+func (r *memoryPaymentRepository) StartAttempt(id uuid.UUID, maxAttempts int) (*core.Payment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	p, err := r.pending(id)
+	if err != nil {
+		return nil, err
+	}
+	r.startAttempt(p, maxAttempts)
+	return copyPayment(p), nil
+}
+
+func (r *memoryPaymentRepository) StartAttempts(ids []uuid.UUID, maxAttempts int) ([]*core.Payment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var started []*core.Payment
+	seen := make(map[uuid.UUID]bool, len(ids))
+	for _, id := range ids {
+		p, err := r.pending(id)
+		if err != nil || seen[id] {
+			continue
+		}
+		seen[id] = true
+		r.startAttempt(p, maxAttempts)
+		started = append(started, copyPayment(p))
+	}
+	return started, nil
+}
+
+func (r *memoryPaymentRepository) startAttempt(p *core.Payment, maxAttempts int) {
+	if p.Attempts < maxAttempts {
+		p.Attempts++
+		p.UpdatedAt = r.clock.Now()
+		return
+	}
+	status, note := core.PaymentStatusFailed, core.NoteMaxAttempts
+	if p.IsExpiredAt(r.clock.Now()) {
+		status, note = core.PaymentStatusExpired, core.NoteExpired
+	}
+	r.transition(p, status, note, "")
+}
+
+func (r *memoryPaymentRepository) Capture(id uuid.UUID, amount float64) (*core.Payment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	p, ok := r.payments[id]
+	if !ok {
+		return nil, core.ErrPaymentNotFound
+	}
+	if p.Status != core.PaymentStatusAuthorized {
+		return nil, core.ErrPaymentNotCapturable
+	}
+	if amount == 0 {
+		amount = p.AuthorizedAmount
+	}
+	if amount > p.AuthorizedAmount {
+		return nil, core.ErrCaptureExceedsAuthorization
+	}
+	p.Amount = amount
+	r.transition(p, core.PaymentStatusSuccess, core.NoteCaptured, "")
+	r.post(p, core.SettlementEntries(p))
+	return copyPayment(p), nil
+}
+
+func (r *memoryPaymentRepository) Void(id uuid.UUID) (*core.Payment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	p, ok := r.payments[id]
+	if !ok {
+		return nil, core.ErrPaymentNotFound
+	}
+	if p.Status != core.PaymentStatusAuthorized {
+		return nil, core.ErrPaymentNotVoidable
+	}
+	r.transition(p, core.PaymentStatusCancelled, core.NoteVoided, "")
+	return copyPayment(p), nil
+}
+
+func (r *memoryPaymentRepository) ResolveReview(id uuid.UUID, decision core.PaymentStatus, note string) (*core.Payment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	p, ok := r.payments[id]
+	if !ok {
+		return nil, core.ErrPaymentNotFound
+	}
+	if p.Status != core.PaymentStatusReview {
+		return nil, core.ErrPaymentNotInReview
+	}
+	r.transition(p, decision, note, "")
+	if decision == core.PaymentStatusPending {
+		r.unpublished[id] = true
+	}
+	return copyPayment(p), nil
+}
+
+func (r *memoryPaymentRepository) OverrideStatus(id uuid.UUID, status core.PaymentStatus, actor, note string) (*core.Payment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	p, ok := r.payments[id]
+	if !ok {
+		return nil, core.ErrPaymentNotFound
+	}
+	if p.Status == status {
+		return nil, core.ErrPaymentStatusUnchanged
+	}
+	r.transition(p, status, note, actor)
+	r.settleToStatus(p)
+	if status == core.PaymentStatusPending {
+		p.Attempts = 0
+		r.unpublished[id] = true
+	}
+	return copyPayment(p), nil
+}
+
+// settleToStatus posts the entries that bring the payment's net settlement to its amount for
+// SUCCESS and to nothing otherwise; refunds are not modeled here
+func (r *memoryPaymentRepository) settleToStatus(p *core.Payment) {
+	var settled float64
+	for _, entry := range p.LedgerEntries {
+		if entry.Account != core.LedgerAccountMerchantSettlement {
+			continue
+		}
+		if entry.Direction == core.LedgerDirectionCredit {
+			settled += entry.Amount
+		} else {
+			settled -= entry.Amount
+		}
+	}
+
+	var target float64
+	if p.Status == core.PaymentStatusSuccess {
+		target = p.Amount
+	}
+	switch diff := math.Round((target-settled)*100) / 100; {
+	case diff > 0:
+		r.post(p, core.SettlementAdjustmentEntries(p, diff))
+	case diff < 0:
+		r.post(p, core.ReversalEntries(p, -diff))
+	}
+}
+
+func (r *memoryPaymentRepository) ReferenceExists(reference string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, ok := r.references[reference]
+	return ok, nil
+}
+
+func (r *memoryPaymentRepository) GetByReference(reference string) (*core.Payment, error) {
+	r.mu.Lock()
+	id, ok := r.references[reference]
+	r.mu.Unlock()
+	if !ok {
+		return nil, core.ErrPaymentNotFound
+	}
+	return r.GetByID(id)
+}
+
+func (r *memoryPaymentRepository) List(filter output.PaymentFilter) ([]*core.Payment, error) {
+	payments := r.matching(filter)
+	sort.Slice(payments, func(i, j int) bool { return payments[i].CreatedAt.After(payments[j].CreatedAt) })
+	return paginate(payments, filter.Limit, filter.Offset), nil
+}
+
+func (r *memoryPaymentRepository) Count(filter output.PaymentFilter) (int64, error) {
+	return int64(len(r.matching(filter))), nil
+}
+
+func (r *memoryPaymentRepository) ListPendingBefore(cutoff time.Time, limit int) ([]*core.Payment, error) {
+	payments := r.matching(output.PaymentFilter{Status: core.PaymentStatusPending, CreatedBefore: cutoff})
+	sort.Slice(payments, func(i, j int) bool { return payments[i].CreatedAt.Before(payments[j].CreatedAt) })
+	return paginate(payments, limit, 0), nil
+}
+
+func (r *memoryPaymentRepository) ExpireDue(limit int) ([]*core.Payment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.clock.Now()
+	var expired []*core.Payment
+	for _, p := range r.payments {
+		if len(expired) == limit {
+			break
+		}
+		if p.IsExpiredAt(now) {
+			r.transition(p, core.PaymentStatusExpired, core.NoteExpired, "")
+			expired = append(expired, copyPayment(p))
+		}
+	}
+	return expired, nil
+}
+
+func (r *memoryPaymentRepository) ResetForReprocess(ids []uuid.UUID, note string) ([]*core.Payment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.clock.Now()
+	var reset []*core.Payment
+	for _, id := range ids {
+		p, ok := r.payments[id]
+		if !ok || !p.CanReprocessAt(now) {
+			continue
+		}
+		r.transition(p, core.PaymentStatusPending, note, "")
+		p.Attempts = 0
+		r.unpublished[id] = true
+		reset = append(reset, copyPayment(p))
+	}
+	return reset, nil
+}
+
+func (r *memoryPaymentRepository) SoftDelete(filter output.PaymentFilter) (int64, error) {
+	payments := r.matching(filter)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, p := range payments {
+		delete(r.payments, p.ID)
+	}
+	return int64(len(payments)), nil
+}
+
+// matching returns copies of the payments the filter selects, in no particular order
+// Limit and Offset are left to the caller
+func (r *memoryPaymentRepository) matching(filter output.PaymentFilter) []*core.Payment {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ids := make(map[uuid.UUID]bool, len(filter.IDs))
+	for _, id := range filter.IDs {
+		ids[id] = true
+	}
+
+	var payments []*core.Payment
+	for _, p := range r.payments {
+		switch {
+		case filter.IDs != nil && !ids[p.ID],
+			filter.MerchantID != "" && p.MerchantID != filter.MerchantID,
+			filter.IsTest != nil && p.IsTest != *filter.IsTest,
+			filter.Status != "" && p.Status != filter.Status,
+			filter.Method != "" && p.Method != filter.Method,
+			filter.CustomerID != "" && p.CustomerID != filter.CustomerID,
+			filter.RefPrefix != "" && !strings.HasPrefix(p.Reference, filter.RefPrefix),
+			filter.Tag != "" && !hasTag(p.Tags, filter.Tag),
+			!filter.CreatedAfter.IsZero() && p.CreatedAt.Before(filter.CreatedAfter),
+			!filter.CreatedBefore.IsZero() && !p.CreatedAt.Before(filter.CreatedBefore):
+			continue
+		}
+		payment := copyPayment(p)
+		payment.Events, payment.LedgerEntries = nil, nil
+		payments = append(payments, payment)
+	}
+	return payments
+}
+
+// hasTag reports whether tags contains tag
+func hasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// paginate returns up to limit items after skipping offset; a limit of 0 keeps them all
+func paginate[T any](items []T, limit, offset int) []T {
+	if offset >= len(items) {
+		return nil
+	}
+	items = items[offset:]
+	if limit > 0 && limit < len(items) {
+		items = items[:limit]
+	}
+	return items
+}
+
+// fakePublisher is an output.EventPublisher that records what it is asked to publish
+// Setting err makes every publish fail with it instead
+type fakePublisher struct {
+	mu     sync.Mutex
+	events []core.DomainEvent
+	err    error
+}
+
+func (p *fakePublisher) Publish(event core.DomainEvent) error {
+	return p.PublishBatch([]core.DomainEvent{event})
+}
+
+func (p *fakePublisher) PublishBatch(events []core.DomainEvent) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.err != nil {
+		return p.err
+	}
+	p.events = append(p.events, events...)
+	return nil
+}
+
+func (p *fakePublisher) Close() error {
+	return nil
+}
+
+// published returns the events published so far
+func (p *fakePublisher) published() []core.DomainEvent {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]core.DomainEvent(nil), p.events...)
+}
+
+// fakeRefundRepository is an output.RefundRepository that records the refunds it is asked to create
+// without checking balances, for tests of what the service lets through
+type fakeRefundRepository struct {
+	mu      sync.Mutex
+	refunds []*core.Refund
+}
+
+func (r *fakeRefundRepository) CreateRefund(paymentID uuid.UUID, refundID string, amount float64) (*core.Refund, bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	refund := &core.Refund{
+		ID:        uuid.New(),
+		PaymentID: paymentID,
+		RefundID:  refundID,
+		Amount:    amount,
+		Status:    core.RefundStatusSuccess,
+	}
+	r.refunds = append(r.refunds, refund)
+	return refund, false, nil
+}
+
+func (r *fakeRefundRepository) RefundedTotal(paymentID uuid.UUID) (float64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var total float64
+	for _, refund := range r.refunds {
+		if refund.PaymentID == paymentID {
+			total += refund.Amount
+		}
+	}
+	return total, nil
+}
+
+func (r *fakeRefundRepository) ListByPaymentID(paymentID uuid.UUID) ([]*core.Refund, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var refunds []*core.Refund
+	for _, refund := range r.refunds {
+		if refund.PaymentID == paymentID {
+			refunds = append(refunds, refund)
+		}
+	}
+	return refunds, nil
+}
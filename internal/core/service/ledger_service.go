@@ -0,0 +1,59 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/cashflow/payment-gateway/internal/core"
+	"github.com/cashflow/payment-gateway/internal/port/input"
+	"github.com/cashflow/payment-gateway/internal/port/output"
+	"github.com/google/uuid"
+)
+
+// LedgerServiceImpl implements the LedgerService input port
+type LedgerServiceImpl struct {
+	paymentRepo output.PaymentRepository
+	ledgerRepo  output.LedgerRepository
+}
+
+// NewLedgerService creates a new ledger service
+func NewLedgerService(
+	paymentRepo output.PaymentRepository,
+	ledgerRepo output.LedgerRepository,
+) input.LedgerService {
+	return &LedgerServiceImpl{
+		paymentRepo: paymentRepo,
+		ledgerRepo:  ledgerRepo,
+	}
+}
+
+// GetPaymentLedger retrieves the ledger entries recorded for a payment
+func (s *LedgerServiceImpl) GetPaymentLedger(paymentID uuid.UUID) ([]input.LedgerEntryResponse, error) {
+	// Ensure the payment exists so unknown IDs are reported as not found
+	if _, err := s.paymentRepo.GetByID(paymentID); err != nil {
+		return nil, fmt.Errorf("failed to get payment: %w", err)
+	}
+
+	entries, err := s.ledgerRepo.ListByPaymentID(paymentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ledger: %w", err)
+	}
+
+	return toLedgerEntryResponses(entries), nil
+}
+
+// toLedgerEntryResponses converts core ledger entries to input port responses
+func toLedgerEntryResponses(entries []core.LedgerEntry) []input.LedgerEntryResponse {
+	responses := make([]input.LedgerEntryResponse, 0, len(entries))
+	for _, entry := range entries {
+		responses = append(responses, input.LedgerEntryResponse{
+			ID:        entry.ID,
+			PaymentID: entry.PaymentID,
+			Account:   entry.Account,
+			Direction: entry.Direction,
+			Amount:    entry.Amount,
+			Currency:  entry.Currency,
+			CreatedAt: entry.CreatedAt,
+		})
+	}
+	return responses
+}
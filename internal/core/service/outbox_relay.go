@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/cashflow/payment-gateway/internal/core"
+	"github.com/cashflow/payment-gateway/internal/port/output"
+	"github.com/google/uuid"
+)
+
+const (
+	// OutboxBatchSize is the number of unpublished payments a relay claims at a time
+	OutboxBatchSize = 100
+	// OutboxMinAge leaves a new payment to the create that is still publishing it; it must stay above PUBLISH_TIMEOUT
+	OutboxMinAge = time.Minute
+	// OutboxClaimLease is how long claimed payments are hidden from other relays before they are retried
+	OutboxClaimLease = time.Minute
+)
+
+// OutboxRelay publishes the payment.created messages of PENDING payments whose create could not,
+// e.g. because the broker was unreachable or the API stopped before publishing
+type OutboxRelay struct {
+	paymentRepo output.PaymentRepository
+	publisher   output.EventPublisher
+}
+
+// NewOutboxRelay creates a new outbox relay
+func NewOutboxRelay(paymentRepo output.PaymentRepository, publisher output.EventPublisher) *OutboxRelay {
+	return &OutboxRelay{
+		paymentRepo: paymentRepo,
+		publisher:   publisher,
+	}
+}
+
+// Run relays unpublished payments every interval until ctx is cancelled
+func (r *OutboxRelay) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		// Keep relaying while full batches come back, so a backlog drains without waiting a tick per batch
+		for {
+			n, err := r.RelayUnpublished()
+			if err != nil {
+				log.Printf("Failed to relay unpublished payments: %v", err)
+			}
+			if err != nil || n < OutboxBatchSize || ctx.Err() != nil {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RelayUnpublished publishes one batch of unpublished payments, returning how many were published
+// A failed publish leaves the batch leased, so it is retried once the lease runs out
+func (r *OutboxRelay) RelayUnpublished() (int, error) {
+	now := time.Now()
+	payments, err := r.paymentRepo.ClaimUnpublished(now, OutboxMinAge, OutboxClaimLease, OutboxBatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to claim unpublished payments: %w", err)
+	}
+	if len(payments) == 0 {
+		return 0, nil
+	}
+
+	events := make([]core.DomainEvent, 0, len(payments))
+	ids := make([]uuid.UUID, 0, len(payments))
+	for _, payment := range payments {
+		events = append(events, core.PaymentCreatedEvent(payment, now))
+		ids = append(ids, payment.ID)
+	}
+	if err := r.publisher.PublishBatch(events); err != nil {
+		return 0, fmt.Errorf("failed to publish %d payments: %w", len(events), err)
+	}
+
+	// A failure here only means the batch is published again; the worker ignores the duplicates
+	if err := r.paymentRepo.MarkPublished(ids); err != nil {
+		log.Printf("Failed to clear outbox entries of %d published payments: %v", len(ids), err)
+	}
+	log.Printf("Relayed %d unpublished payments", len(payments))
+	return len(payments), nil
+}
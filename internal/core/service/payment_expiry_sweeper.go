@@ -0,0 +1,80 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/cashflow/payment-gateway/internal/core"
+	"github.com/cashflow/payment-gateway/internal/port/output"
+)
+
+// ExpiryBatchSize is the number of payments expired per transaction
+const ExpiryBatchSize = 100
+
+// PaymentExpirySweeper moves PENDING payments past their expires_at to EXPIRED
+type PaymentExpirySweeper struct {
+	paymentRepo output.PaymentRepository
+	publisher   output.EventPublisher
+}
+
+// NewPaymentExpirySweeper creates a new payment expiry sweeper
+func NewPaymentExpirySweeper(
+	paymentRepo output.PaymentRepository,
+	publisher output.EventPublisher,
+) *PaymentExpirySweeper {
+	return &PaymentExpirySweeper{
+		paymentRepo: paymentRepo,
+		publisher:   publisher,
+	}
+}
+
+// Run sweeps expired payments every interval until ctx is cancelled
+func (s *PaymentExpirySweeper) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		// Keep sweeping while full batches come back, so a backlog drains without waiting a tick per batch
+		for {
+			n, err := s.SweepExpired()
+			if err != nil {
+				log.Printf("Failed to sweep expired payments: %v", err)
+			}
+			if err != nil || n < ExpiryBatchSize || ctx.Err() != nil {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// SweepExpired expires one batch of due payments, returning how many were expired
+// Which payments are due is decided by the database's clock, not this worker's
+// The status change is committed, with each payment's webhook delivery, before publishing, so a
+// publish failure is only logged
+func (s *PaymentExpirySweeper) SweepExpired() (int, error) {
+	payments, err := s.paymentRepo.ExpireDue(ExpiryBatchSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to expire payments: %w", err)
+	}
+
+	events := make([]core.DomainEvent, 0, len(payments))
+	for _, payment := range payments {
+		events = append(events, core.PaymentProcessedEvent(payment, core.PaymentStatusExpired, payment.UpdatedAt))
+	}
+	if err := s.publisher.PublishBatch(events); err != nil {
+		log.Printf("Failed to publish %d %s events: %v", len(events), core.PaymentStatusExpired, err)
+	}
+
+	for _, payment := range payments {
+		log.Printf("Payment %s expired", payment.ID)
+	}
+	return len(payments), nil
+}
@@ -1,8 +1,12 @@
 package service
 
 import (
+	"errors"
 	"fmt"
+	"log"
 	"math/rand"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
@@ -10,39 +14,258 @@ import (
 	"github.com/cashflow/payment-gateway/internal/port/output"
 )
 
+// Reference prefixes that script a test payment's outcome
+// Live payments ignore them and always go through the simulation
+const (
+	TestFailPrefix    = "FAIL-" // Test payment deterministically fails
+	TestSuccessPrefix = "OK-"   // Test payment deterministically succeeds
+)
+
+// ProcessResult reports how a payment was processed
+// A Duplicate result means the message was redelivered for a payment that was already
+// processed: nothing was applied, published or enqueued, and Status is the payment's current status
+type ProcessResult struct {
+	Status    core.PaymentStatus // The status actually applied
+	Reason    string             // Why, as recorded on the payment's status event
+	Duplicate bool
+}
+
 // PaymentProcessor handles payment processing business logic
 type PaymentProcessor struct {
 	paymentRepo output.PaymentRepository
+	publisher   output.EventPublisher
+	clock       core.Clock
+	maxAttempts int
 }
 
 // NewPaymentProcessor creates a new payment processor
-func NewPaymentProcessor(paymentRepo output.PaymentRepository) *PaymentProcessor {
+// clock stamps the processed events; expiry itself is decided by the database's clock
+// maxAttempts is how many deliveries may start processing a payment before it is failed
+func NewPaymentProcessor(
+	paymentRepo output.PaymentRepository,
+	publisher output.EventPublisher,
+	clock core.Clock,
+	maxAttempts int,
+) *PaymentProcessor {
 	return &PaymentProcessor{
 		paymentRepo: paymentRepo,
+		publisher:   publisher,
+		clock:       clock,
+		maxAttempts: maxAttempts,
 	}
 }
 
 // ProcessPayment processes a payment asynchronously
 // This simulates payment processing and randomly assigns SUCCESS or FAILED status
+// Test payments skip the simulation and deterministically succeed, unless their
+// reference starts with TestFailPrefix, in which case they deterministically fail
 // The processing is idempotent - it only processes payments in PENDING status
-func (p *PaymentProcessor) ProcessPayment(paymentID uuid.UUID) error {
-	// Randomly determine success or failure (50/50 chance)
-	rand.Seed(time.Now().UnixNano())
-	status := core.PaymentStatusFailed
-	if rand.Float32() < 0.5 {
-		status = core.PaymentStatusSuccess
+// Auth-only payments are moved to AUTHORIZED instead of SUCCESS and settled by a later capture
+// Payments past their expires_at are moved to EXPIRED instead of SUCCESS or FAILED
+// Expiry is decided by the repository against the database's clock, never against this
+// worker's clock or the message's timestamp, which may be skewed
+// The outcome and its reason are recorded on the payment's status event and returned
+// A redelivered message for a payment that is no longer PENDING is reported as a Duplicate
+// result rather than an error, and publishes no second event or webhook
+// Every delivery that starts processing counts an attempt on the payment; once maxAttempts are
+// used up, e.g. by failures that kept requeueing it, the payment is failed with core.NoteMaxAttempts
+// and the result is returned without an error, so the message is acknowledged instead of requeued
+func (p *PaymentProcessor) ProcessPayment(paymentID uuid.UUID) (ProcessResult, error) {
+	payment, err := p.paymentRepo.GetByID(paymentID)
+	if err != nil {
+		return ProcessResult{}, fmt.Errorf("failed to process payment: %w", err)
+	}
+	if payment.Status != core.PaymentStatusPending {
+		return ProcessResult{Status: payment.Status, Duplicate: true}, nil
 	}
 
-	// Simulate processing time
-	time.Sleep(time.Duration(rand.Intn(1000)+500) * time.Millisecond)
+	// Count the attempt before doing any work, so a crash mid-attempt still uses up the budget
+	payment, err = p.paymentRepo.StartAttempt(paymentID, p.maxAttempts)
+	if errors.Is(err, core.ErrPaymentAlreadyProcessed) {
+		return p.duplicateResult(paymentID), nil
+	}
+	if err != nil {
+		return ProcessResult{}, fmt.Errorf("failed to process payment: %w", err)
+	}
+	if payment.Status != core.PaymentStatusPending {
+		// The attempt budget was spent and the payment failed (or expired) instead
+		return p.complete(payment, payment.Status, gaveUpReason(payment)), nil
+	}
+
+	status, reason := decideOutcome(payment)
 
 	// Atomically update payment status
-	// This uses SELECT FOR UPDATE to prevent concurrent processing
-	err := p.paymentRepo.ProcessPayment(paymentID, status)
+	// This uses SELECT FOR UPDATE to prevent concurrent processing, and checks expiry
+	// under the lock, so the applied status may be EXPIRED even if status is not
+	applied, err := p.paymentRepo.ProcessPayment(paymentID, status, reason)
+	if errors.Is(err, core.ErrPaymentAlreadyProcessed) {
+		// Another delivery of the same message won the row lock while this one was simulating
+		return p.duplicateResult(paymentID), nil
+	}
+	if err != nil {
+		return ProcessResult{}, fmt.Errorf("failed to process payment: %w", err)
+	}
+	if applied != status {
+		status, reason = applied, core.NoteExpired
+	}
+	return p.complete(payment, status, reason), nil
+}
+
+// BatchResult is how one payment of a batch was processed; Err is set when its message must be
+// handled like a ProcessPayment error
+type BatchResult struct {
+	ProcessResult
+	Err error
+}
+
+// ProcessBatch processes the payments like ProcessPayment, but counts all their attempts in one
+// transaction and applies all their statuses in another, instead of two transactions per payment
+// The simulated gateway calls run concurrently. Results line up with ids; the second copy of an ID
+// redelivered within the batch is reported as a Duplicate
+// The PENDING guard is still checked per row, so a payment another worker settled meanwhile is a
+// Duplicate rather than a failure. A failed transaction fails every payment it covered, so their
+// messages are retried together
+func (p *PaymentProcessor) ProcessBatch(ids []uuid.UUID) []BatchResult {
+	results := make([]BatchResult, len(ids))
+
+	// Count the attempts before doing any work, so a crash mid-batch still uses up the budgets
+	started, err := p.paymentRepo.StartAttempts(ids, p.maxAttempts)
+	if err != nil {
+		err = fmt.Errorf("failed to process payments: %w", err)
+		for i := range results {
+			results[i].Err = err
+		}
+		return results
+	}
+
+	outcomes := make(map[uuid.UUID]BatchResult, len(started))
+	var pending []*core.Payment
+	for _, payment := range started {
+		if payment.Status != core.PaymentStatusPending {
+			// The attempt budget was spent and the payment failed (or expired) instead
+			outcomes[payment.ID] = BatchResult{ProcessResult: p.complete(payment, payment.Status, gaveUpReason(payment))}
+			continue
+		}
+		pending = append(pending, payment)
+	}
+
+	// Decide every outcome concurrently, then apply them together
+	updates := make([]output.StatusUpdate, len(pending))
+	var wg sync.WaitGroup
+	for i, payment := range pending {
+		wg.Add(1)
+		go func(i int, payment *core.Payment) {
+			defer wg.Done()
+			status, reason := decideOutcome(payment)
+			updates[i] = output.StatusUpdate{PaymentID: payment.ID, Status: status, Note: reason}
+		}(i, payment)
+	}
+	wg.Wait()
+
+	if len(updates) > 0 {
+		applied, err := p.paymentRepo.ProcessPayments(updates)
+		for i, update := range updates {
+			if err != nil {
+				outcomes[update.PaymentID] = BatchResult{Err: fmt.Errorf("failed to process payment: %w", err)}
+				continue
+			}
+			status, ok := applied[update.PaymentID]
+			if !ok {
+				continue // Another delivery won the row lock while this one was simulating
+			}
+			reason := update.Note
+			if status != update.Status {
+				reason = core.NoteExpired
+			}
+			outcomes[update.PaymentID] = BatchResult{ProcessResult: p.complete(pending[i], status, reason)}
+		}
+	}
+
+	for i, id := range ids {
+		if outcome, ok := outcomes[id]; ok {
+			results[i] = outcome
+			delete(outcomes, id)
+			continue
+		}
+		// Not PENDING when the batch locked it, or settled by another delivery since
+		results[i] = p.batchDuplicate(id)
+	}
+	return results
+}
+
+// batchDuplicate reports a payment of a batch that was not processed because it is no longer
+// PENDING, or fails it like ProcessPayment when the payment can't be read
+func (p *PaymentProcessor) batchDuplicate(paymentID uuid.UUID) BatchResult {
+	payment, err := p.paymentRepo.GetByID(paymentID)
 	if err != nil {
-		return fmt.Errorf("failed to process payment: %w", err)
+		return BatchResult{Err: fmt.Errorf("failed to process payment: %w", err)}
+	}
+	return BatchResult{ProcessResult: ProcessResult{Status: payment.Status, Duplicate: true}}
+}
+
+// decideOutcome runs the (simulated) gateway call for a PENDING payment and returns the status
+// it should move to, with the reason recorded on its status event
+func decideOutcome(payment *core.Payment) (core.PaymentStatus, string) {
+	var status core.PaymentStatus
+	var reason string
+	if payment.IsTest {
+		status, reason = testPaymentOutcome(payment.Reference)
+	} else {
+		// Randomly determine success or failure (50/50 chance)
+		rand.Seed(time.Now().UnixNano())
+		status, reason = core.PaymentStatusFailed, core.NoteGatewayDeclined
+		if rand.Float32() < 0.5 {
+			status, reason = core.PaymentStatusSuccess, core.NoteGatewayApproved
+		}
+
+		// Simulate processing time
+		time.Sleep(time.Duration(rand.Intn(1000)+500) * time.Millisecond)
 	}
 
-	return nil
+	// Auth-only payments are authorized instead of settled and wait for a capture
+	if payment.AuthOnly && status == core.PaymentStatusSuccess {
+		status, reason = core.PaymentStatusAuthorized, core.NoteGatewayAuthorized
+		if payment.IsTest {
+			reason = core.NoteTestAuthorized
+		}
+	}
+	return status, reason
+}
+
+// gaveUpReason explains the status StartAttempt gave a payment whose attempt budget was spent
+func gaveUpReason(payment *core.Payment) string {
+	if payment.Status == core.PaymentStatusExpired {
+		return core.NoteExpired
+	}
+	return core.NoteMaxAttempts
+}
+
+// complete publishes the outcome event for a payment whose new status was just committed, and
+// returns its result; the merchant webhook was stored by the repository with the status
+func (p *PaymentProcessor) complete(payment *core.Payment, status core.PaymentStatus, reason string) ProcessResult {
+	// The status is already committed, so a publish failure is logged rather than
+	// returned (returning would requeue a message that can no longer be processed)
+	if err := p.publisher.Publish(core.PaymentProcessedEvent(payment, status, p.clock.Now())); err != nil {
+		log.Printf("Failed to publish %s event for payment %s: %v", status, payment.ID, err)
+	}
+	return ProcessResult{Status: status, Reason: reason}
+}
+
+// duplicateResult reports a duplicate delivery with the payment's current status,
+// which is left empty if the payment can't be re-read
+func (p *PaymentProcessor) duplicateResult(paymentID uuid.UUID) ProcessResult {
+	result := ProcessResult{Duplicate: true}
+	if payment, err := p.paymentRepo.GetByID(paymentID); err == nil {
+		result.Status = payment.Status
+	}
+	return result
 }
 
+// testPaymentOutcome returns the scripted status for a test payment's reference, with its reason
+// References with neither prefix succeed, as do those starting with TestSuccessPrefix
+func testPaymentOutcome(reference string) (core.PaymentStatus, string) {
+	if strings.HasPrefix(reference, TestFailPrefix) {
+		return core.PaymentStatusFailed, core.NoteTestFailed
+	}
+	return core.PaymentStatusSuccess, core.NoteTestSucceeded
+}
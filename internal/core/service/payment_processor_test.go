@@ -0,0 +1,52 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cashflow/payment-gateway/internal/core"
+	"github.com/cashflow/payment-gateway/internal/port/input"
+)
+
+func TestProcessPaymentExpiresByClock(t *testing.T) {
+	tests := []struct {
+		name       string
+		elapsed    time.Duration
+		wantStatus core.PaymentStatus
+	}{
+		{name: "before expires_at", elapsed: time.Minute - time.Nanosecond, wantStatus: core.PaymentStatusSuccess},
+		{name: "at expires_at", elapsed: time.Minute, wantStatus: core.PaymentStatusExpired},
+		{name: "after expires_at", elapsed: time.Hour, wantStatus: core.PaymentStatusExpired},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := core.NewFakeClock(time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC))
+			repo := newMemoryPaymentRepository(clock)
+			publisher := &fakePublisher{}
+			created, err := newTestPaymentService(repo, publisher, nil).CreatePayment(input.CreatePaymentRequest{
+				MerchantID: "merchant-1",
+				Amount:     100,
+				Currency:   core.CurrencyETB,
+				Reference:  "OK-EXPIRY",
+				IsTest:     true,
+				TTLSeconds: 60,
+			})
+			if err != nil {
+				t.Fatalf("CreatePayment: %v", err)
+			}
+
+			clock.Advance(tt.elapsed)
+			result, err := NewPaymentProcessor(repo, publisher, clock, 3).ProcessPayment(created.ID)
+			if err != nil {
+				t.Fatalf("ProcessPayment: %v", err)
+			}
+
+			if result.Status != tt.wantStatus {
+				t.Errorf("status: got %s, want %s", result.Status, tt.wantStatus)
+			}
+			if tt.wantStatus == core.PaymentStatusExpired && result.Reason != core.NoteExpired {
+				t.Errorf("reason: got %q, want %q", result.Reason, core.NoteExpired)
+			}
+		})
+	}
+}
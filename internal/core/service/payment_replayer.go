@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cashflow/payment-gateway/internal/core"
+	"github.com/cashflow/payment-gateway/internal/port/output"
+	"github.com/google/uuid"
+	"golang.org/x/time/rate"
+)
+
+// ReplayBatchSize is the number of payments a replay reads, and for FAILED payments resets, at a time
+const ReplayBatchSize = 100
+
+// ReplayRequest selects the payments a replay re-publishes
+type ReplayRequest struct {
+	// Status is PENDING, whose payments are re-published as they are, or FAILED, whose payments
+	// are reset to PENDING first when core.Payment.CanReprocessAt allows it, like an admin reprocess
+	Status        core.PaymentStatus
+	CreatedAfter  time.Time            // Inclusive
+	CreatedBefore time.Time            // Exclusive
+	After         output.PaymentCursor // Resume after this payment; zero starts at CreatedAfter
+	DryRun        bool                 // Count what would be replayed without changing or publishing anything
+}
+
+// ReplayProgress is a replay's running totals, reported after every batch
+type ReplayProgress struct {
+	Scanned   int                  // Payments read in the window
+	Published int                  // payment.created messages published (or that would be, in a dry run)
+	Skipped   int                  // FAILED payments that may no longer be reprocessed
+	Cursor    output.PaymentCursor // Last payment handled; pass it as After to resume
+}
+
+// PaymentReplayer re-publishes payment.created messages for the payments created in a window,
+// so the worker processes them again after a bug fix
+// Replays are safe to repeat: the worker only processes PENDING payments, so a message for a
+// payment that was already processed is acknowledged as a duplicate
+type PaymentReplayer struct {
+	paymentRepo output.PaymentRepository
+	publisher   output.EventPublisher
+	limiter     *rate.Limiter
+}
+
+// NewPaymentReplayer creates a replayer publishing at most perSecond messages per second
+func NewPaymentReplayer(paymentRepo output.PaymentRepository, publisher output.EventPublisher, perSecond float64) *PaymentReplayer {
+	return &PaymentReplayer{
+		paymentRepo: paymentRepo,
+		publisher:   publisher,
+		limiter:     rate.NewLimiter(rate.Limit(perSecond), 1),
+	}
+}
+
+// Run replays the selected payments oldest first until the window is exhausted, ctx is cancelled
+// or a step fails, calling progress after every batch
+// On error the returned progress's cursor ends the last batch fully handled; resuming from it
+// repeats at most part of one batch. A FAILED replay that stops mid-batch leaves that batch's
+// reset payments PENDING, so follow it with a PENDING replay of the window
+func (r *PaymentReplayer) Run(ctx context.Context, req ReplayRequest, progress func(ReplayProgress)) (ReplayProgress, error) {
+	if req.Status != core.PaymentStatusPending && req.Status != core.PaymentStatusFailed {
+		return ReplayProgress{}, fmt.Errorf("%w: status must be PENDING or FAILED", core.ErrInvalidParameter)
+	}
+	if req.CreatedAfter.IsZero() || req.CreatedBefore.IsZero() || !req.CreatedAfter.Before(req.CreatedBefore) {
+		return ReplayProgress{}, fmt.Errorf("%w: created_after must be before created_before", core.ErrInvalidParameter)
+	}
+
+	filter := output.PaymentFilter{
+		Status:        req.Status,
+		CreatedAfter:  req.CreatedAfter,
+		CreatedBefore: req.CreatedBefore,
+		Limit:         ReplayBatchSize,
+	}
+	done := ReplayProgress{Cursor: req.After}
+	for {
+		batch, err := r.paymentRepo.ListAfter(filter, done.Cursor)
+		if err != nil {
+			return done, fmt.Errorf("failed to list payments: %w", err)
+		}
+		if len(batch) == 0 {
+			return done, nil
+		}
+
+		replay, skipped, err := r.prepare(req, batch)
+		if err != nil {
+			return done, err
+		}
+		if !req.DryRun {
+			for _, payment := range replay {
+				if err := r.publish(ctx, payment); err != nil {
+					return done, err
+				}
+			}
+		}
+
+		done.Scanned += len(batch)
+		done.Published += len(replay)
+		done.Skipped += skipped
+		done.Cursor = output.CursorOf(batch[len(batch)-1])
+		progress(done)
+	}
+}
+
+// prepare returns the batch's payments to publish, resetting eligible FAILED payments to PENDING,
+// and how many were skipped as no longer eligible
+func (r *PaymentReplayer) prepare(req ReplayRequest, batch []*core.Payment) ([]*core.Payment, int, error) {
+	if req.Status == core.PaymentStatusPending {
+		return batch, 0, nil
+	}
+
+	now := time.Now()
+	eligible := make([]*core.Payment, 0, len(batch))
+	for _, payment := range batch {
+		if payment.CanReprocessAt(now) {
+			eligible = append(eligible, payment)
+		}
+	}
+	if req.DryRun || len(eligible) == 0 {
+		return eligible, len(batch) - len(eligible), nil
+	}
+
+	ids := make([]uuid.UUID, 0, len(eligible))
+	for _, payment := range eligible {
+		ids = append(ids, payment.ID)
+	}
+	// The repository re-checks eligibility under the row lock, so the reset set may be smaller
+	reset, err := r.paymentRepo.ResetForReprocess(ids, core.NoteReplay)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to reset payments: %w", err)
+	}
+	return reset, len(batch) - len(reset), nil
+}
+
+// publish waits for the rate limit, then publishes the payment's payment.created message
+func (r *PaymentReplayer) publish(ctx context.Context, payment *core.Payment) error {
+	if err := r.limiter.Wait(ctx); err != nil {
+		return fmt.Errorf("replay interrupted: %w", err)
+	}
+	err := r.publisher.Publish(core.PaymentCreated{
+		PaymentID:  payment.ID,
+		MerchantID: payment.MerchantID,
+		Amount:     payment.Amount,
+		Currency:   payment.Currency,
+		IsTest:     payment.IsTest,
+		Source:     payment.Source,
+		OccurredAt: time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to publish payment %s: %w", payment.ID, err)
+	}
+	return nil
+}
@@ -1,8 +1,11 @@
 package service
 
 import (
+	"errors"
 	"fmt"
+	"log"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/cashflow/payment-gateway/internal/core"
@@ -10,80 +13,386 @@ import (
 	"github.com/cashflow/payment-gateway/internal/port/output"
 )
 
+const (
+	DefaultListLimit        = 20  // Page size when the client does not specify one
+	MaxListLimit            = 100 // Upper bound on page size to keep list queries cheap
+	MaxIdempotencyKeyLength = 255 // Upper bound on a client-supplied Idempotency-Key
+	MaxReferenceAttempts    = 3   // Generated references drawn for one create before a collision is reported
+)
+
+// DefaultCurrencies maps merchant IDs to the currency used when a create request omits one
+// The "*" entry is used for merchants without their own default
+type DefaultCurrencies map[string]core.Currency
+
+// For returns the default currency for a merchant, or "" if none is configured
+func (d DefaultCurrencies) For(merchantID string) core.Currency {
+	if currency, ok := d[merchantID]; ok {
+		return currency
+	}
+	return d["*"]
+}
+
+// ReferenceDedupWindows maps merchant IDs to how long a create repeating an earlier payment's
+// reference returns that payment instead of a conflict; zero or missing disables it
+// The "*" entry is used for merchants without their own window
+type ReferenceDedupWindows map[string]time.Duration
+
+// For returns the reference dedup window for a merchant, or 0 if none is configured
+func (w ReferenceDedupWindows) For(merchantID string) time.Duration {
+	if window, ok := w[merchantID]; ok {
+		return window
+	}
+	return w["*"]
+}
+
+// DailyLimits maps merchant IDs to the most their payments in one currency may add up to per UTC day;
+// zero or missing leaves the merchant unlimited
+// The "*" entry is used for merchants without their own limit
+type DailyLimits map[string]float64
+
+// For returns the daily amount limit for a merchant, or 0 if none is configured
+func (d DailyLimits) For(merchantID string) float64 {
+	if limit, ok := d[merchantID]; ok {
+		return limit
+	}
+	return d["*"]
+}
+
+// PublishFailureMode decides what a create does when the payment's payment.created message is not confirmed
+type PublishFailureMode string
+
+const (
+	// PublishFailOpen keeps the payment and returns it unenqueued; the outbox relay publishes it later
+	PublishFailOpen PublishFailureMode = "fail_open"
+	// PublishFailClosed deletes the payment again and fails the create with core.ErrPaymentNotEnqueued
+	PublishFailClosed PublishFailureMode = "fail_closed"
+)
+
 // PaymentServiceImpl implements the PaymentService input port
 type PaymentServiceImpl struct {
-	paymentRepo output.PaymentRepository
-	paymentMsg  output.PaymentMessaging
+	paymentRepo       output.PaymentRepository
+	publisher         output.EventPublisher
+	validator         Validator
+	idempotency       output.IdempotencyStore
+	newID             core.IDGenerator
+	newReference      core.ReferenceGenerator
+	clock             core.Clock
+	defaultCurrencies DefaultCurrencies
+	dedupWindows      ReferenceDedupWindows
+	dailyLimits       DailyLimits
+	risk              output.RiskEvaluator
+	publishFailure    PublishFailureMode
+}
+
+// PaymentServiceOptions holds the optional dependencies and settings of a payment service
+// The zero value of every field selects the default noted on it
+type PaymentServiceOptions struct {
+	// Idempotency reserves Idempotency-Key values; it must be set to serve requests that send one
+	Idempotency output.IdempotencyStore
+	// NewID generates the IDs of created payments; nil uses core.NewRandomID
+	NewID core.IDGenerator
+	// NewReference generates the references of requests with GenerateReference; nil uses core.DefaultReferencePrefix
+	NewReference core.ReferenceGenerator
+	// Clock stamps the events the service publishes; nil uses the system clock
+	Clock core.Clock
+	// DefaultCurrencies fills in the currency of requests that omit it; nil requires it on every request
+	DefaultCurrencies DefaultCurrencies
+	// DedupWindows lets merchants retry with a reference instead of an Idempotency-Key; nil disables it
+	DedupWindows ReferenceDedupWindows
+	// DailyLimits caps what each merchant's payments may add up to per day; nil leaves every merchant unlimited
+	DailyLimits DailyLimits
+	// Risk decides whether new payments are processed right away, held for review or declined; nil approves every payment
+	Risk output.RiskEvaluator
+	// PublishFailure decides what a create does when publishing fails; "" uses PublishFailOpen
+	PublishFailure PublishFailureMode
 }
 
 // NewPaymentService creates a new payment service
+// validator checks and normalizes create requests, both for creating and for dry-run validation
 func NewPaymentService(
 	paymentRepo output.PaymentRepository,
-	paymentMsg output.PaymentMessaging,
+	publisher output.EventPublisher,
+	validator Validator,
+	opts PaymentServiceOptions,
 ) input.PaymentService {
+	if opts.NewID == nil {
+		opts.NewID = core.NewRandomID
+	}
+	if opts.NewReference == nil {
+		opts.NewReference = core.NewReferenceGenerator(core.DefaultReferencePrefix)
+	}
+	if opts.Clock == nil {
+		opts.Clock = core.SystemClock{}
+	}
+	if opts.PublishFailure == "" {
+		opts.PublishFailure = PublishFailOpen
+	}
 	return &PaymentServiceImpl{
-		paymentRepo: paymentRepo,
-		paymentMsg:  paymentMsg,
+		paymentRepo:       paymentRepo,
+		publisher:         publisher,
+		validator:         validator,
+		idempotency:       opts.Idempotency,
+		newID:             opts.NewID,
+		newReference:      opts.NewReference,
+		clock:             opts.Clock,
+		defaultCurrencies: opts.DefaultCurrencies,
+		dedupWindows:      opts.DedupWindows,
+		dailyLimits:       opts.DailyLimits,
+		risk:              opts.Risk,
+		publishFailure:    opts.PublishFailure,
 	}
 }
 
 // CreatePayment creates a new payment
+// With an idempotency key, a retry returns the payment the first request created (Replayed)
+// instead of creating another, and a retry while the first request is in flight is rejected
 func (s *PaymentServiceImpl) CreatePayment(req input.CreatePaymentRequest) (*input.PaymentResponse, error) {
-	// Validate amount
-	if req.Amount <= 0 {
-		return nil, fmt.Errorf("amount must be greater than zero")
+	if req.IdempotencyKey == "" {
+		return s.createPayment(req)
+	}
+	if len(req.IdempotencyKey) > MaxIdempotencyKeyLength {
+		return nil, fmt.Errorf("%w: must be at most %d characters", core.ErrInvalidIdempotencyKey, MaxIdempotencyKeyLength)
 	}
 
-	// Validate currency
-	if req.Currency != core.CurrencyETB && req.Currency != core.CurrencyUSD {
-		return nil, fmt.Errorf("currency must be ETB or USD")
+	// Keys are scoped per merchant, so merchants can't collide with each other's keys
+	key := req.MerchantID + ":" + req.IdempotencyKey
+	reserved, paymentID, err := s.idempotency.Reserve(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create payment: %w", err)
+	}
+	if !reserved {
+		return s.replayPayment(req, paymentID)
 	}
 
-	// Validate reference
-	req.Reference = strings.TrimSpace(req.Reference)
-	if req.Reference == "" {
-		return nil, fmt.Errorf("reference is required")
+	response, err := s.createPayment(req)
+	switch {
+	case response != nil:
+		// The payment is stored, even if publishing it failed; retries must return it
+		if completeErr := s.idempotency.Complete(key, response.ID); completeErr != nil {
+			log.Printf("Failed to record idempotency key for payment %s: %v", response.ID, completeErr)
+		}
+	default:
+		// Nothing was stored, so let the client retry with the same key
+		if releaseErr := s.idempotency.Release(key); releaseErr != nil {
+			log.Printf("Failed to release idempotency key: %v", releaseErr)
+		}
+	}
+	return response, err
+}
+
+// replayPayment returns the payment an earlier request with the same idempotency key created
+// paymentID is uuid.Nil while that request is still in flight
+func (s *PaymentServiceImpl) replayPayment(req input.CreatePaymentRequest, paymentID uuid.UUID) (*input.PaymentResponse, error) {
+	if paymentID == uuid.Nil {
+		return nil, core.ErrIdempotencyKeyInUse
 	}
 
-	// Check if reference already exists
-	exists, err := s.paymentRepo.ReferenceExists(req.Reference)
+	start := time.Now()
+	payment, err := s.paymentRepo.GetByID(paymentID)
+	req.Timings.AddDB(start)
 	if err != nil {
-		return nil, fmt.Errorf("failed to validate reference: %w", err)
+		return nil, fmt.Errorf("failed to replay payment: %w", err)
+	}
+
+	// A key reused for a different payment is a client bug, not a retry
+	// A retry asking for a generated reference can't know it, so only an explicit one is compared
+	if !generatesReference(req) && payment.Reference != strings.TrimSpace(req.Reference) {
+		return nil, fmt.Errorf("%w: it created payment %s with reference %s", core.ErrIdempotencyKeyReused, payment.ID, payment.Reference)
+	}
+
+	response := toPaymentResponse(payment)
+	response.Replayed = true
+	return response, nil
+}
+
+// replayByReference returns the payment an earlier request with the same reference created, if the
+// merchant has a dedup window, the payment is theirs, was created within the window and has the
+// same amount and currency; otherwise it returns nil and the request is handled as a new payment,
+// so a duplicate reference is still reported as a conflict
+func (s *PaymentServiceImpl) replayByReference(req input.CreatePaymentRequest) (*input.PaymentResponse, error) {
+	window := s.dedupWindows.For(req.MerchantID)
+	reference := strings.TrimSpace(req.Reference)
+	if window <= 0 || reference == "" {
+		return nil, nil
+	}
+
+	start := time.Now()
+	payment, err := s.paymentRepo.GetByReference(reference)
+	req.Timings.AddDB(start)
+	if errors.Is(err, core.ErrPaymentNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to replay payment: %w", err)
+	}
+
+	if payment.MerchantID != req.MerchantID ||
+		s.clock.Now().Sub(payment.CreatedAt) > window ||
+		payment.Currency != req.Currency ||
+		core.FormatAmount(payment.Amount, payment.Currency) != core.FormatAmount(req.Amount, req.Currency) {
+		return nil, nil
 	}
-	if exists {
-		return nil, fmt.Errorf("reference already exists")
+
+	response := toPaymentResponse(payment)
+	response.Replayed = true
+	return response, nil
+}
+
+// createPayment validates, stores and enqueues a payment
+// The response is non-nil whenever the payment was stored, even if an error is also returned
+func (s *PaymentServiceImpl) createPayment(req input.CreatePaymentRequest) (*input.PaymentResponse, error) {
+	s.applyDefaultCurrency(&req)
+	// A generated reference is new, so there is no earlier payment to replay
+	generated := generatesReference(req)
+	if generated {
+		req.Reference = s.newReference()
+	} else if response, err := s.replayByReference(req); response != nil || err != nil {
+		return response, err
+	}
+	if err := s.validator.Validate(&req); err != nil {
+		return nil, err
 	}
 
 	// Create payment entity
 	payment := &core.Payment{
-		ID:        uuid.New(),
-		Amount:    req.Amount,
-		Currency:  req.Currency,
-		Reference: req.Reference,
-		Status:  core.PaymentStatusPending,
+		ID:            s.newID(),
+		MerchantID:    req.MerchantID,
+		Amount:        req.Amount,
+		Currency:      req.Currency,
+		Reference:     req.Reference,
+		Description:   req.Description,
+		CustomerID:    req.CustomerID,
+		CustomerEmail: req.CustomerEmail,
+		Status:        core.PaymentStatusPending,
+		Source:        req.Source,
+		Method:        req.Method,
+		AuthOnly:      req.AuthOnly,
+		IsTest:        req.IsTest,
+		Tags:          req.Tags,
+		ExpiresAt:     req.ExpiresAt,
+
+		ParentPaymentID: req.ParentPaymentID,
+	}
+
+	if payment.AuthOnly {
+		payment.AuthorizedAmount = payment.Amount
+	}
+
+	// A payment the risk rules hold is stored in REVIEW and only enqueued once an operator approves it;
+	// a declined one is never stored
+	if s.risk != nil {
+		decision, err := s.risk.Evaluate(payment)
+		if err != nil {
+			return nil, fmt.Errorf("failed to evaluate payment risk: %w", err)
+		}
+		switch decision.Outcome {
+		case core.RiskDecline:
+			if decision.Reason == nil {
+				return nil, core.ErrPaymentDeclined
+			}
+			return nil, decision.Reason
+		case core.RiskReview:
+			payment.Status = core.PaymentStatusReview
+			if decision.Reason != nil {
+				log.Printf("Payment %s held for review: %v", payment.ID, decision.Reason)
+			}
+		}
 	}
 
 	// Save payment
-	if err := s.paymentRepo.Create(payment); err != nil {
+	start := time.Now()
+	err := s.storePayment(payment)
+	// A generated reference that is already taken says nothing about the request; draw another
+	for attempt := 1; generated && errors.Is(err, core.ErrReferenceExists) && attempt < MaxReferenceAttempts; attempt++ {
+		log.Printf("Generated reference %s already exists, generating another", payment.Reference)
+		payment.Reference = s.newReference()
+		err = s.storePayment(payment)
+	}
+	req.Timings.AddDB(start)
+	if err != nil {
+		// A concurrent retry with the same reference may have won the insert
+		if !generated && errors.Is(err, core.ErrReferenceExists) {
+			if response, replayErr := s.replayByReference(req); response != nil && replayErr == nil {
+				return response, nil
+			}
+		}
 		return nil, fmt.Errorf("failed to create payment: %w", err)
 	}
+	if payment.InReview() {
+		return toPaymentResponse(payment), nil
+	}
 
-	// Publish message to queue (non-blocking - log error but don't fail)
-	if err := s.paymentMsg.PublishPaymentMessage(payment.ID); err != nil {
-		// In production, you might want to implement a retry mechanism or dead letter queue
-		// For now, we log the error but don't fail the request since payment is already created
-		return nil, fmt.Errorf("payment created but failed to publish message: %w", err)
+	// Publish the created event, which queues the payment for processing
+	start = time.Now()
+	err = s.publisher.Publish(core.PaymentCreatedEvent(payment, s.clock.Now()))
+	req.Timings.AddPublish(start)
+	if err != nil {
+		return s.publishFailed(payment, err)
 	}
 
+	// The broker confirmed the message, so the outbox relay must not republish it
+	start = time.Now()
+	if err := s.paymentRepo.MarkPublished([]uuid.UUID{payment.ID}); err != nil {
+		// The relay republishes it later, and the worker ignores the duplicate
+		log.Printf("Failed to clear outbox entry of payment %s: %v", payment.ID, err)
+	}
+	req.Timings.AddDB(start)
+
 	// Return response
-	return &input.PaymentResponse{
-		ID:        payment.ID,
-		Amount:    payment.Amount,
-		Currency:  payment.Currency,
-		Reference: payment.Reference,
-		Status:    payment.Status,
-		CreatedAt: payment.CreatedAt,
-	}, nil
+	response := toPaymentResponse(payment)
+	response.Enqueued = true
+	return response, nil
+}
+
+// publishFailed finishes a create whose payment.created message was not confirmed, as the
+// publish failure mode says
+// Failing open keeps the payment PENDING and returns it unenqueued; its outbox entry makes the
+// worker's relay publish it later. Failing closed deletes the payment again and returns
+// core.ErrPaymentNotEnqueued, so nothing is left behind for a retry to trip over. If the payment
+// can't be deleted, e.g. because the message got through after all and the worker picked it up,
+// it is accepted as if failing open
+func (s *PaymentServiceImpl) publishFailed(payment *core.Payment, publishErr error) (*input.PaymentResponse, error) {
+	if s.publishFailure == PublishFailClosed {
+		err := s.paymentRepo.DeleteUnpublished(payment.ID)
+		if err == nil {
+			log.Printf("Payment %s rolled back: %v", payment.ID, publishErr)
+			return nil, fmt.Errorf("%w: %w", core.ErrPaymentNotEnqueued, publishErr)
+		}
+		log.Printf("Failed to roll back payment %s, accepting it instead: %v", payment.ID, err)
+	}
+	log.Printf("Payment %s accepted without enqueueing, left to the outbox relay: %v", payment.ID, publishErr)
+	return toPaymentResponse(payment), nil
+}
+
+// storePayment inserts the payment, enforcing the merchant's daily limit in the same transaction
+func (s *PaymentServiceImpl) storePayment(payment *core.Payment) error {
+	if limit := s.dailyLimits.For(payment.MerchantID); limit > 0 {
+		return s.paymentRepo.CreateWithinDailyLimit(payment, limit)
+	}
+	return s.paymentRepo.Create(payment)
+}
+
+// ValidatePayment runs all of CreatePayment's validation without persisting or publishing anything
+func (s *PaymentServiceImpl) ValidatePayment(req input.CreatePaymentRequest) error {
+	s.applyDefaultCurrency(&req)
+	if generatesReference(req) {
+		req.Reference = s.newReference()
+	}
+	return s.validator.Validate(&req)
+}
+
+// generatesReference reports whether the request leaves its reference to the service
+// A reference the client does send always wins over the flag
+func generatesReference(req input.CreatePaymentRequest) bool {
+	return req.GenerateReference && strings.TrimSpace(req.Reference) == ""
+}
+
+// applyDefaultCurrency sets the merchant's default currency on a request that omits one
+// Without a default the currency stays empty and fails validation as before
+func (s *PaymentServiceImpl) applyDefaultCurrency(req *input.CreatePaymentRequest) {
+	if req.Currency == "" {
+		req.Currency = s.defaultCurrencies.For(req.MerchantID)
+	}
 }
 
 // GetPayment retrieves a payment by ID
@@ -93,13 +402,286 @@ func (s *PaymentServiceImpl) GetPayment(id uuid.UUID) (*input.PaymentResponse, e
 		return nil, fmt.Errorf("failed to get payment: %w", err)
 	}
 
-	return &input.PaymentResponse{
-		ID:        payment.ID,
-		Amount:    payment.Amount,
-		Currency:  payment.Currency,
-		Reference: payment.Reference,
-		Status:    payment.Status,
-		CreatedAt: payment.CreatedAt,
+	return toPaymentResponse(payment), nil
+}
+
+// GetPaymentVersion checks that a payment exists and returns when it last changed
+func (s *PaymentServiceImpl) GetPaymentVersion(id uuid.UUID, merchantID string) (time.Time, error) {
+	updatedAt, err := s.paymentRepo.GetUpdatedAt(id, merchantID)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to check payment: %w", err)
+	}
+	return updatedAt, nil
+}
+
+// GetPaymentWithIncludes retrieves a payment by ID along with the requested related records
+func (s *PaymentServiceImpl) GetPaymentWithIncludes(id uuid.UUID, includes []string) (*input.PaymentResponse, error) {
+	// Validate includes against the whitelist
+	var relations output.PaymentRelations
+	for _, include := range includes {
+		switch strings.TrimSpace(include) {
+		case input.IncludeEvents:
+			relations.Events = true
+		case input.IncludeLedger:
+			relations.Ledger = true
+		default:
+			return nil, fmt.Errorf("%w: include must be one of: %s, %s", core.ErrInvalidParameter, input.IncludeEvents, input.IncludeLedger)
+		}
+	}
+
+	payment, err := s.paymentRepo.GetByIDWithRelations(id, relations)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get payment: %w", err)
+	}
+
+	response := toPaymentResponse(payment)
+	if relations.Events {
+		response.Events = toPaymentEventResponses(payment.Events)
+	}
+	if relations.Ledger {
+		response.LedgerEntries = toLedgerEntryResponses(payment.LedgerEntries)
+	}
+	return response, nil
+}
+
+// ListPayments retrieves payments matching the request filters
+func (s *PaymentServiceImpl) ListPayments(req input.ListPaymentsRequest) (*input.ListPaymentsResponse, error) {
+	// Apply pagination defaults
+	if req.Limit == 0 {
+		req.Limit = DefaultListLimit
+	}
+	if req.Limit < 0 || req.Limit > MaxListLimit {
+		return nil, fmt.Errorf("%w: limit must be between 1 and %d", core.ErrInvalidParameter, MaxListLimit)
+	}
+	if req.Offset < 0 {
+		return nil, fmt.Errorf("%w: offset must not be negative", core.ErrInvalidParameter)
+	}
+
+	// Validate time window
+	if !req.CreatedAfter.IsZero() && !req.CreatedBefore.IsZero() && !req.CreatedAfter.Before(req.CreatedBefore) {
+		return nil, fmt.Errorf("%w: created_after must be before created_before", core.ErrInvalidParameter)
+	}
+	if req.Status != "" && !req.Status.IsValid() {
+		return nil, fmt.Errorf("%w: status must be REVIEW, PENDING, AUTHORIZED, SUCCESS, FAILED, EXPIRED or CANCELLED", core.ErrInvalidParameter)
+	}
+	if req.Method != "" && !req.Method.IsValid() {
+		return nil, fmt.Errorf("%w: method must be card, mobile_money, bank_transfer or unknown", core.ErrInvalidParameter)
+	}
+
+	filter := output.PaymentFilter{
+		MerchantID:    req.MerchantID,
+		IsTest:        req.IsTest,
+		Status:        req.Status,
+		Method:        req.Method,
+		CustomerID:    req.CustomerID,
+		Tag:           req.Tag,
+		CreatedAfter:  req.CreatedAfter,
+		CreatedBefore: req.CreatedBefore,
+		Limit:         req.Limit,
+		Offset:        req.Offset,
+	}
+	payments, err := s.paymentRepo.List(filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list payments: %w", err)
+	}
+
+	responses := make([]input.PaymentResponse, 0, len(payments))
+	for _, payment := range payments {
+		responses = append(responses, *toPaymentResponse(payment))
+	}
+
+	response := &input.ListPaymentsResponse{
+		Payments: responses,
+		Limit:    req.Limit,
+		Offset:   req.Offset,
+	}
+	if req.IncludeTotal {
+		total, err := s.paymentRepo.Count(filter)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count payments: %w", err)
+		}
+		response.Total = &total
+	}
+	return response, nil
+}
+
+// ListPaymentEvents retrieves a page of a payment's status history, newest first unless asked otherwise
+func (s *PaymentServiceImpl) ListPaymentEvents(req input.ListPaymentEventsRequest) (*input.ListPaymentEventsResponse, error) {
+	if req.Limit == 0 {
+		req.Limit = DefaultListLimit
+	}
+	if req.Limit < 0 || req.Limit > MaxListLimit {
+		return nil, fmt.Errorf("%w: limit must be between 1 and %d", core.ErrInvalidParameter, MaxListLimit)
+	}
+	if req.Offset < 0 {
+		return nil, fmt.Errorf("%w: offset must not be negative", core.ErrInvalidParameter)
+	}
+	if req.Order == "" {
+		req.Order = input.EventOrderNewest
+	}
+	if req.Order != input.EventOrderNewest && req.Order != input.EventOrderOldest {
+		return nil, fmt.Errorf("%w: order must be %s or %s", core.ErrInvalidParameter, input.EventOrderNewest, input.EventOrderOldest)
+	}
+
+	// Ensure the payment exists so unknown IDs are reported as not found rather than as an empty page
+	payment, err := s.paymentRepo.GetByID(req.PaymentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get payment: %w", err)
+	}
+	if req.MerchantID != "" && payment.MerchantID != req.MerchantID {
+		return nil, fmt.Errorf("failed to get payment: %w", core.ErrPaymentNotFound)
+	}
+
+	events, err := s.paymentRepo.ListEvents(output.EventPage{
+		PaymentID:   req.PaymentID,
+		OldestFirst: req.Order == input.EventOrderOldest,
+		Limit:       req.Limit,
+		Offset:      req.Offset,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list payment events: %w", err)
+	}
+
+	return &input.ListPaymentEventsResponse{
+		Events: toPaymentEventResponses(events),
+		Order:  req.Order,
+		Limit:  req.Limit,
+		Offset: req.Offset,
 	}, nil
 }
 
+// toPaymentEventResponses converts core payment events to input port responses
+func toPaymentEventResponses(events []core.PaymentEvent) []input.PaymentEventResponse {
+	responses := make([]input.PaymentEventResponse, 0, len(events))
+	for _, event := range events {
+		responses = append(responses, input.PaymentEventResponse{
+			ID:         event.ID,
+			FromStatus: event.FromStatus,
+			ToStatus:   event.ToStatus,
+			Note:       event.Note,
+			Actor:      event.Actor,
+			CreatedAt:  event.CreatedAt,
+		})
+	}
+	return responses
+}
+
+// CapturePayment settles (part of) an AUTHORIZED payment
+// The payment's amount becomes the captured amount and the rest of the authorization is released
+// The capture is committed with the merchant's webhook before payment.succeeded is published, so a
+// publish failure is only logged
+func (s *PaymentServiceImpl) CapturePayment(req input.CapturePaymentRequest) (*input.PaymentResponse, error) {
+	if req.Amount < 0 {
+		return nil, &input.ValidationError{Fields: []input.FieldError{
+			{Field: "amount", Message: "amount must be greater than zero", Err: core.ErrInvalidAmount},
+		}}
+	}
+
+	payment, err := s.paymentRepo.GetByID(req.PaymentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get payment: %w", err)
+	}
+
+	// Payments belonging to another merchant are reported as not found
+	if req.MerchantID != "" && payment.MerchantID != req.MerchantID {
+		return nil, core.ErrPaymentNotFound
+	}
+
+	// 0 captures the full authorization, so an amount that only rounds to 0 must not reach the repository
+	amount := core.RoundAmount(req.Amount, payment.Currency)
+	if req.Amount > 0 && amount == 0 {
+		return nil, &input.ValidationError{Fields: []input.FieldError{
+			{Field: "amount", Message: "amount is less than the currency's smallest unit", Err: core.ErrInvalidAmount},
+		}}
+	}
+
+	captured, err := s.paymentRepo.Capture(req.PaymentID, amount)
+	if err != nil {
+		return nil, fmt.Errorf("failed to capture payment: %w", err)
+	}
+
+	if err := s.publisher.Publish(core.PaymentProcessedEvent(captured, captured.Status, s.clock.Now())); err != nil {
+		log.Printf("Failed to publish capture event for payment %s: %v", captured.ID, err)
+	}
+
+	return toPaymentResponse(captured), nil
+}
+
+// VoidPayment releases an AUTHORIZED payment without capturing it, moving it to CANCELLED
+// The void is committed with the merchant's webhook before payment.cancelled is published, so a
+// publish failure is only logged
+func (s *PaymentServiceImpl) VoidPayment(req input.VoidPaymentRequest) (*input.PaymentResponse, error) {
+	payment, err := s.paymentRepo.GetByID(req.PaymentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get payment: %w", err)
+	}
+
+	// Payments belonging to another merchant are reported as not found
+	if req.MerchantID != "" && payment.MerchantID != req.MerchantID {
+		return nil, core.ErrPaymentNotFound
+	}
+
+	voided, err := s.paymentRepo.Void(req.PaymentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to void payment: %w", err)
+	}
+
+	if err := s.publisher.Publish(core.PaymentProcessedEvent(voided, voided.Status, s.clock.Now())); err != nil {
+		log.Printf("Failed to publish void event for payment %s: %v", voided.ID, err)
+	}
+
+	return toPaymentResponse(voided), nil
+}
+
+// ListChildPayments retrieves a parent payment's installments and how much of its amount they collected
+func (s *PaymentServiceImpl) ListChildPayments(parentID uuid.UUID, merchantID string) (*input.ListChildPaymentsResponse, error) {
+	group, err := s.paymentRepo.GetGroup(parentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get payment: %w", err)
+	}
+
+	// Payments belonging to another merchant are reported as not found
+	if merchantID != "" && group.Parent.MerchantID != merchantID {
+		return nil, core.ErrPaymentNotFound
+	}
+
+	response := &input.ListChildPaymentsResponse{
+		ParentPaymentID: group.Parent.ID,
+		Currency:        group.Parent.Currency,
+		Children:        make([]input.PaymentResponse, 0, len(group.Children)),
+		TotalAmount:     group.Parent.Amount,
+		PaidAmount:      group.PaidAmount(),
+		PendingAmount:   group.PendingAmount(),
+		RemainingAmount: group.RemainingAmount(),
+	}
+	for _, child := range group.Children {
+		response.Children = append(response.Children, *toPaymentResponse(child))
+	}
+	return response, nil
+}
+
+// toPaymentResponse converts a core.Payment to the input port response
+func toPaymentResponse(payment *core.Payment) *input.PaymentResponse {
+	return &input.PaymentResponse{
+		ID:               payment.ID,
+		MerchantID:       payment.MerchantID,
+		Amount:           payment.Amount,
+		Currency:         payment.Currency,
+		Reference:        payment.Reference,
+		Description:      payment.Description,
+		CustomerID:       payment.CustomerID,
+		CustomerEmail:    payment.CustomerEmail,
+		Status:           payment.Status,
+		Source:           payment.Source,
+		Method:           payment.Method,
+		IsTest:           payment.IsTest,
+		AuthOnly:         payment.AuthOnly,
+		AuthorizedAmount: payment.AuthorizedAmount,
+		Tags:             payment.Tags,
+		ExpiresAt:        payment.ExpiresAt,
+		ParentPaymentID:  payment.ParentPaymentID,
+		Attempts:         payment.Attempts,
+		CreatedAt:        payment.CreatedAt,
+		UpdatedAt:        payment.UpdatedAt,
+	}
+}
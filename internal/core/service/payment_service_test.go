@@ -0,0 +1,308 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/cashflow/payment-gateway/internal/core"
+	"github.com/cashflow/payment-gateway/internal/port/input"
+	"github.com/cashflow/payment-gateway/internal/port/output"
+	"github.com/google/uuid"
+)
+
+// newTestPaymentService wires a payment service to repo and publisher, on repo's clock, with
+// default settings apart from the reference dedup windows
+func newTestPaymentService(repo *memoryPaymentRepository, publisher *fakePublisher, dedupWindows ReferenceDedupWindows) input.PaymentService {
+	validator := NewPaymentValidator(repo, repo.clock, "", nil)
+	return NewPaymentService(repo, publisher, validator, PaymentServiceOptions{Clock: repo.clock, DedupWindows: dedupWindows})
+}
+
+func TestCreatePaymentResolvesTTLFromClock(t *testing.T) {
+	clock := core.NewFakeClock(time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC))
+	payments := newTestPaymentService(newMemoryPaymentRepository(clock), &fakePublisher{}, nil)
+
+	created, err := payments.CreatePayment(input.CreatePaymentRequest{
+		MerchantID: "merchant-1",
+		Amount:     100,
+		Currency:   core.CurrencyETB,
+		Reference:  "TTL-1",
+		TTLSeconds: 90,
+	})
+	if err != nil {
+		t.Fatalf("CreatePayment: %v", err)
+	}
+	want := clock.Now().Add(90 * time.Second)
+	if created.ExpiresAt == nil || !created.ExpiresAt.Equal(want) {
+		t.Errorf("expires_at: got %v, want %v", created.ExpiresAt, want)
+	}
+
+	// An absolute expires_at is judged against the same clock
+	past := clock.Now().Add(-time.Second)
+	_, err = payments.CreatePayment(input.CreatePaymentRequest{
+		MerchantID: "merchant-1",
+		Amount:     100,
+		Currency:   core.CurrencyETB,
+		Reference:  "TTL-2",
+		ExpiresAt:  &past,
+	})
+	if !errors.Is(err, core.ErrInvalidExpiry) {
+		t.Errorf("expires_at in the past: got %v, want %v", err, core.ErrInvalidExpiry)
+	}
+}
+
+func TestCreatePaymentReferenceDedupWindow(t *testing.T) {
+	const window = 10 * time.Minute
+	tests := []struct {
+		name         string
+		elapsed      time.Duration
+		wantReplayed bool
+	}{
+		{name: "within the window", elapsed: window / 2, wantReplayed: true},
+		{name: "at the end of the window", elapsed: window, wantReplayed: true},
+		{name: "after the window", elapsed: window + time.Nanosecond},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := core.NewFakeClock(time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC))
+			payments := newTestPaymentService(newMemoryPaymentRepository(clock), &fakePublisher{}, ReferenceDedupWindows{"merchant-1": window})
+			req := input.CreatePaymentRequest{
+				MerchantID: "merchant-1",
+				Amount:     100,
+				Currency:   core.CurrencyETB,
+				Reference:  "DEDUP-1",
+			}
+
+			first, err := payments.CreatePayment(req)
+			if err != nil {
+				t.Fatalf("first CreatePayment: %v", err)
+			}
+			clock.Advance(tt.elapsed)
+			second, err := payments.CreatePayment(req)
+
+			if !tt.wantReplayed {
+				if !errors.Is(err, core.ErrReferenceExists) {
+					t.Errorf("repeat: got %v, want %v", err, core.ErrReferenceExists)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("repeat CreatePayment: %v", err)
+			}
+			if !second.Replayed || second.ID != first.ID {
+				t.Errorf("repeat: got payment %s (replayed %t), want %s replayed", second.ID, second.Replayed, first.ID)
+			}
+		})
+	}
+}
+
+// BenchmarkCreatePayment measures a create's validation, storage and publishing, with the
+// database and broker replaced by in-memory fakes, so it tracks the service's own cost
+func BenchmarkCreatePayment(b *testing.B) {
+	repo := newMemoryPaymentRepository(nil)
+	publisher := &fakePublisher{}
+	payments := newTestPaymentService(repo, publisher, nil)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, err := payments.CreatePayment(input.CreatePaymentRequest{
+			MerchantID:    "merchant-bench",
+			Amount:        1250.75,
+			Currency:      core.CurrencyETB,
+			Reference:     "BENCH-" + strconv.Itoa(i),
+			Description:   "Order #" + strconv.Itoa(i),
+			CustomerEmail: "customer@example.com",
+			Tags:          []string{"subscription", "web"},
+		})
+		if err != nil {
+			b.Fatalf("CreatePayment: %v", err)
+		}
+	}
+}
+
+func TestVoidPayment(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     core.PaymentStatus
+		merchantID string
+		wantErr    error
+	}{
+		{name: "authorized", status: core.PaymentStatusAuthorized, merchantID: "merchant-1"},
+		{name: "unscoped", status: core.PaymentStatusAuthorized},
+		{name: "still pending", status: core.PaymentStatusPending, merchantID: "merchant-1", wantErr: core.ErrPaymentNotVoidable},
+		{name: "already captured", status: core.PaymentStatusSuccess, merchantID: "merchant-1", wantErr: core.ErrPaymentNotVoidable},
+		{name: "another merchant's", status: core.PaymentStatusAuthorized, merchantID: "merchant-2", wantErr: core.ErrPaymentNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newMemoryPaymentRepository(nil)
+			publisher := &fakePublisher{}
+			payment := &core.Payment{
+				ID:               uuid.New(),
+				MerchantID:       "merchant-1",
+				Amount:           100,
+				AuthorizedAmount: 100,
+				Currency:         core.CurrencyETB,
+				Reference:        "VOID-1",
+				Status:           tt.status,
+			}
+			if err := repo.Create(payment); err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+
+			voided, err := newTestPaymentService(repo, publisher, nil).VoidPayment(input.VoidPaymentRequest{
+				PaymentID:  payment.ID,
+				MerchantID: tt.merchantID,
+			})
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("VoidPayment: got %v, want %v", err, tt.wantErr)
+				}
+				if events := publisher.published(); len(events) != 0 {
+					t.Errorf("published %v, want nothing", events)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("VoidPayment: %v", err)
+			}
+			if voided.Status != core.PaymentStatusCancelled {
+				t.Errorf("status: got %s, want %s", voided.Status, core.PaymentStatusCancelled)
+			}
+			events := publisher.published()
+			if len(events) != 1 || events[0].EventType() != core.EventTypePaymentCancelled {
+				t.Errorf("published %v, want one payment.cancelled", events)
+			}
+		})
+	}
+}
+
+func TestCapturePaymentRejectsAmountBelowSmallestUnit(t *testing.T) {
+	repo := newMemoryPaymentRepository(nil)
+	payment := &core.Payment{
+		ID:               uuid.New(),
+		MerchantID:       "merchant-1",
+		Amount:           100,
+		AuthorizedAmount: 100,
+		Currency:         core.CurrencyETB,
+		Reference:        "CAPTURE-1",
+		Status:           core.PaymentStatusAuthorized,
+	}
+	if err := repo.Create(payment); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	// 0.001 rounds to 0, which would otherwise capture the full authorization
+	_, err := newTestPaymentService(repo, &fakePublisher{}, nil).CapturePayment(input.CapturePaymentRequest{
+		PaymentID: payment.ID,
+		Amount:    0.001,
+	})
+
+	if !errors.Is(err, core.ErrInvalidAmount) {
+		t.Fatalf("CapturePayment: got %v, want %v", err, core.ErrInvalidAmount)
+	}
+	stored, err := repo.GetByID(payment.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if stored.Status != core.PaymentStatusAuthorized {
+		t.Errorf("status: got %s, want %s", stored.Status, core.PaymentStatusAuthorized)
+	}
+}
+
+func TestCreatePaymentWithMessagingUnavailable(t *testing.T) {
+	publishErr := fmt.Errorf("failed to publish message: %w", output.ErrMessagingUnavailable)
+
+	tests := []struct {
+		name    string
+		mode    PublishFailureMode
+		wantErr error
+	}{
+		{name: "fail open", mode: PublishFailOpen},
+		{name: "fail closed", mode: PublishFailClosed, wantErr: core.ErrPaymentNotEnqueued},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The relay judges outbox age by the system clock, so payments stamped in 2024 are due
+			repo := newMemoryPaymentRepository(core.NewFakeClock(time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)))
+			publisher := &fakePublisher{err: publishErr}
+			validator := NewPaymentValidator(repo, repo.clock, "", nil)
+			svc := NewPaymentService(repo, publisher, validator, PaymentServiceOptions{Clock: repo.clock, PublishFailure: tt.mode})
+
+			created, err := svc.CreatePayment(input.CreatePaymentRequest{
+				MerchantID: "merchant-1",
+				Amount:     100,
+				Currency:   core.CurrencyETB,
+				Reference:  "BROKER-DOWN-1",
+			})
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) || !errors.Is(err, output.ErrMessagingUnavailable) {
+					t.Fatalf("CreatePayment: got %v, want %v wrapping %v", err, tt.wantErr, output.ErrMessagingUnavailable)
+				}
+				// Nothing is left behind for the relay or a retry to trip over
+				if len(repo.payments) != 0 || len(repo.unpublished) != 0 {
+					t.Errorf("left %d payments and %d outbox entries, want none", len(repo.payments), len(repo.unpublished))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CreatePayment: %v", err)
+			}
+
+			// The payment is accepted, reported as not enqueued, and left in the outbox
+			if created.Enqueued || created.Status != core.PaymentStatusPending {
+				t.Errorf("created: enqueued %t, status %s; want false, %s", created.Enqueued, created.Status, core.PaymentStatusPending)
+			}
+			if !repo.unpublished[created.ID] {
+				t.Fatalf("payment %s has no outbox entry", created.ID)
+			}
+
+			// Once the broker is back, the relay publishes it and clears the entry
+			publisher.err = nil
+			n, err := NewOutboxRelay(repo, publisher).RelayUnpublished()
+			if err != nil || n != 1 {
+				t.Fatalf("RelayUnpublished: got %d, %v; want 1, nil", n, err)
+			}
+			events := publisher.published()
+			if len(events) != 1 || events[0].EventType() != core.EventTypePaymentCreated {
+				t.Errorf("published %v, want one payment.created", events)
+			}
+			if repo.unpublished[created.ID] {
+				t.Errorf("payment %s still has an outbox entry", created.ID)
+			}
+		})
+	}
+}
+
+// rejectingValidator is a Validator that reports every request's reference as invalid
+type rejectingValidator struct{}
+
+func (rejectingValidator) Validate(req *input.CreatePaymentRequest) error {
+	return &input.ValidationError{Fields: []input.FieldError{
+		{Field: "reference", Message: "reference is blocked", Err: core.ErrInvalidReference},
+	}}
+}
+
+func TestCreatePaymentUsesInjectedValidator(t *testing.T) {
+	repo := newMemoryPaymentRepository(nil)
+	publisher := &fakePublisher{}
+	svc := NewPaymentService(repo, publisher, rejectingValidator{}, PaymentServiceOptions{})
+
+	_, err := svc.CreatePayment(input.CreatePaymentRequest{
+		MerchantID: "merchant-1",
+		Amount:     100,
+		Currency:   core.CurrencyETB,
+		Reference:  "BLOCKED-1",
+	})
+	if !errors.Is(err, core.ErrInvalidReference) {
+		t.Fatalf("CreatePayment: got %v, want %v", err, core.ErrInvalidReference)
+	}
+	if len(repo.payments) != 0 || len(publisher.published()) != 0 {
+		t.Errorf("stored %d payments and published %d events, want none", len(repo.payments), len(publisher.published()))
+	}
+}
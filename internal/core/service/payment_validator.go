@@ -0,0 +1,314 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/cashflow/payment-gateway/internal/core"
+	"github.com/cashflow/payment-gateway/internal/port/input"
+	"github.com/cashflow/payment-gateway/internal/port/output"
+)
+
+const (
+	MaxReferenceLength     = 255 // Matches the varchar(255) reference column
+	MaxDescriptionLength   = 500 // Matches the varchar(500) description column
+	MaxCustomerIDLength    = 64  // Matches the varchar(64) customer_id column
+	MaxCustomerEmailLength = 254 // Matches the varchar(254) customer_email column
+	MaxTags                = 10  // Upper bound on tags per payment
+	MaxTagLength           = 50  // Upper bound on a single tag's length
+)
+
+// referencePattern whitelists the characters allowed in a payment reference
+var referencePattern = regexp.MustCompile(`^[A-Za-z0-9\-_./]+$`)
+
+// Validator validates payment creation requests for the payment service
+type Validator interface {
+	// Validate checks req, normalizing its fields in place
+	// Field problems are reported together as an *input.ValidationError; any other error means
+	// validation itself could not be completed
+	Validate(req *input.CreatePaymentRequest) error
+}
+
+// PaymentValidator is the Validator payment services are wired with
+// It is shared by the create and dry-run validation paths
+type PaymentValidator struct {
+	paymentRepo    output.PaymentRepository
+	clock          core.Clock
+	rounding       core.RoundingMode
+	minProcessable MinProcessableAmounts
+}
+
+// NewPaymentValidator creates a new payment validator
+// clock resolves ttl_seconds and checks expires_at is in the future
+// rounding decides how amounts with more decimal places than their currency keeps are treated;
+// an empty mode rejects them
+// minProcessable rejects amounts the currency's rails would decline; nil uses the registry's minimums
+func NewPaymentValidator(paymentRepo output.PaymentRepository, clock core.Clock, rounding core.RoundingMode, minProcessable MinProcessableAmounts) *PaymentValidator {
+	if rounding == "" {
+		rounding = core.RoundingReject
+	}
+	return &PaymentValidator{
+		paymentRepo:    paymentRepo,
+		clock:          clock,
+		rounding:       rounding,
+		minProcessable: minProcessable,
+	}
+}
+
+// Validate validates a create request, normalizing its amount, reference, description, source, method, tags and expiry in place
+// All field problems are reported together as an *input.ValidationError;
+// any other error means validation itself could not be completed
+func (v *PaymentValidator) Validate(req *input.CreatePaymentRequest) error {
+	fieldErrors := v.validateFields(req)
+
+	// Check for duplicates only once the reference format is valid
+	if !hasFieldError(fieldErrors, "reference") {
+		start := time.Now()
+		exists, err := v.paymentRepo.ReferenceExists(req.Reference)
+		req.Timings.AddDB(start)
+		if err != nil {
+			return fmt.Errorf("failed to validate reference: %w", err)
+		}
+		if exists {
+			fieldErrors = append(fieldErrors, input.FieldError{Field: "reference", Message: "reference already exists", Err: core.ErrReferenceExists})
+		}
+	}
+
+	// Check an installment against its parent; the create checks again with the parent locked
+	if req.ParentPaymentID != nil {
+		parentErrors, err := v.validateParent(req, fieldErrors)
+		if err != nil {
+			return err
+		}
+		fieldErrors = append(fieldErrors, parentErrors...)
+	}
+
+	if len(fieldErrors) > 0 {
+		return &input.ValidationError{Fields: fieldErrors}
+	}
+	return nil
+}
+
+// validateFields runs the stateless field rules
+func (v *PaymentValidator) validateFields(req *input.CreatePaymentRequest) []input.FieldError {
+	var fieldErrors []input.FieldError
+
+	currency, supported := core.LookupCurrency(req.Currency)
+
+	// Bring the amount to the currency's precision under the rounding mode before checking its bounds
+	precise := true
+	if supported && req.Amount > 0 {
+		req.Amount, precise = v.rounding.Apply(req.Amount, currency.Code)
+	}
+
+	// Validate amount, within the currency's bounds when the currency is known
+	switch {
+	case req.Amount <= 0:
+		fieldErrors = append(fieldErrors, input.FieldError{Field: "amount", Message: "amount must be greater than zero", Err: core.ErrInvalidAmount})
+	case !precise:
+		message := fmt.Sprintf("amount must have at most %d decimal places for %s", currency.Decimals, currency.Code)
+		fieldErrors = append(fieldErrors, input.FieldError{Field: "amount", Message: message, Err: core.ErrInvalidAmount})
+	case supported && (req.Amount < currency.MinAmount || req.Amount > currency.MaxAmount):
+		message := fmt.Sprintf("amount must be between %s and %s %s",
+			core.FormatAmount(currency.MinAmount, currency.Code), core.FormatAmount(currency.MaxAmount, currency.Code), currency.Code)
+		fieldErrors = append(fieldErrors, input.FieldError{Field: "amount", Message: message, Err: core.ErrInvalidAmount})
+	case supported && req.Amount < v.minProcessable.For(currency):
+		// Valid, but the rails would decline it once the payment is processed
+		message := fmt.Sprintf("amount must be at least %s %s to be processed",
+			core.FormatAmount(v.minProcessable.For(currency), currency.Code), currency.Code)
+		fieldErrors = append(fieldErrors, input.FieldError{Field: "amount", Message: message, Err: core.ErrAmountNotProcessable})
+	}
+
+	// Validate currency against the registry
+	if !supported {
+		fieldErrors = append(fieldErrors, input.FieldError{Field: "currency", Message: "currency must be ETB or USD", Err: core.ErrInvalidCurrency})
+	}
+
+	// Validate reference
+	req.Reference = strings.TrimSpace(req.Reference)
+	switch {
+	case req.Reference == "":
+		fieldErrors = append(fieldErrors, input.FieldError{Field: "reference", Message: "reference is required", Err: core.ErrInvalidReference})
+	case len(req.Reference) > MaxReferenceLength:
+		fieldErrors = append(fieldErrors, input.FieldError{
+			Field:   "reference",
+			Message: fmt.Sprintf("reference must be at most %d characters", MaxReferenceLength),
+			Err:     core.ErrInvalidReference,
+		})
+	case !referencePattern.MatchString(req.Reference):
+		fieldErrors = append(fieldErrors, input.FieldError{
+			Field:   "reference",
+			Message: "reference must contain only letters, digits and -_./",
+			Err:     core.ErrInvalidReference,
+		})
+	}
+
+	// Validate description
+	req.Description = strings.TrimSpace(req.Description)
+	if utf8.RuneCountInString(req.Description) > MaxDescriptionLength {
+		fieldErrors = append(fieldErrors, input.FieldError{
+			Field:   "description",
+			Message: fmt.Sprintf("description must be at most %d characters", MaxDescriptionLength),
+			Err:     core.ErrInvalidDescription,
+		})
+	}
+
+	// Validate source, defaulting to the public API
+	if req.Source == "" {
+		req.Source = core.PaymentSourceAPI
+	}
+	if !req.Source.IsValid() {
+		fieldErrors = append(fieldErrors, input.FieldError{Field: "source", Message: "source must be api, import or replay", Err: core.ErrInvalidSource})
+	}
+
+	// Validate method, defaulting to unknown until gateways report it
+	if req.Method == "" {
+		req.Method = core.PaymentMethodUnknown
+	}
+	if !req.Method.IsValid() {
+		fieldErrors = append(fieldErrors, input.FieldError{
+			Field:   "method",
+			Message: "method must be card, mobile_money, bank_transfer or unknown",
+			Err:     core.ErrInvalidMethod,
+		})
+	}
+
+	// Validate customer identifiers, both optional
+	req.CustomerID = strings.TrimSpace(req.CustomerID)
+	if len(req.CustomerID) > MaxCustomerIDLength {
+		fieldErrors = append(fieldErrors, input.FieldError{
+			Field:   "customer_id",
+			Message: fmt.Sprintf("customer_id must be at most %d characters", MaxCustomerIDLength),
+			Err:     core.ErrInvalidCustomerID,
+		})
+	}
+	req.CustomerEmail = strings.TrimSpace(req.CustomerEmail)
+	if req.CustomerEmail != "" && !isEmailAddress(req.CustomerEmail) {
+		fieldErrors = append(fieldErrors, input.FieldError{
+			Field:   "customer_email",
+			Message: "customer_email must be a valid email address",
+			Err:     core.ErrInvalidCustomerEmail,
+		})
+	}
+
+	// Validate tags, trimming them and dropping duplicates
+	if message := normalizeTags(req); message != "" {
+		fieldErrors = append(fieldErrors, input.FieldError{Field: "tags", Message: message, Err: core.ErrInvalidTags})
+	}
+
+	// Validate expiry, resolving ttl_seconds to an absolute expires_at
+	now := v.clock.Now()
+	switch {
+	case req.ExpiresAt != nil && req.TTLSeconds != 0:
+		fieldErrors = append(fieldErrors, input.FieldError{
+			Field:   "expires_at",
+			Message: "set either expires_at or ttl_seconds, not both",
+			Err:     core.ErrInvalidExpiry,
+		})
+	case req.TTLSeconds < 0:
+		fieldErrors = append(fieldErrors, input.FieldError{Field: "ttl_seconds", Message: "ttl_seconds must be greater than zero", Err: core.ErrInvalidExpiry})
+	case req.TTLSeconds > 0:
+		expiresAt := now.Add(time.Duration(req.TTLSeconds) * time.Second)
+		req.ExpiresAt, req.TTLSeconds = &expiresAt, 0
+	case req.ExpiresAt != nil && !req.ExpiresAt.After(now):
+		fieldErrors = append(fieldErrors, input.FieldError{Field: "expires_at", Message: "expires_at must be in the future", Err: core.ErrInvalidExpiry})
+	}
+
+	return fieldErrors
+}
+
+// validateParent checks an installment against its parent payment and the parent's other installments
+// The amount and currency are only compared once they are valid themselves
+func (v *PaymentValidator) validateParent(req *input.CreatePaymentRequest, fieldErrors []input.FieldError) ([]input.FieldError, error) {
+	start := time.Now()
+	group, err := v.paymentRepo.GetGroup(*req.ParentPaymentID)
+	req.Timings.AddDB(start)
+	// Payments belonging to another merchant are reported as not found
+	if errors.Is(err, core.ErrPaymentNotFound) || (err == nil && group.Parent.MerchantID != req.MerchantID) {
+		return []input.FieldError{{Field: "parent_payment_id", Message: "parent payment not found", Err: core.ErrInvalidParentPayment}}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to validate parent payment: %w", err)
+	}
+
+	parent := group.Parent
+	if parent.IsInstallment() {
+		return []input.FieldError{{
+			Field:   "parent_payment_id",
+			Message: "parent payment is an installment itself",
+			Err:     core.ErrInvalidParentPayment,
+		}}, nil
+	}
+	if !hasFieldError(fieldErrors, "currency") && req.Currency != parent.Currency {
+		return []input.FieldError{{
+			Field:   "currency",
+			Message: fmt.Sprintf("currency must be the parent payment's currency, %s", parent.Currency),
+			Err:     core.ErrInvalidCurrency,
+		}}, nil
+	}
+	if !hasFieldError(fieldErrors, "amount") && !hasFieldError(fieldErrors, "currency") &&
+		core.RoundAmount(req.Amount, req.Currency) > group.RemainingAmount() {
+		return []input.FieldError{{
+			Field: "amount",
+			Message: fmt.Sprintf("amount must be at most %s %s, what is left of the parent payment's %s",
+				core.FormatAmount(group.RemainingAmount(), parent.Currency), parent.Currency, core.FormatAmount(parent.Amount, parent.Currency)),
+			Err: core.ErrInstallmentsExceedTotal,
+		}}, nil
+	}
+	return nil, nil
+}
+
+// normalizeTags trims and deduplicates the request's tags in place
+// It returns a description of the first problem found, or "" if the tags are valid
+func normalizeTags(req *input.CreatePaymentRequest) string {
+	if len(req.Tags) == 0 {
+		return ""
+	}
+
+	seen := make(map[string]bool, len(req.Tags))
+	tags := make([]string, 0, len(req.Tags))
+	for _, tag := range req.Tags {
+		tag = strings.TrimSpace(tag)
+		switch {
+		case tag == "":
+			return "tags must not be empty"
+		case len(tag) > MaxTagLength:
+			return fmt.Sprintf("tags must be at most %d characters", MaxTagLength)
+		case seen[tag]:
+			continue
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+	}
+	if len(tags) > MaxTags {
+		return fmt.Sprintf("at most %d tags are allowed", MaxTags)
+	}
+
+	req.Tags = tags
+	return ""
+}
+
+// isEmailAddress reports whether value is a bare email address such as "jane@example.com"
+// Display names ("Jane <jane@example.com>") are rejected
+func isEmailAddress(value string) bool {
+	if len(value) > MaxCustomerEmailLength {
+		return false
+	}
+	address, err := mail.ParseAddress(value)
+	return err == nil && address.Address == value
+}
+
+// hasFieldError reports whether any of the errors concerns the given field
+func hasFieldError(fieldErrors []input.FieldError, field string) bool {
+	for _, fieldErr := range fieldErrors {
+		if fieldErr.Field == field {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"github.com/cashflow/payment-gateway/internal/core"
+	"github.com/cashflow/payment-gateway/internal/port/input"
+	"github.com/google/uuid"
+)
+
+// Read-only (maintenance) mode wraps the services so their write operations return
+// core.ErrReadOnly while reads pass through, whichever primary adapter calls them
+
+// readOnlyPaymentService rejects payment creation and captures; dry-run validation still works
+type readOnlyPaymentService struct {
+	input.PaymentService
+}
+
+// NewReadOnlyPaymentService wraps a payment service for read-only mode
+func NewReadOnlyPaymentService(paymentService input.PaymentService) input.PaymentService {
+	return &readOnlyPaymentService{PaymentService: paymentService}
+}
+
+// CreatePayment is rejected in read-only mode
+func (s *readOnlyPaymentService) CreatePayment(req input.CreatePaymentRequest) (*input.PaymentResponse, error) {
+	return nil, core.ErrReadOnly
+}
+
+// CapturePayment is rejected in read-only mode
+func (s *readOnlyPaymentService) CapturePayment(req input.CapturePaymentRequest) (*input.PaymentResponse, error) {
+	return nil, core.ErrReadOnly
+}
+
+// VoidPayment is rejected in read-only mode
+func (s *readOnlyPaymentService) VoidPayment(req input.VoidPaymentRequest) (*input.PaymentResponse, error) {
+	return nil, core.ErrReadOnly
+}
+
+// readOnlyRefundService rejects refunds
+type readOnlyRefundService struct {
+	input.RefundService
+}
+
+// NewReadOnlyRefundService wraps a refund service for read-only mode
+func NewReadOnlyRefundService(refundService input.RefundService) input.RefundService {
+	return &readOnlyRefundService{RefundService: refundService}
+}
+
+// CreateRefund is rejected in read-only mode
+func (s *readOnlyRefundService) CreateRefund(req input.CreateRefundRequest) (*input.RefundResponse, error) {
+	return nil, core.ErrReadOnly
+}
+
+// readOnlyWebhookService rejects webhook replays, which schedule new deliveries
+type readOnlyWebhookService struct {
+	input.WebhookService
+}
+
+// NewReadOnlyWebhookService wraps a webhook service for read-only mode
+func NewReadOnlyWebhookService(webhookService input.WebhookService) input.WebhookService {
+	return &readOnlyWebhookService{WebhookService: webhookService}
+}
+
+// ReplayDelivery is rejected in read-only mode
+func (s *readOnlyWebhookService) ReplayDelivery(paymentID, deliveryID uuid.UUID, merchantID string) (*input.WebhookDeliveryResponse, error) {
+	return nil, core.ErrReadOnly
+}
+
+// ReplayPaymentWebhook is rejected in read-only mode
+func (s *readOnlyWebhookService) ReplayPaymentWebhook(paymentID uuid.UUID, merchantID string) (*input.WebhookDeliveryResponse, error) {
+	return nil, core.ErrReadOnly
+}
+
+// readOnlyAdminService rejects purges, reprocessing, review decisions and status overrides, including dry runs so operators
+// aren't misled into expecting them to go through
+type readOnlyAdminService struct {
+	input.AdminService
+}
+
+// NewReadOnlyAdminService wraps an admin service for read-only mode
+func NewReadOnlyAdminService(adminService input.AdminService) input.AdminService {
+	return &readOnlyAdminService{AdminService: adminService}
+}
+
+// PurgePayments is rejected in read-only mode
+func (s *readOnlyAdminService) PurgePayments(req input.PurgePaymentsRequest) (*input.PurgePaymentsResponse, error) {
+	return nil, core.ErrReadOnly
+}
+
+// ReprocessPayments is rejected in read-only mode
+func (s *readOnlyAdminService) ReprocessPayments(req input.ReprocessPaymentsRequest) (*input.ReprocessPaymentsResponse, error) {
+	return nil, core.ErrReadOnly
+}
+
+// ApprovePayment is rejected in read-only mode
+func (s *readOnlyAdminService) ApprovePayment(id uuid.UUID) (*input.PaymentResponse, error) {
+	return nil, core.ErrReadOnly
+}
+
+// RejectPayment is rejected in read-only mode
+func (s *readOnlyAdminService) RejectPayment(id uuid.UUID) (*input.PaymentResponse, error) {
+	return nil, core.ErrReadOnly
+}
+
+// OverrideStatus is rejected in read-only mode
+func (s *readOnlyAdminService) OverrideStatus(req input.OverrideStatusRequest) (*input.PaymentResponse, error) {
+	return nil, core.ErrReadOnly
+}
+
+// readOnlyExportService rejects starting exports, which record a new export row
+type readOnlyExportService struct {
+	input.ExportService
+}
+
+// NewReadOnlyExportService wraps an export service for read-only mode
+func NewReadOnlyExportService(exportService input.ExportService) input.ExportService {
+	return &readOnlyExportService{ExportService: exportService}
+}
+
+// StartExport is rejected in read-only mode
+func (s *readOnlyExportService) StartExport(req input.StartExportRequest) (*input.ExportResponse, error) {
+	return nil, core.ErrReadOnly
+}
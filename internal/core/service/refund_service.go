@@ -0,0 +1,166 @@
+package service
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"strings"
+
+	"github.com/cashflow/payment-gateway/internal/core"
+	"github.com/cashflow/payment-gateway/internal/port/input"
+	"github.com/cashflow/payment-gateway/internal/port/output"
+	"github.com/google/uuid"
+)
+
+// MaxRefundIDLength matches the varchar(64) refund_id column
+const MaxRefundIDLength = 64
+
+// RefundServiceImpl implements the RefundService input port
+type RefundServiceImpl struct {
+	paymentRepo output.PaymentRepository
+	refundRepo  output.RefundRepository
+	publisher   output.EventPublisher
+}
+
+// NewRefundService creates a new refund service
+func NewRefundService(
+	paymentRepo output.PaymentRepository,
+	refundRepo output.RefundRepository,
+	publisher output.EventPublisher,
+) input.RefundService {
+	return &RefundServiceImpl{
+		paymentRepo: paymentRepo,
+		refundRepo:  refundRepo,
+		publisher:   publisher,
+	}
+}
+
+// CreateRefund refunds (part of) a successful payment, idempotently per refund ID
+func (s *RefundServiceImpl) CreateRefund(req input.CreateRefundRequest) (*input.RefundResponse, error) {
+	// Validate request
+	var fieldErrors []input.FieldError
+	req.RefundID = strings.TrimSpace(req.RefundID)
+	switch {
+	case req.RefundID == "":
+		fieldErrors = append(fieldErrors, input.FieldError{Field: "refund_id", Message: "refund_id is required", Err: core.ErrInvalidRefundID})
+	case len(req.RefundID) > MaxRefundIDLength || !referencePattern.MatchString(req.RefundID):
+		fieldErrors = append(fieldErrors, input.FieldError{
+			Field:   "refund_id",
+			Message: fmt.Sprintf("refund_id must be at most %d letters, digits or -_./", MaxRefundIDLength),
+			Err:     core.ErrInvalidRefundID,
+		})
+	}
+	if req.Amount < 0 {
+		fieldErrors = append(fieldErrors, input.FieldError{Field: "amount", Message: "amount must be greater than zero", Err: core.ErrInvalidAmount})
+	}
+	if len(fieldErrors) > 0 {
+		return nil, &input.ValidationError{Fields: fieldErrors}
+	}
+
+	payment, err := s.paymentRepo.GetByID(req.PaymentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get payment: %w", err)
+	}
+
+	// Payments belonging to another merchant are reported as not found
+	if req.MerchantID != "" && payment.MerchantID != req.MerchantID {
+		return nil, core.ErrPaymentNotFound
+	}
+	if err := validateRefundAmount(req.Amount, payment.Currency); err != nil {
+		return nil, err
+	}
+
+	refund, replayed, err := s.refundRepo.CreateRefund(req.PaymentID, req.RefundID, req.Amount)
+	if err != nil {
+		return nil, fmt.Errorf("failed to refund payment: %w", err)
+	}
+
+	// Replays were already announced; the refund is committed, so a publish failure is only logged
+	if !replayed {
+		event := core.PaymentRefunded{
+			PaymentID:  payment.ID,
+			MerchantID: payment.MerchantID,
+			RefundID:   refund.RefundID,
+			Amount:     refund.Amount,
+			Currency:   refund.Currency,
+			OccurredAt: refund.CreatedAt,
+		}
+		if err := s.publisher.Publish(event); err != nil {
+			log.Printf("Failed to publish refund event for payment %s: %v", payment.ID, err)
+		}
+	}
+
+	response := toRefundResponse(refund)
+	response.Replayed = replayed
+	return response, nil
+}
+
+// ListRefunds retrieves a payment's refunds and its refund balances
+func (s *RefundServiceImpl) ListRefunds(paymentID uuid.UUID, merchantID string) (*input.ListRefundsResponse, error) {
+	payment, err := s.paymentRepo.GetByID(paymentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get payment: %w", err)
+	}
+
+	// Payments belonging to another merchant are reported as not found
+	if merchantID != "" && payment.MerchantID != merchantID {
+		return nil, core.ErrPaymentNotFound
+	}
+
+	refunds, err := s.refundRepo.ListByPaymentID(paymentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list refunds: %w", err)
+	}
+
+	response := &input.ListRefundsResponse{
+		Currency: payment.Currency,
+		Refunds:  make([]input.RefundResponse, 0, len(refunds)),
+	}
+	for _, refund := range refunds {
+		response.Refunds = append(response.Refunds, *toRefundResponse(refund))
+		if refund.Status == core.RefundStatusSuccess {
+			response.RefundedAmount += refund.Amount
+		}
+	}
+	response.RefundedAmount = core.RoundAmount(response.RefundedAmount, payment.Currency)
+	if payment.IsRefundable() {
+		response.RefundableAmount = math.Max(0, core.RoundAmount(payment.Amount-response.RefundedAmount, payment.Currency))
+	}
+	return response, nil
+}
+
+// validateRefundAmount checks a refund amount against the payment's currency: 0 refunds the full
+// balance, anything else must be at least one minor unit with no more decimal places than the
+// currency keeps, since the repository compares amounts in minor units
+func validateRefundAmount(amount float64, currency core.Currency) error {
+	if amount == 0 {
+		return nil
+	}
+
+	minAmount := math.Pow10(-currency.Decimals())
+	if info, ok := core.LookupCurrency(currency); ok {
+		minAmount = info.MinAmount
+	}
+	if _, ok := core.RoundingReject.Apply(amount, currency); ok && amount >= minAmount {
+		return nil
+	}
+	return &input.ValidationError{Fields: []input.FieldError{{
+		Field: "amount",
+		Message: fmt.Sprintf("amount must be 0 for a full refund, or at least %s %s with at most %d decimal places",
+			core.FormatAmount(minAmount, currency), currency, currency.Decimals()),
+		Err: core.ErrInvalidAmount,
+	}}}
+}
+
+// toRefundResponse converts a core.Refund to the input port response
+func toRefundResponse(refund *core.Refund) *input.RefundResponse {
+	return &input.RefundResponse{
+		ID:        refund.ID,
+		PaymentID: refund.PaymentID,
+		RefundID:  refund.RefundID,
+		Amount:    refund.Amount,
+		Currency:  refund.Currency,
+		Status:    refund.Status,
+		CreatedAt: refund.CreatedAt,
+	}
+}
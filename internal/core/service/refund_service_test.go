@@ -0,0 +1,59 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/cashflow/payment-gateway/internal/core"
+	"github.com/cashflow/payment-gateway/internal/port/input"
+)
+
+func TestCreateRefundRejectsAmountsBelowMinorUnit(t *testing.T) {
+	repo := newMemoryPaymentRepository(nil)
+	payment := &core.Payment{
+		ID:         core.NewTimeOrderedID(),
+		MerchantID: "merchant-1",
+		Amount:     100,
+		Currency:   core.CurrencyETB,
+		Reference:  "REFUND-1",
+		Status:     core.PaymentStatusSuccess,
+	}
+	repo.payments[payment.ID] = payment
+	repo.references[payment.Reference] = payment.ID
+
+	tests := []struct {
+		name    string
+		amount  float64
+		wantErr error
+	}{
+		{name: "full refund", amount: 0},
+		{name: "one minor unit", amount: 0.01},
+		{name: "partial refund", amount: 25.5},
+		{name: "below one minor unit", amount: 0.001, wantErr: core.ErrInvalidAmount},
+		{name: "more decimal places than the currency keeps", amount: 10.005, wantErr: core.ErrInvalidAmount},
+		{name: "negative", amount: -1, wantErr: core.ErrInvalidAmount},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			refunds := &fakeRefundRepository{}
+			svc := NewRefundService(repo, refunds, &fakePublisher{})
+
+			_, err := svc.CreateRefund(input.CreateRefundRequest{
+				PaymentID:  payment.ID,
+				MerchantID: payment.MerchantID,
+				RefundID:   "refund-1",
+				Amount:     tt.amount,
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("CreateRefund(%v) error = %v, want %v", tt.amount, err, tt.wantErr)
+			}
+			var validation *input.ValidationError
+			if tt.wantErr != nil && !errors.As(err, &validation) {
+				t.Errorf("CreateRefund(%v) error = %T, want a validation error", tt.amount, err)
+			}
+			if wantCreated := tt.wantErr == nil; (len(refunds.refunds) == 1) != wantCreated {
+				t.Errorf("CreateRefund(%v) created %d refunds", tt.amount, len(refunds.refunds))
+			}
+		})
+	}
+}
@@ -0,0 +1,218 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/cashflow/payment-gateway/internal/core"
+	"github.com/cashflow/payment-gateway/internal/port/output"
+	"github.com/google/uuid"
+)
+
+// WebhookBatchSize is the number of due deliveries a dispatcher claims at a time
+const WebhookBatchSize = 10
+
+// WebhookEndpoints maps merchant IDs to webhook URLs
+// The "*" entry is used for merchants without their own URL
+type WebhookEndpoints map[string]string
+
+// URLFor returns the webhook URL for a merchant, or "" if none is configured
+func (e WebhookEndpoints) URLFor(merchantID string) string {
+	if url, ok := e[merchantID]; ok {
+		return url
+	}
+	return e["*"]
+}
+
+// NewDelivery builds the delivery notifying the merchant of the payment's terminal status,
+// or of its authorization so the merchant knows it can be captured
+// It returns nil when no webhook URL is configured for the merchant; payment repositories store
+// the delivery in the same transaction as the status change
+func (e WebhookEndpoints) NewDelivery(payment *core.Payment) (*core.WebhookDelivery, error) {
+	url := e.URLFor(payment.MerchantID)
+	if url == "" || !(payment.IsTerminal() || payment.IsAuthorized()) {
+		return nil, nil
+	}
+	return newWebhookDelivery(payment, url)
+}
+
+// WebhookPolicy configures webhook retries
+type WebhookPolicy struct {
+	MaxAttempts int           // Attempts before a delivery is marked FAILED
+	BaseBackoff time.Duration // Delay after the first failed attempt, doubled per attempt
+	MaxBackoff  time.Duration // Upper bound on the delay between attempts
+	ClaimLease  time.Duration // How long a claimed batch is hidden from other dispatchers
+}
+
+// Backoff returns the delay before the next attempt after the given number of failed attempts
+func (p WebhookPolicy) Backoff(attempts int) time.Duration {
+	backoff := p.BaseBackoff
+	for i := 1; i < attempts && backoff < p.MaxBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, p.MaxBackoff)
+}
+
+// webhookPayload is the JSON body posted to merchant endpoints
+// ID is the delivery ID; it is unchanged on retries and replays so merchants can deduplicate
+type webhookPayload struct {
+	ID         uuid.UUID          `json:"id"`
+	Event      core.EventType     `json:"event"`
+	PaymentID  uuid.UUID          `json:"payment_id"`
+	MerchantID string             `json:"merchant_id,omitempty"`
+	Amount     json.Number        `json:"amount"`
+	Currency   core.Currency      `json:"currency"`
+	Status     core.PaymentStatus `json:"status"`
+	Source     core.PaymentSource `json:"source,omitempty"`
+	IsTest     bool               `json:"is_test"`
+	CreatedAt  time.Time          `json:"created_at"`
+}
+
+// WebhookDispatcher sends the stored webhook deliveries and drives their retries
+type WebhookDispatcher struct {
+	webhookRepo output.WebhookRepository
+	sender      output.WebhookSender
+	policy      WebhookPolicy
+}
+
+// NewWebhookDispatcher creates a new webhook dispatcher
+func NewWebhookDispatcher(
+	webhookRepo output.WebhookRepository,
+	sender output.WebhookSender,
+	policy WebhookPolicy,
+) *WebhookDispatcher {
+	return &WebhookDispatcher{
+		webhookRepo: webhookRepo,
+		sender:      sender,
+		policy:      policy,
+	}
+}
+
+// webhookEventType returns the event a webhook for the payment's terminal or authorized status reports
+func webhookEventType(payment *core.Payment) core.EventType {
+	switch payment.Status {
+	case core.PaymentStatusAuthorized:
+		return core.EventTypePaymentAuthorized
+	case core.PaymentStatusSuccess:
+		return core.EventTypePaymentSucceeded
+	case core.PaymentStatusExpired:
+		return core.EventTypePaymentExpired
+	case core.PaymentStatusCancelled:
+		return core.EventTypePaymentCancelled
+	}
+	return core.EventTypePaymentFailed
+}
+
+// newWebhookDelivery builds a due delivery of the payment's terminal status to url
+func newWebhookDelivery(payment *core.Payment, url string) (*core.WebhookDelivery, error) {
+	now := time.Now()
+	delivery := &core.WebhookDelivery{
+		ID:            uuid.New(),
+		PaymentID:     payment.ID,
+		MerchantID:    payment.MerchantID,
+		EventType:     webhookEventType(payment),
+		URL:           url,
+		Status:        core.WebhookDeliveryPending,
+		NextAttemptAt: now,
+	}
+
+	payload, err := json.Marshal(webhookPayload{
+		ID:         delivery.ID,
+		Event:      delivery.EventType,
+		PaymentID:  payment.ID,
+		MerchantID: payment.MerchantID,
+		Amount:     json.Number(core.FormatAmount(payment.Amount, payment.Currency)),
+		Currency:   payment.Currency,
+		Status:     payment.Status,
+		Source:     payment.Source,
+		IsTest:     payment.IsTest,
+		CreatedAt:  now,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal webhook payload: %w", err)
+	}
+	delivery.Payload = payload
+	return delivery, nil
+}
+
+// Run dispatches due deliveries every interval until ctx is cancelled
+func (d *WebhookDispatcher) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		// Keep claiming while full batches come back, so a backlog drains without waiting a tick per batch
+		for {
+			n, err := d.DispatchDue(time.Now())
+			if err != nil {
+				log.Printf("Failed to dispatch webhooks: %v", err)
+			}
+			if err != nil || n < WebhookBatchSize || ctx.Err() != nil {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DispatchDue claims and attempts one batch of due deliveries, returning how many were claimed
+func (d *WebhookDispatcher) DispatchDue(now time.Time) (int, error) {
+	deliveries, err := d.webhookRepo.ClaimDue(now, d.policy.ClaimLease, WebhookBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, delivery := range deliveries {
+		d.attempt(delivery)
+		if err := d.webhookRepo.UpdateDelivery(delivery); errors.Is(err, core.ErrDeliveryLeaseLost) {
+			// Replayed or claimed again while this attempt ran; the newer schedule stands
+			log.Printf("Discarded outcome of webhook delivery %s: %v", delivery.ID, err)
+		} else if err != nil {
+			// The lease expires and the delivery is attempted again
+			log.Printf("Failed to record webhook delivery %s: %v", delivery.ID, err)
+		}
+	}
+	return len(deliveries), nil
+}
+
+// attempt sends a delivery once and applies the retry policy to the outcome
+// Connection errors, timeouts, 408, 429 and 5xx are retried; other non-2xx responses fail immediately
+func (d *WebhookDispatcher) attempt(delivery *core.WebhookDelivery) {
+	statusCode, err := d.sender.Send(delivery.URL, delivery.Payload)
+	now := time.Now()
+	delivery.Attempts++
+	delivery.LastStatusCode = statusCode
+
+	if err == nil && statusCode >= 200 && statusCode < 300 {
+		delivery.Status = core.WebhookDeliveryDelivered
+		delivery.LastError = ""
+		delivery.DeliveredAt = &now
+		return
+	}
+
+	retryable := err != nil || statusCode >= 500 ||
+		statusCode == http.StatusRequestTimeout || statusCode == http.StatusTooManyRequests
+	if err != nil {
+		delivery.LastError = err.Error()
+	} else {
+		delivery.LastError = fmt.Sprintf("endpoint responded with status %d", statusCode)
+	}
+
+	if !retryable || delivery.Attempts >= d.policy.MaxAttempts {
+		delivery.Status = core.WebhookDeliveryFailed
+		log.Printf("Webhook delivery %s for payment %s failed after %d attempts: %s",
+			delivery.ID, delivery.PaymentID, delivery.Attempts, delivery.LastError)
+		return
+	}
+	delivery.NextAttemptAt = now.Add(d.policy.Backoff(delivery.Attempts))
+}
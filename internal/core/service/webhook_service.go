@@ -0,0 +1,159 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cashflow/payment-gateway/internal/core"
+	"github.com/cashflow/payment-gateway/internal/port/input"
+	"github.com/cashflow/payment-gateway/internal/port/output"
+	"github.com/google/uuid"
+)
+
+// WebhookServiceImpl implements the WebhookService input port
+type WebhookServiceImpl struct {
+	paymentRepo output.PaymentRepository
+	webhookRepo output.WebhookRepository
+	endpoints   WebhookEndpoints
+}
+
+// NewWebhookService creates a new webhook service
+func NewWebhookService(
+	paymentRepo output.PaymentRepository,
+	webhookRepo output.WebhookRepository,
+	endpoints WebhookEndpoints,
+) input.WebhookService {
+	return &WebhookServiceImpl{
+		paymentRepo: paymentRepo,
+		webhookRepo: webhookRepo,
+		endpoints:   endpoints,
+	}
+}
+
+// ListDeliveries retrieves a payment's webhook deliveries in creation order
+func (s *WebhookServiceImpl) ListDeliveries(paymentID uuid.UUID, merchantID string) ([]input.WebhookDeliveryResponse, error) {
+	if _, err := s.getScopedPayment(paymentID, merchantID); err != nil {
+		return nil, err
+	}
+
+	deliveries, err := s.webhookRepo.ListByPaymentID(paymentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+
+	responses := make([]input.WebhookDeliveryResponse, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		responses = append(responses, *toWebhookDeliveryResponse(delivery))
+	}
+	return responses, nil
+}
+
+// ReplayDelivery schedules a delivered or failed delivery to be sent again with a fresh attempt budget
+func (s *WebhookServiceImpl) ReplayDelivery(paymentID, deliveryID uuid.UUID, merchantID string) (*input.WebhookDeliveryResponse, error) {
+	if _, err := s.getScopedPayment(paymentID, merchantID); err != nil {
+		return nil, err
+	}
+
+	delivery, err := s.webhookRepo.GetDelivery(deliveryID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook delivery: %w", err)
+	}
+	if delivery.PaymentID != paymentID {
+		return nil, core.ErrDeliveryNotFound
+	}
+	if !delivery.IsReplayable() {
+		return nil, core.ErrDeliveryPending
+	}
+
+	if err := s.reschedule(delivery); err != nil {
+		return nil, err
+	}
+	return toWebhookDeliveryResponse(delivery), nil
+}
+
+// ReplayPaymentWebhook re-enqueues the webhook for the payment's current terminal or authorized status
+// The latest delivery of that status is rescheduled (or returned as is while still pending);
+// a new delivery is created if none was ever recorded, e.g. because the URL was added later
+func (s *WebhookServiceImpl) ReplayPaymentWebhook(paymentID uuid.UUID, merchantID string) (*input.WebhookDeliveryResponse, error) {
+	payment, err := s.getScopedPayment(paymentID, merchantID)
+	if err != nil {
+		return nil, err
+	}
+	if !payment.IsTerminal() && !payment.IsAuthorized() {
+		return nil, fmt.Errorf("%w: current status is %s", core.ErrPaymentNotProcessed, payment.Status)
+	}
+
+	deliveries, err := s.webhookRepo.ListByPaymentID(paymentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+
+	// Deliveries are in creation order, so scan from the end for the latest one
+	eventType := webhookEventType(payment)
+	for i := len(deliveries) - 1; i >= 0; i-- {
+		delivery := deliveries[i]
+		if delivery.EventType != eventType {
+			continue
+		}
+		if delivery.IsReplayable() {
+			if err := s.reschedule(delivery); err != nil {
+				return nil, err
+			}
+		}
+		return toWebhookDeliveryResponse(delivery), nil
+	}
+
+	url := s.endpoints.URLFor(payment.MerchantID)
+	if url == "" {
+		return nil, core.ErrWebhookURLNotConfigured
+	}
+	delivery, err := newWebhookDelivery(payment, url)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.webhookRepo.CreateDelivery(delivery); err != nil {
+		return nil, fmt.Errorf("failed to replay webhook delivery: %w", err)
+	}
+	return toWebhookDeliveryResponse(delivery), nil
+}
+
+// reschedule makes a delivery due now with a fresh attempt budget
+func (s *WebhookServiceImpl) reschedule(delivery *core.WebhookDelivery) error {
+	delivery.Status = core.WebhookDeliveryPending
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = time.Now()
+	delivery.DeliveredAt = nil
+	if err := s.webhookRepo.UpdateDelivery(delivery); err != nil {
+		return fmt.Errorf("failed to replay webhook delivery: %w", err)
+	}
+	return nil
+}
+
+// getScopedPayment reports payments that don't exist or belong to another merchant as not found
+func (s *WebhookServiceImpl) getScopedPayment(paymentID uuid.UUID, merchantID string) (*core.Payment, error) {
+	payment, err := s.paymentRepo.GetByID(paymentID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get payment: %w", err)
+	}
+	if merchantID != "" && payment.MerchantID != merchantID {
+		return nil, core.ErrPaymentNotFound
+	}
+	return payment, nil
+}
+
+// toWebhookDeliveryResponse converts a core.WebhookDelivery to the input port response
+func toWebhookDeliveryResponse(delivery *core.WebhookDelivery) *input.WebhookDeliveryResponse {
+	return &input.WebhookDeliveryResponse{
+		ID:             delivery.ID,
+		PaymentID:      delivery.PaymentID,
+		EventType:      delivery.EventType,
+		URL:            delivery.URL,
+		Status:         delivery.Status,
+		Attempts:       delivery.Attempts,
+		NextAttemptAt:  delivery.NextAttemptAt,
+		LastError:      delivery.LastError,
+		LastStatusCode: delivery.LastStatusCode,
+		DeliveredAt:    delivery.DeliveredAt,
+		CreatedAt:      delivery.CreatedAt,
+	}
+}
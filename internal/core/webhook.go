@@ -0,0 +1,45 @@
+package core
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WebhookDeliveryStatus represents the status of a webhook delivery
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "PENDING"   // Waiting for its next attempt
+	WebhookDeliveryDelivered WebhookDeliveryStatus = "DELIVERED" // The merchant endpoint answered 2xx
+	WebhookDeliveryFailed    WebhookDeliveryStatus = "FAILED"    // Permanently rejected or out of attempts
+)
+
+// WebhookDelivery is a notification to a merchant endpoint, persisted so pending
+// deliveries and their retry schedule survive restarts
+type WebhookDelivery struct {
+	ID             uuid.UUID
+	PaymentID      uuid.UUID
+	MerchantID     string
+	EventType      EventType
+	URL            string
+	Payload        []byte
+	Status         WebhookDeliveryStatus
+	Attempts       int
+	NextAttemptAt  time.Time
+	LastError      string
+	LastStatusCode int
+	DeliveredAt    *time.Time
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+
+	// LeasedUntil is the lease a dispatcher took when it claimed the delivery; saving the attempt's
+	// outcome only succeeds while the delivery still holds that lease. Zero when not claimed
+	LeasedUntil time.Time
+}
+
+// IsReplayable checks if a delivery can be manually sent again
+// Pending deliveries are still being retried by the dispatcher
+func (d *WebhookDelivery) IsReplayable() bool {
+	return d.Status != WebhookDeliveryPending
+}
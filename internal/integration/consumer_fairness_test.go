@@ -0,0 +1,100 @@
+//go:build integration
+
+package integration
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cashflow/payment-gateway/internal/adapter/secondary/messaging"
+	"github.com/google/uuid"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// TestConsumersShareQueueEvenly runs several workers on one queue, each on a connection of its own
+// with the given prefetch, and checks that a backlog of equally slow messages is spread evenly
+// across them rather than drained by whichever consumer registered first
+func TestConsumersShareQueueEvenly(t *testing.T) {
+	const (
+		workers     = 3
+		messages    = 60
+		processTime = 20 * time.Millisecond
+	)
+
+	tests := []struct {
+		name     string
+		prefetch int
+	}{
+		{name: "default prefetch", prefetch: messaging.PrefetchCount},
+		{name: "prefetch 4", prefetch: 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queue := declareQueue(t)
+
+			var (
+				mu      sync.Mutex
+				counts  = make([]int, workers)
+				pending sync.WaitGroup
+			)
+			pending.Add(messages)
+			for i := 0; i < workers; i++ {
+				i := i
+				client := connectRabbitMQ(t)
+				opts := messaging.ConsumeOptions{
+					PrefetchCount: tt.prefetch,
+					Queues:        []string{queue},
+					WorkerID:      fmt.Sprintf("fairness-%d", i),
+				}
+				err := client.ConsumePaymentMessages(opts, func(msg messaging.PaymentMessage) error {
+					time.Sleep(processTime)
+					mu.Lock()
+					counts[i]++
+					mu.Unlock()
+					pending.Done()
+					return nil
+				})
+				if err != nil {
+					t.Fatalf("worker %d failed to start consuming: %v", i, err)
+				}
+			}
+
+			// Publish straight to the queue through the default exchange, so only this test's
+			// consumers receive the messages
+			channel := rawChannel(t)
+			for i := 0; i < messages; i++ {
+				body, err := json.Marshal(messaging.PaymentMessage{PaymentID: uuid.New(), Timestamp: time.Now()})
+				if err != nil {
+					t.Fatalf("failed to marshal message: %v", err)
+				}
+				if err := channel.Publish("", queue, false, false, amqp.Publishing{ContentType: "application/json", Body: body}); err != nil {
+					t.Fatalf("failed to publish message %d: %v", i, err)
+				}
+			}
+
+			done := make(chan struct{})
+			go func() {
+				pending.Wait()
+				close(done)
+			}()
+			select {
+			case <-done:
+			case <-time.After(processTimeout):
+				t.Fatalf("messages were not all processed within %s", processTimeout)
+			}
+
+			// An even share is messages/workers; allow half of it either way for scheduling noise
+			share := messages / workers
+			mu.Lock()
+			defer mu.Unlock()
+			for i, count := range counts {
+				if count < share/2 || count > share+share/2 {
+					t.Errorf("worker %d processed %d of %d messages, want %d±%d (all workers: %v)", i, count, messages, share, share/2, counts)
+				}
+			}
+		})
+	}
+}
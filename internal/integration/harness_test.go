@@ -0,0 +1,83 @@
+//go:build integration
+
+package integration
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cashflow/payment-gateway/internal/adapter/secondary/messaging"
+	"github.com/cashflow/payment-gateway/internal/constant/model/db"
+	"github.com/cashflow/payment-gateway/internal/testutil/dbtest"
+	"github.com/google/uuid"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// openDB connects to the suite's Postgres in a schema of its own, dropped when the test ends
+func openDB(t *testing.T) *db.DB {
+	t.Helper()
+	return dbtest.Open(t, databaseURL)
+}
+
+// openRabbitMQ connects a client to the suite's RabbitMQ and declares a queue of the test's own,
+// bound to the payment.created keys, so the test consumes its messages without competing with
+// workers on the shared processing queue
+func openRabbitMQ(t *testing.T) (*messaging.RabbitMQClient, string) {
+	t.Helper()
+	queue := declareQueue(t)
+	return connectRabbitMQ(t), queue
+}
+
+// connectRabbitMQ connects a client to the suite's RabbitMQ on a connection of its own, as a
+// separate worker process would; its consumers are stopped when the test ends
+func connectRabbitMQ(t *testing.T) *messaging.RabbitMQClient {
+	t.Helper()
+	client, err := messaging.NewRabbitMQClientConcrete(amqpURL, 5*time.Second)
+	if err != nil {
+		t.Fatalf("failed to connect to RabbitMQ: %v", err)
+	}
+	t.Cleanup(func() {
+		client.StopConsuming()
+		client.Close()
+	})
+	return client
+}
+
+// declareQueue declares a queue of the test's own, bound to the payment.created keys and
+// deleted when the test ends
+func declareQueue(t *testing.T) string {
+	t.Helper()
+	channel := rawChannel(t)
+	queue := "it_" + uuid.NewString()[:8]
+	if _, err := channel.QueueDeclare(queue, false, false, false, false, nil); err != nil {
+		t.Fatalf("failed to declare queue %s: %v", queue, err)
+	}
+	if err := channel.QueueBind(queue, messaging.CurrencyRoutingKeyPattern, messaging.ExchangeName, false, nil); err != nil {
+		t.Fatalf("failed to bind queue %s: %v", queue, err)
+	}
+	t.Cleanup(func() {
+		if _, err := channel.QueueDelete(queue, false, false, false); err != nil {
+			t.Errorf("failed to delete queue %s: %v", queue, err)
+		}
+	})
+	return queue
+}
+
+// rawChannel opens a plain AMQP channel on the suite's RabbitMQ for setting up the broker around
+// the client under test, closed when the test ends
+func rawChannel(t *testing.T) *amqp.Channel {
+	t.Helper()
+	conn, err := amqp.Dial(amqpURL)
+	if err != nil {
+		t.Fatalf("failed to connect to RabbitMQ: %v", err)
+	}
+	channel, err := conn.Channel()
+	if err != nil {
+		conn.Close()
+		t.Fatalf("failed to open channel: %v", err)
+	}
+	t.Cleanup(func() {
+		conn.Close()
+	})
+	return channel
+}
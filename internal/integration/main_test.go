@@ -0,0 +1,79 @@
+//go:build integration
+
+package integration
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"testing"
+
+	"github.com/cashflow/payment-gateway/internal/testutil/dbtest"
+	"github.com/ory/dockertest/v3"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// databaseURL and amqpURL are the servers the suite runs against, set by TestMain
+var (
+	databaseURL string
+	amqpURL     string
+)
+
+// TestMain starts Postgres and RabbitMQ containers for the suite and removes them afterwards
+// TEST_DATABASE_URL and TEST_RABBITMQ_URL override either one with a server already running
+func TestMain(m *testing.M) {
+	os.Exit(run(m))
+}
+
+func run(m *testing.M) int {
+	url, stop, err := dbtest.Start()
+	if err != nil {
+		log.Fatalf("failed to provide Postgres (or set TEST_DATABASE_URL): %v", err)
+	}
+	defer stop()
+	databaseURL = url
+
+	url, stop, err = startRabbitMQ()
+	if err != nil {
+		log.Fatalf("failed to provide RabbitMQ (or set TEST_RABBITMQ_URL): %v", err)
+	}
+	defer stop()
+	amqpURL = url
+
+	return m.Run()
+}
+
+// startRabbitMQ returns TEST_RABBITMQ_URL, or runs a RabbitMQ container and waits until it
+// accepts connections
+func startRabbitMQ() (string, func(), error) {
+	if url := os.Getenv("TEST_RABBITMQ_URL"); url != "" {
+		return url, func() {}, nil
+	}
+
+	pool, err := dbtest.NewPool()
+	if err != nil {
+		return "", nil, err
+	}
+	resource, err := pool.RunWithOptions(&dockertest.RunOptions{
+		Repository: "rabbitmq",
+		Tag:        "3-alpine",
+	}, dbtest.AutoRemove)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to start RabbitMQ: %w", err)
+	}
+	purge := dbtest.Purger(pool, resource)
+
+	url := fmt.Sprintf("amqp://guest:guest@%s/", resource.GetHostPort("5672/tcp"))
+	err = pool.Retry(func() error {
+		conn, err := amqp.Dial(url)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	})
+	if err != nil {
+		purge()
+		return "", nil, fmt.Errorf("failed to reach RabbitMQ: %w", err)
+	}
+	return url, purge, nil
+}
@@ -0,0 +1,223 @@
+//go:build integration
+
+package integration
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cashflow/payment-gateway/internal/adapter/secondary/database"
+	"github.com/cashflow/payment-gateway/internal/adapter/secondary/messaging"
+	"github.com/cashflow/payment-gateway/internal/constant/model/db"
+	"github.com/cashflow/payment-gateway/internal/core"
+	"github.com/cashflow/payment-gateway/internal/core/service"
+	"github.com/cashflow/payment-gateway/internal/port/input"
+	"github.com/cashflow/payment-gateway/internal/port/output"
+	"github.com/google/uuid"
+)
+
+// processTimeout bounds the wait for the worker to process a published payment
+const processTimeout = 30 * time.Second
+
+// worker is the payment processor consuming the test's queue, reporting each result it settles
+type worker struct {
+	processor *service.PaymentProcessor
+	mu        sync.Mutex
+	results   map[uuid.UUID]chan service.ProcessResult
+}
+
+// newWorker wires a processor to the repository and client the way the worker binary does
+func newWorker(repo output.PaymentRepository, client *messaging.RabbitMQClient, maxAttempts int) *worker {
+	return &worker{
+		processor: service.NewPaymentProcessor(repo, client, core.SystemClock{}, maxAttempts),
+		results:   make(map[uuid.UUID]chan service.ProcessResult),
+	}
+}
+
+// handle processes one message and reports its result, returning ProcessPayment's error otherwise
+// A message of a payment the test did not create (published by another run sharing the broker)
+// fails with ErrPaymentNotFound, which the client settles as terminal by acking it
+func (w *worker) handle(msg messaging.PaymentMessage) error {
+	result, err := w.processor.ProcessPayment(msg.PaymentID)
+	if err != nil {
+		return err
+	}
+	w.result(msg.PaymentID) <- result
+	return nil
+}
+
+// result returns the channel the result of processing paymentID is reported on
+func (w *worker) result(paymentID uuid.UUID) chan service.ProcessResult {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	results, ok := w.results[paymentID]
+	if !ok {
+		results = make(chan service.ProcessResult, 1)
+		w.results[paymentID] = results
+	}
+	return results
+}
+
+// wait returns the result of the worker processing paymentID
+func (w *worker) wait(t *testing.T, paymentID uuid.UUID) service.ProcessResult {
+	t.Helper()
+	select {
+	case result := <-w.result(paymentID):
+		return result
+	case <-time.After(processTimeout):
+		t.Fatalf("payment %s was not processed within %s", paymentID, processTimeout)
+		return service.ProcessResult{}
+	}
+}
+
+func TestPaymentFlow(t *testing.T) {
+	conn := openDB(t)
+	client, queue := openRabbitMQ(t)
+
+	repo := database.NewGormPaymentRepository(conn.DB, nil)
+	validator := service.NewPaymentValidator(repo, core.SystemClock{}, "", nil)
+	payments := service.NewPaymentService(repo, client, validator, service.PaymentServiceOptions{})
+
+	w := newWorker(repo, client, 3)
+	if err := client.ConsumePaymentMessages(messaging.ConsumeOptions{Queues: []string{queue}, WorkerID: "integration"}, w.handle); err != nil {
+		t.Fatalf("failed to start consuming: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		reference   string
+		authOnly    bool
+		wantStatus  core.PaymentStatus
+		wantNote    string
+		wantEntries int
+	}{
+		{name: "success", reference: "OK-" + uuid.NewString(), wantStatus: core.PaymentStatusSuccess, wantNote: core.NoteTestSucceeded, wantEntries: 2},
+		{name: "failure", reference: "FAIL-" + uuid.NewString(), wantStatus: core.PaymentStatusFailed, wantNote: core.NoteTestFailed},
+		{name: "auth only", reference: "OK-" + uuid.NewString(), authOnly: true, wantStatus: core.PaymentStatusAuthorized, wantNote: core.NoteTestAuthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			created, err := payments.CreatePayment(input.CreatePaymentRequest{
+				MerchantID: "merchant-integration",
+				Amount:     125.50,
+				Currency:   core.CurrencyETB,
+				Reference:  tt.reference,
+				IsTest:     true,
+				AuthOnly:   tt.authOnly,
+			})
+			if err != nil {
+				t.Fatalf("CreatePayment: %v", err)
+			}
+			if created.Status != core.PaymentStatusPending || !created.Enqueued {
+				t.Fatalf("created payment: status %s, enqueued %t; want PENDING, enqueued", created.Status, created.Enqueued)
+			}
+
+			// The confirmed publish clears the outbox entry, so the relay won't publish it again
+			var outboxEntries int64
+			if err := conn.Model(&db.PaymentOutbox{}).Where("payment_id = ?", created.ID).Count(&outboxEntries).Error; err != nil {
+				t.Fatalf("failed to count outbox entries: %v", err)
+			}
+			if outboxEntries != 0 {
+				t.Errorf("outbox entries after a confirmed publish: got %d, want 0", outboxEntries)
+			}
+
+			result := w.wait(t, created.ID)
+			if result.Duplicate || result.Status != tt.wantStatus || result.Reason != tt.wantNote {
+				t.Fatalf("process result: got %+v, want %s (%s)", result, tt.wantStatus, tt.wantNote)
+			}
+
+			payment, err := repo.GetByIDWithRelations(created.ID, output.PaymentRelations{Events: true, Ledger: true})
+			if err != nil {
+				t.Fatalf("GetByIDWithRelations: %v", err)
+			}
+			if payment.Status != tt.wantStatus || payment.Attempts != 1 {
+				t.Errorf("stored payment: status %s, attempts %d; want %s, 1", payment.Status, payment.Attempts, tt.wantStatus)
+			}
+			if len(payment.Events) != 2 ||
+				payment.Events[0].ToStatus != core.PaymentStatusPending ||
+				payment.Events[1].FromStatus != core.PaymentStatusPending || payment.Events[1].ToStatus != tt.wantStatus ||
+				payment.Events[1].Note != tt.wantNote {
+				t.Errorf("events: got %+v, want creation then PENDING -> %s", payment.Events, tt.wantStatus)
+			}
+			if len(payment.LedgerEntries) != tt.wantEntries {
+				t.Errorf("ledger entries: got %d, want %d", len(payment.LedgerEntries), tt.wantEntries)
+			}
+
+			// A redelivery of the same message changes nothing
+			redelivered, err := w.processor.ProcessPayment(created.ID)
+			if err != nil {
+				t.Fatalf("ProcessPayment redelivery: %v", err)
+			}
+			if !redelivered.Duplicate || redelivered.Status != tt.wantStatus {
+				t.Errorf("redelivery: got %+v, want a duplicate with status %s", redelivered, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestConcurrentDeliveriesProcessOnce(t *testing.T) {
+	const deliveries = 8
+
+	conn := openDB(t)
+	client, _ := openRabbitMQ(t)
+	repo := database.NewGormPaymentRepository(conn.DB, nil)
+
+	// The budget covers every delivery, so none of them gives up on the payment instead
+	w := newWorker(repo, client, deliveries+1)
+
+	payment := &core.Payment{
+		ID:         core.NewTimeOrderedID(),
+		MerchantID: "merchant-integration",
+		Amount:     40,
+		Currency:   core.CurrencyETB,
+		Reference:  "OK-" + uuid.NewString(),
+		Status:     core.PaymentStatusPending,
+		Source:     core.PaymentSourceAPI,
+		Method:     core.PaymentMethodUnknown,
+		IsTest:     true,
+	}
+	if err := repo.Create(payment); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	// Redeliveries of one message racing each other on several workers
+	results := make([]service.ProcessResult, deliveries)
+	errs := make([]error, deliveries)
+	var wg sync.WaitGroup
+	for i := 0; i < deliveries; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], errs[i] = w.processor.ProcessPayment(payment.ID)
+		}(i)
+	}
+	wg.Wait()
+
+	processed := 0
+	for i, result := range results {
+		if errs[i] != nil {
+			t.Fatalf("delivery %d: %v", i, errs[i])
+		}
+		if !result.Duplicate {
+			processed++
+		}
+	}
+	if processed != 1 {
+		t.Errorf("deliveries that processed the payment: got %d, want 1", processed)
+	}
+
+	stored, err := repo.GetByIDWithRelations(payment.ID, output.PaymentRelations{Events: true, Ledger: true})
+	if err != nil {
+		t.Fatalf("GetByIDWithRelations: %v", err)
+	}
+	if stored.Status != core.PaymentStatusSuccess {
+		t.Errorf("status: got %s, want SUCCESS", stored.Status)
+	}
+	if len(stored.Events) != 2 {
+		t.Errorf("events: got %d, want the creation and one transition", len(stored.Events))
+	}
+	if len(stored.LedgerEntries) != 2 {
+		t.Errorf("ledger entries: got %d, want one settlement pair", len(stored.LedgerEntries))
+	}
+}
@@ -0,0 +1,142 @@
+package logger
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"github.com/cashflow/payment-gateway/internal/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// GORM log levels accepted by DB_LOG_LEVEL
+var gormLogLevels = map[string]gormlogger.LogLevel{
+	"silent": gormlogger.Silent,
+	"error":  gormlogger.Error,
+	"warn":   gormlogger.Warn,
+	"info":   gormlogger.Info,
+}
+
+// IsGormLogLevel reports whether level is a valid DB_LOG_LEVEL
+func IsGormLogLevel(level string) bool {
+	_, ok := gormLogLevels[level]
+	return ok
+}
+
+// GormLogger sends GORM's logs through the structured logger
+// At "error" failed queries are logged; "warn" adds queries slower than the slow-query
+// threshold; "info" logs every query. Bound values are inlined into the logged statement
+// with plain strings masked, since references and customer fields may hold personal data
+// It is also a prometheus.Collector counting slow queries whatever the level
+type GormLogger struct {
+	logger        *slog.Logger
+	level         gormlogger.LogLevel
+	slowThreshold time.Duration
+
+	slowQueries     *atomic.Int64 // Shared with the copies LogMode returns
+	slowQueriesDesc *prometheus.Desc
+}
+
+// NewGormLogger creates a GORM logger writing to logger at the given DB_LOG_LEVEL
+// slowThreshold of zero disables slow-query logging and counting
+func NewGormLogger(logger *slog.Logger, level string, slowThreshold time.Duration) *GormLogger {
+	logLevel, ok := gormLogLevels[level]
+	if !ok {
+		logLevel = gormlogger.Warn
+	}
+	return &GormLogger{
+		logger:        logger,
+		level:         logLevel,
+		slowThreshold: slowThreshold,
+		slowQueries:   &atomic.Int64{},
+		slowQueriesDesc: prometheus.NewDesc(metrics.Namespace+"_slow_queries_total",
+			"Database queries that took longer than DB_SLOW_QUERY_MS", nil, nil),
+	}
+}
+
+// LogMode returns a copy of the logger at the given level
+func (l *GormLogger) LogMode(level gormlogger.LogLevel) gormlogger.Interface {
+	copied := *l
+	copied.level = level
+	return &copied
+}
+
+// Info logs GORM's informational messages
+func (l *GormLogger) Info(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= gormlogger.Info {
+		l.logger.InfoContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+// Warn logs GORM's warnings
+func (l *GormLogger) Warn(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= gormlogger.Warn {
+		l.logger.WarnContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+// Error logs GORM's errors
+func (l *GormLogger) Error(ctx context.Context, msg string, args ...interface{}) {
+	if l.level >= gormlogger.Error {
+		l.logger.ErrorContext(ctx, fmt.Sprintf(msg, args...))
+	}
+}
+
+// Trace logs a finished query according to the level: failures, slow queries, or every query
+// Record-not-found is an expected outcome and never logged as a failure
+func (l *GormLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	elapsed := time.Since(begin)
+	slow := l.slowThreshold > 0 && elapsed > l.slowThreshold
+	if slow {
+		l.slowQueries.Add(1)
+	}
+	if l.level <= gormlogger.Silent {
+		return
+	}
+
+	switch {
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound) && l.level >= gormlogger.Error:
+		sql, rows := fc()
+		l.logger.ErrorContext(ctx, "Database query failed",
+			"sql", sql, "rows", rows, "duration", elapsed, "error", err)
+	case slow && l.level >= gormlogger.Warn:
+		sql, rows := fc()
+		l.logger.WarnContext(ctx, "Slow database query",
+			"sql", sql, "rows", rows, "duration", elapsed, "threshold", l.slowThreshold)
+	case l.level >= gormlogger.Info:
+		sql, rows := fc()
+		l.logger.InfoContext(ctx, "Database query", "sql", sql, "rows", rows, "duration", elapsed)
+	}
+}
+
+// ParamsFilter masks the bound values that may hold personal data before they are inlined
+// into a logged statement: plain strings and byte slices (references, descriptions, customer
+// fields) are replaced, while IDs, numbers, times and named enum types (status, currency)
+// are kept so a slow or failing query can still be traced to its rows
+func (l *GormLogger) ParamsFilter(ctx context.Context, sql string, params ...interface{}) (string, []interface{}) {
+	masked := make([]interface{}, len(params))
+	for i, param := range params {
+		switch param.(type) {
+		case string, *string, []byte:
+			masked[i] = RedactedValue
+		default:
+			masked[i] = param
+		}
+	}
+	return sql, masked
+}
+
+// Describe implements prometheus.Collector
+func (l *GormLogger) Describe(ch chan<- *prometheus.Desc) {
+	ch <- l.slowQueriesDesc
+}
+
+// Collect implements prometheus.Collector
+func (l *GormLogger) Collect(ch chan<- prometheus.Metric) {
+	ch <- prometheus.MustNewConstMetric(l.slowQueriesDesc, prometheus.CounterValue, float64(l.slowQueries.Load()))
+}
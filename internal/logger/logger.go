@@ -0,0 +1,20 @@
+package logger
+
+import (
+	"log/slog"
+	"os"
+)
+
+// New creates the structured logger used by both services
+// format is "json" or "text"; every attribute passes through the redactor
+func New(format string, redactor *Redactor) *slog.Logger {
+	opts := &slog.HandlerOptions{ReplaceAttr: redactor.ReplaceAttr}
+
+	var handler slog.Handler
+	if format == "json" {
+		handler = slog.NewJSONHandler(os.Stdout, opts)
+	} else {
+		handler = slog.NewTextHandler(os.Stdout, opts)
+	}
+	return slog.New(handler)
+}
@@ -0,0 +1,126 @@
+package logger
+
+import (
+	"encoding/json"
+	"log/slog"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// RedactedValue replaces sensitive values in logs
+const RedactedValue = "[REDACTED]"
+
+// DefaultRedactKeys are the key patterns masked when none are configured
+var DefaultRedactKeys = []string{
+	"authorization",
+	"*password*",
+	"*secret*",
+	"*token*",
+	"card_number",
+	"cvv",
+	"pan",
+	"*email*",
+	"*phone*",
+}
+
+// cardNumberPattern matches card-like digit runs (13-19 digits, optionally separated by spaces or dashes)
+var cardNumberPattern = regexp.MustCompile(`\b(?:\d[ -]?){12,18}\d\b`)
+
+// jsonFieldPattern matches a "key": value pair in JSON text that may not parse, such as a body cut
+// off mid-value: the value is a string, possibly missing its closing quote, or a bare scalar
+var jsonFieldPattern = regexp.MustCompile(`"((?:[^"\\]|\\.)*)"(\s*:\s*)("(?:[^"\\]|\\.)*"?|[^\s,{}\[\]"]+)`)
+
+// Redactor masks sensitive values before they are logged
+// Keys are matched case-insensitively against glob patterns (e.g. "*email*");
+// card-like numbers are masked wherever they appear
+type Redactor struct {
+	patterns []string
+}
+
+// NewRedactor creates a redactor for the given key patterns
+func NewRedactor(patterns []string) *Redactor {
+	normalized := make([]string, 0, len(patterns))
+	for _, pattern := range patterns {
+		if pattern = strings.ToLower(strings.TrimSpace(pattern)); pattern != "" {
+			normalized = append(normalized, pattern)
+		}
+	}
+	return &Redactor{patterns: normalized}
+}
+
+// IsSensitive reports whether values under the key must be masked
+func (r *Redactor) IsSensitive(key string) bool {
+	key = strings.ToLower(key)
+	for _, pattern := range r.patterns {
+		if matched, _ := path.Match(pattern, key); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// RedactString masks card-like numbers in free text
+func (r *Redactor) RedactString(s string) string {
+	return cardNumberPattern.ReplaceAllString(s, RedactedValue)
+}
+
+// RedactJSON masks sensitive keys in a JSON document and card-like numbers anywhere in it
+// Bodies that are not valid JSON (e.g. truncated) are scanned for "key": value pairs instead,
+// so a sensitive value is masked even when the document around it is incomplete
+func (r *Redactor) RedactJSON(body []byte) string {
+	var parsed interface{}
+	if err := json.Unmarshal(body, &parsed); err == nil {
+		if masked, err := json.Marshal(r.redactValue(parsed)); err == nil {
+			return r.RedactString(string(masked))
+		}
+	}
+	return r.RedactString(r.redactFields(string(body)))
+}
+
+// ReplaceAttr is a slog.HandlerOptions.ReplaceAttr hook masking sensitive attributes
+func (r *Redactor) ReplaceAttr(groups []string, a slog.Attr) slog.Attr {
+	if r.IsSensitive(a.Key) {
+		return slog.String(a.Key, RedactedValue)
+	}
+	if a.Value.Kind() == slog.KindString {
+		return slog.String(a.Key, r.RedactString(a.Value.String()))
+	}
+	return a
+}
+
+// redactValue recursively masks the values of sensitive keys
+func (r *Redactor) redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, nested := range v {
+			if r.IsSensitive(key) {
+				v[key] = RedactedValue
+			} else {
+				v[key] = r.redactValue(nested)
+			}
+		}
+	case []interface{}:
+		for i, nested := range v {
+			v[i] = r.redactValue(nested)
+		}
+	}
+	return value
+}
+
+// redactFields masks the values of sensitive keys in JSON text that could not be parsed
+func (r *Redactor) redactFields(s string) string {
+	var out strings.Builder
+	last := 0
+	for _, m := range jsonFieldPattern.FindAllStringSubmatchIndex(s, -1) {
+		if !r.IsSensitive(s[m[2]:m[3]]) {
+			continue
+		}
+		// Keep the key and separator, replace only the value
+		out.WriteString(s[last:m[5]])
+		out.WriteString(`"` + RedactedValue + `"`)
+		last = m[1]
+	}
+	out.WriteString(s[last:])
+	return out.String()
+}
@@ -0,0 +1,35 @@
+package logger
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRedactJSONTruncatedBody(t *testing.T) {
+	redactor := NewRedactor(DefaultRedactKeys)
+	body := `{"reference":"ORDER-1","customer_email":"jane@example.com","amount":50,"metadata":{"phone":"+251911000000","api_token":"tok_live_abcdef`
+
+	got := redactor.RedactJSON([]byte(body))
+
+	for _, leaked := range []string{"jane@example.com", "+251911000000", "tok_live"} {
+		if strings.Contains(got, leaked) {
+			t.Errorf("redacted body %q still contains %q", got, leaked)
+		}
+	}
+	for _, kept := range []string{`"reference":"ORDER-1"`, `"amount":50`, `"customer_email":"[REDACTED]"`} {
+		if !strings.Contains(got, kept) {
+			t.Errorf("redacted body %q is missing %q", got, kept)
+		}
+	}
+}
+
+func TestRedactJSONValidBody(t *testing.T) {
+	redactor := NewRedactor(DefaultRedactKeys)
+
+	got := redactor.RedactJSON([]byte(`{"customer_email":"jane@example.com","card_number":"4111 1111 1111 1111","amount":50}`))
+
+	want := `{"amount":50,"card_number":"[REDACTED]","customer_email":"[REDACTED]"}`
+	if got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
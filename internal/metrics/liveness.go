@@ -0,0 +1,93 @@
+package metrics
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// LivenessPath is where the worker reports whether it is still draining its queues
+const LivenessPath = "/health"
+
+// Liveness tells a wedged consumer, still connected but no longer finishing messages, from an idle one
+// The worker is unhealthy once no message was handled for the window while its queues hold messages
+type Liveness struct {
+	window  time.Duration
+	backlog func() (int, error) // Messages in the worker's queues; nil when the backend can't tell
+	last    atomic.Int64        // Unix nanoseconds of the last handled message, or of startup
+	now     func() time.Time
+}
+
+// NewLiveness creates a liveness check over window; startup counts as the last handled message,
+// so a worker gets a full window to handle its first one
+// backlog reports the messages waiting in or delivered from the worker's queues; with a nil backlog
+// the worker is always reported live, since an idle worker can't be told from a wedged one
+func NewLiveness(window time.Duration, backlog func() (int, error)) *Liveness {
+	l := &Liveness{window: window, backlog: backlog, now: time.Now}
+	l.MessageHandled()
+	return l
+}
+
+// MessageHandled records that a message was just processed and acknowledged
+func (l *Liveness) MessageHandled() {
+	l.last.Store(l.now().UnixNano())
+}
+
+// LastHandled returns when a message was last handled, or when the worker started if none was
+func (l *Liveness) LastHandled() time.Time {
+	return time.Unix(0, l.last.Load())
+}
+
+// LivenessStatus is the JSON body LivenessPath responds with
+type LivenessStatus struct {
+	Status          string    `json:"status"` // "ok" or "unhealthy"
+	LastProcessedAt time.Time `json:"last_processed_at"`
+	Backlog         *int      `json:"backlog,omitempty"`
+	Error           string    `json:"error,omitempty"` // Why the backlog is unknown
+}
+
+// Check reports the worker's liveness
+// It is live if it handled a message within the window or nothing is waiting; when the backlog
+// can't be read it is reported live too, so a management API outage doesn't restart every worker
+func (l *Liveness) Check() (live bool, status LivenessStatus) {
+	status = LivenessStatus{Status: "ok", LastProcessedAt: l.LastHandled().UTC()}
+	if l.backlog == nil || l.now().Sub(status.LastProcessedAt) <= l.window {
+		return true, status
+	}
+
+	backlog, err := l.backlog()
+	if err != nil {
+		status.Error = err.Error()
+		return true, status
+	}
+	status.Backlog = &backlog
+	if backlog > 0 {
+		status.Status = "unhealthy"
+		return false, status
+	}
+	return true, status
+}
+
+// ServeHTTP responds 200 while the worker is live and 503 once it is wedged, with a JSON status
+func (l *Liveness) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	live, status := l.Check()
+	w.Header().Set("Content-Type", "application/json")
+	if !live {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(status)
+}
+
+// NewLastProcessedGauge exports when the worker last handled a message, as a Unix timestamp
+func NewLastProcessedGauge(liveness *Liveness) prometheus.GaugeFunc {
+	return prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Name:      "worker_last_processed_timestamp_seconds",
+		Help:      "When the worker last processed and acknowledged a message (its start time until the first one)",
+	}, func() float64 {
+		return float64(liveness.LastHandled().UnixNano()) / 1e9
+	})
+}
@@ -0,0 +1,103 @@
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Namespace prefixes every metric exported by the services
+const Namespace = "payment"
+
+// NewRegistry creates a registry with the Go runtime and process collectors registered
+func NewRegistry() *prometheus.Registry {
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return reg
+}
+
+// NewDBPoolCollector exports db's connection pool stats as go_sql_* metrics labelled db_name=name:
+// open, in-use and idle connections, the pool limit, and how often and how long callers waited
+func NewDBPoolCollector(db *sql.DB, name string) prometheus.Collector {
+	return collectors.NewDBStatsCollector(db, name)
+}
+
+// NewDuplicateDeliveryCounter counts payment messages delivered again after their payment was
+// already processed, which the worker acknowledges without applying them a second time
+func NewDuplicateDeliveryCounter() prometheus.Counter {
+	return prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "duplicate_deliveries_total",
+		Help:      "Payment messages redelivered after their payment was already processed, acknowledged without effect",
+	})
+}
+
+// Outcomes of a payment message handled by the worker, the values of worker_messages_total's outcome label
+const (
+	OutcomeProcessed        = "processed"         // The payment's new status was applied
+	OutcomeAlreadyProcessed = "already_processed" // A redelivery for a payment that is no longer PENDING; benign
+	OutcomeFailed           = "failed"            // Processing failed; the message is retried unless the error is terminal
+)
+
+// NewWorkerOutcomeCounter counts the payment messages the worker handled by outcome
+// already_processed is kept apart from failed so redeliveries never show up as errors on dashboards
+func NewWorkerOutcomeCounter() *prometheus.CounterVec {
+	counter := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Name:      "worker_messages_total",
+		Help:      "Payment messages handled by the worker, by outcome: processed, already_processed or failed",
+	}, []string{"outcome"})
+	// Export every outcome from the start, so rates over them are defined before the first message
+	for _, outcome := range []string{OutcomeProcessed, OutcomeAlreadyProcessed, OutcomeFailed} {
+		counter.WithLabelValues(outcome)
+	}
+	return counter
+}
+
+// NewShutdownInFlightGauge reports how many messages were being processed when the worker began shutting down
+func NewShutdownInFlightGauge() prometheus.Gauge {
+	return prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Name:      "worker_shutdown_in_flight_messages",
+		Help:      "Messages being processed when the worker stopped consuming to shut down",
+	})
+}
+
+// NewShutdownDrainGauge reports how long the worker waited for in-flight messages while shutting down
+func NewShutdownDrainGauge() prometheus.Gauge {
+	return prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Name:      "worker_shutdown_drain_seconds",
+		Help:      "Time the worker spent waiting for in-flight messages to finish after it stopped consuming",
+	})
+}
+
+// Handler serves the registry's metrics in the Prometheus exposition format
+func Handler(reg *prometheus.Registry) http.Handler {
+	return promhttp.HandlerFor(reg, promhttp.HandlerOpts{})
+}
+
+// NewServer creates an HTTP server exposing the registry's metrics on /metrics, health on LivenessPath
+// when it is set, and the runtime profiles under PprofPath when pprof is set
+func NewServer(port string, reg *prometheus.Registry, health http.Handler, pprof bool) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", Handler(reg))
+	if health != nil {
+		mux.Handle(LivenessPath, health)
+	}
+	if pprof {
+		RegisterPprof(mux)
+	}
+	return &http.Server{
+		Addr:              ":" + port,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+}
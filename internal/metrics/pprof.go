@@ -0,0 +1,33 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/pprof"
+	"time"
+)
+
+// PprofPath is where the runtime profiles are served, e.g. /debug/pprof/goroutine?debug=2
+const PprofPath = "/debug/pprof/"
+
+// RegisterPprof adds net/http/pprof's handlers to mux under PprofPath
+// Profiles expose internals such as stack traces and command-line flags, so only register them
+// on a listener that is not reachable from outside the cluster, and only when ENABLE_PPROF is set
+func RegisterPprof(mux *http.ServeMux) {
+	mux.HandleFunc(PprofPath, pprof.Index) // Also serves the named profiles: heap, goroutine, allocs, block, mutex
+	mux.HandleFunc(PprofPath+"cmdline", pprof.Cmdline)
+	mux.HandleFunc(PprofPath+"profile", pprof.Profile)
+	mux.HandleFunc(PprofPath+"symbol", pprof.Symbol)
+	mux.HandleFunc(PprofPath+"trace", pprof.Trace)
+}
+
+// NewPprofServer creates an HTTP server serving only the profiles, on addr
+// There is no write timeout: a CPU profile or trace streams for as long as its seconds parameter asks
+func NewPprofServer(addr string) *http.Server {
+	mux := http.NewServeMux()
+	RegisterPprof(mux)
+	return &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+}
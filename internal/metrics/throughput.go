@@ -0,0 +1,68 @@
+package metrics
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// ThroughputWindow is how far back the throughput gauge averages
+const ThroughputWindow = time.Minute
+
+// Throughput counts events in a sliding window of one-second buckets
+type Throughput struct {
+	mu      sync.Mutex
+	counts  []int64
+	seconds []int64 // Unix second each bucket counts; a bucket from an older second is stale
+	now     func() time.Time
+}
+
+// NewThroughput creates an empty sliding window over ThroughputWindow
+func NewThroughput() *Throughput {
+	size := int(ThroughputWindow / time.Second)
+	return &Throughput{
+		counts:  make([]int64, size),
+		seconds: make([]int64, size),
+		now:     time.Now,
+	}
+}
+
+// Inc counts one event at the current second
+func (t *Throughput) Inc() {
+	second := t.now().Unix()
+	i := int(second % int64(len(t.counts)))
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.seconds[i] != second {
+		t.seconds[i] = second
+		t.counts[i] = 0
+	}
+	t.counts[i]++
+}
+
+// PerSecond returns the average number of events per second over the window
+func (t *Throughput) PerSecond() float64 {
+	oldest := t.now().Unix() - int64(len(t.counts)) + 1
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var total int64
+	for i, second := range t.seconds {
+		if second >= oldest {
+			total += t.counts[i]
+		}
+	}
+	return float64(total) / float64(len(t.counts))
+}
+
+// NewThroughputGauge exports the payments the worker processed per second over the last minute
+// Unlike rate() over a counter it needs no scrape history, so a dashboard shows it from the first scrape
+func NewThroughputGauge(throughput *Throughput) prometheus.GaugeFunc {
+	return prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: Namespace,
+		Name:      "worker_processed_per_second",
+		Help:      "Payments processed per second, averaged over the last minute",
+	}, throughput.PerSecond)
+}
@@ -0,0 +1,85 @@
+package input
+
+import (
+	"time"
+
+	"github.com/cashflow/payment-gateway/internal/core"
+	"github.com/google/uuid"
+)
+
+// AdminService is an input port (primary port) for operator-only payment operations
+// Primary adapters (admin HTTP handlers) will use this
+type AdminService interface {
+	// PurgePayments soft-deletes payments matching the request filter
+	PurgePayments(req PurgePaymentsRequest) (*PurgePaymentsResponse, error)
+
+	// ListPendingPayments retrieves PENDING payments older than the request's age, oldest first
+	ListPendingPayments(req ListPendingPaymentsRequest) (*ListPendingPaymentsResponse, error)
+
+	// ReprocessPayments sends eligible FAILED payments back to PENDING and re-enqueues them
+	ReprocessPayments(req ReprocessPaymentsRequest) (*ReprocessPaymentsResponse, error)
+
+	// ApprovePayment releases a payment held for review to PENDING and enqueues it for processing
+	ApprovePayment(id uuid.UUID) (*PaymentResponse, error)
+
+	// RejectPayment fails a payment held for review without processing it
+	RejectPayment(id uuid.UUID) (*PaymentResponse, error)
+
+	// OverrideStatus forces a payment to a status outside the normal state machine, recording who and why
+	OverrideStatus(req OverrideStatusRequest) (*PaymentResponse, error)
+}
+
+// PurgePaymentsRequest represents the request to purge payments
+type PurgePaymentsRequest struct {
+	Status        core.PaymentStatus
+	CreatedBefore time.Time
+	DryRun        bool // Only report how many payments would be purged
+}
+
+// PurgePaymentsResponse represents the result of a purge
+type PurgePaymentsResponse struct {
+	Count  int64
+	DryRun bool
+}
+
+// ListPendingPaymentsRequest represents the request to find stuck PENDING payments
+// OlderThan and Limit fall back to service defaults when zero
+type ListPendingPaymentsRequest struct {
+	OlderThan time.Duration
+	Limit     int
+}
+
+// ListPendingPaymentsResponse represents PENDING payments created before Cutoff
+type ListPendingPaymentsResponse struct {
+	Payments []PaymentResponse
+	Cutoff   time.Time
+	Limit    int
+}
+
+// ReprocessPaymentsRequest selects FAILED payments to reprocess, either by ID or by creation time
+// Set PaymentIDs or at least one of CreatedAfter and CreatedBefore, not both
+type ReprocessPaymentsRequest struct {
+	PaymentIDs    []uuid.UUID
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+	DryRun        bool // Only report which payments would be reprocessed
+}
+
+// OverrideStatusRequest represents the request to force a payment's status
+// Actor and Reason are required; both are kept on the recorded payment event
+// Actor is the operator the caller authenticated as, so it can't be chosen by the request itself
+type OverrideStatusRequest struct {
+	PaymentID uuid.UUID
+	Status    core.PaymentStatus
+	Actor     string
+	Reason    string
+}
+
+// ReprocessPaymentsResponse represents the result of a reprocess
+// Enqueued is false when the payments were reset but publishing failed; they then
+// stay PENDING and show up as stuck until the outbox relay publishes them
+type ReprocessPaymentsResponse struct {
+	PaymentIDs []uuid.UUID
+	DryRun     bool
+	Enqueued   bool
+}
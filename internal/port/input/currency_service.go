@@ -0,0 +1,20 @@
+package input
+
+import "github.com/cashflow/payment-gateway/internal/core"
+
+// CurrencyService is an input port (primary port) for the currency registry
+// Primary adapters (HTTP handlers) will use this
+type CurrencyService interface {
+	// ListCurrencies lists the currencies payments can be created in
+	ListCurrencies() []CurrencyResponse
+}
+
+// CurrencyResponse represents the response for a supported currency
+type CurrencyResponse struct {
+	Code      core.Currency
+	Decimals  int
+	MinAmount float64
+	MaxAmount float64
+
+	MinProcessable float64 // Smallest amount payments are processed for; 0 when MinAmount is enough
+}
@@ -0,0 +1,36 @@
+package input
+
+import (
+	"time"
+
+	"github.com/cashflow/payment-gateway/internal/core"
+	"github.com/google/uuid"
+)
+
+// ExportService is an input port (primary port) for bulk payment exports to the object store
+// Primary adapters (admin HTTP handlers) will use this
+type ExportService interface {
+	// StartExport starts exporting the payments created on a UTC day in the background
+	StartExport(req StartExportRequest) (*ExportResponse, error)
+
+	// GetExport retrieves an export by ID, to follow its progress
+	GetExport(id uuid.UUID) (*ExportResponse, error)
+}
+
+// StartExportRequest represents the request to export a day's payments
+// Day is truncated to midnight UTC
+type StartExportRequest struct {
+	Day time.Time
+}
+
+// ExportResponse represents the response for an export
+type ExportResponse struct {
+	ID          uuid.UUID
+	Day         time.Time
+	Status      core.ExportStatus
+	ObjectKey   string
+	RowCount    int64
+	Error       string
+	CreatedAt   time.Time
+	CompletedAt *time.Time
+}
@@ -0,0 +1,26 @@
+package input
+
+import (
+	"time"
+
+	"github.com/cashflow/payment-gateway/internal/core"
+	"github.com/google/uuid"
+)
+
+// LedgerService is an input port (primary port) for ledger operations
+// Primary adapters (HTTP handlers) will use this
+type LedgerService interface {
+	// GetPaymentLedger retrieves the ledger entries recorded for a payment
+	GetPaymentLedger(paymentID uuid.UUID) ([]LedgerEntryResponse, error)
+}
+
+// LedgerEntryResponse represents the response for a ledger entry
+type LedgerEntryResponse struct {
+	ID        uuid.UUID
+	PaymentID uuid.UUID
+	Account   string
+	Direction core.LedgerDirection
+	Amount    float64
+	Currency  core.Currency
+	CreatedAt time.Time
+}
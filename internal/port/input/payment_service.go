@@ -13,24 +13,187 @@ type PaymentService interface {
 	// CreatePayment creates a new payment
 	CreatePayment(req CreatePaymentRequest) (*PaymentResponse, error)
 
+	// ValidatePayment validates a create request without persisting or publishing anything
+	// It returns a *ValidationError when the request is invalid
+	ValidatePayment(req CreatePaymentRequest) error
+
 	// GetPayment retrieves a payment by ID
 	GetPayment(id uuid.UUID) (*PaymentResponse, error)
+
+	// GetPaymentWithIncludes retrieves a payment by ID along with the requested related records
+	GetPaymentWithIncludes(id uuid.UUID, includes []string) (*PaymentResponse, error)
+
+	// GetPaymentVersion checks that a payment exists, scoped to merchantID when set, without loading it
+	// It returns when the payment last changed, which matches PaymentResponse.UpdatedAt
+	GetPaymentVersion(id uuid.UUID, merchantID string) (time.Time, error)
+
+	// ListPayments retrieves payments matching the request filters
+	ListPayments(req ListPaymentsRequest) (*ListPaymentsResponse, error)
+
+	// ListPaymentEvents retrieves a page of a payment's status history
+	ListPaymentEvents(req ListPaymentEventsRequest) (*ListPaymentEventsResponse, error)
+
+	// CapturePayment settles (part of) an AUTHORIZED auth-only payment
+	CapturePayment(req CapturePaymentRequest) (*PaymentResponse, error)
+
+	// VoidPayment releases an AUTHORIZED auth-only payment without capturing it
+	VoidPayment(req VoidPaymentRequest) (*PaymentResponse, error)
+
+	// ListChildPayments retrieves a parent payment's installments and how much of its amount they collected
+	// A non-empty merchantID reports other merchants' payments as not found
+	ListChildPayments(parentID uuid.UUID, merchantID string) (*ListChildPaymentsResponse, error)
 }
 
+// Related records that can be requested with GetPaymentWithIncludes
+const (
+	IncludeEvents = "events"
+	IncludeLedger = "ledger"
+)
+
 // CreatePaymentRequest represents the request to create a payment
 type CreatePaymentRequest struct {
-	Amount    float64
-	Currency  core.Currency
-	Reference string
+	MerchantID  string
+	Amount      float64
+	Currency    core.Currency
+	Reference   string
+	Description string
+	IsTest      bool
+	Tags        []string
+	Source      core.PaymentSource // Defaults to api when empty
+	Method      core.PaymentMethod // Defaults to unknown when empty
+	AuthOnly    bool               // Authorize only; the payment is settled by a later capture
+
+	// Optional customer identifiers, for fraud analysis and support lookups
+	CustomerID    string
+	CustomerEmail string
+
+	// Optional expiry, as an absolute time or seconds from now (at most one may be set)
+	ExpiresAt  *time.Time
+	TTLSeconds int
+
+	// Optional client-supplied key making retries of the same create safe
+	IdempotencyKey string
+
+	// Generate the reference when Reference is empty, instead of rejecting the request
+	GenerateReference bool
+
+	// Optional payment whose amount this one collects part of, as an installment
+	ParentPaymentID *uuid.UUID
+
+	// Optional, receives the time spent on the database and publishing
+	Timings *Timings
+}
+
+// CapturePaymentRequest represents the request to capture an authorized payment
+// An Amount of 0 captures the full authorized amount
+type CapturePaymentRequest struct {
+	PaymentID  uuid.UUID
+	MerchantID string
+	Amount     float64
+}
+
+// VoidPaymentRequest represents the request to void an authorized payment
+type VoidPaymentRequest struct {
+	PaymentID  uuid.UUID
+	MerchantID string
+}
+
+// ListPaymentsRequest represents the request to list payments
+// MerchantID scopes the result to a single merchant when set
+type ListPaymentsRequest struct {
+	MerchantID    string
+	IsTest        *bool
+	Status        core.PaymentStatus
+	Method        core.PaymentMethod
+	CustomerID    string
+	Tag           string
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+	Limit         int
+	Offset        int
+	IncludeTotal  bool // Also count every matching payment, which costs a COUNT(*) query
+}
+
+// ListPaymentsResponse represents a page of payments
+// Total is the number of payments matching the filters, set only when requested
+type ListPaymentsResponse struct {
+	Payments []PaymentResponse
+	Limit    int
+	Offset   int
+	Total    *int64
 }
 
 // PaymentResponse represents the response for a payment
 type PaymentResponse struct {
-	ID        uuid.UUID
-	Amount    float64
-	Currency  core.Currency
-	Reference string
-	Status    core.PaymentStatus
-	CreatedAt time.Time
+	ID               uuid.UUID
+	MerchantID       string
+	Amount           float64
+	Currency         core.Currency
+	Reference        string
+	Description      string
+	CustomerID       string
+	CustomerEmail    string
+	Status           core.PaymentStatus
+	Source           core.PaymentSource
+	Method           core.PaymentMethod
+	IsTest           bool
+	AuthOnly         bool
+	AuthorizedAmount float64 // Set for auth-only payments; Amount is the captured amount once captured
+	Tags             []string
+	ExpiresAt        *time.Time
+	ParentPaymentID  *uuid.UUID
+	Attempts         int // Processing attempts the worker started
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+	Enqueued         bool // Set by CreatePayment once the processing message is confirmed
+	Replayed         bool // Set by CreatePayment when an earlier request with the same idempotency key created the payment
+
+	// Related records, nil unless requested via GetPaymentWithIncludes
+	Events        []PaymentEventResponse
+	LedgerEntries []LedgerEntryResponse
 }
 
+// ListChildPaymentsResponse represents a parent payment's installments, oldest first
+// TotalAmount is the parent's amount; it splits into PaidAmount, PendingAmount and RemainingAmount
+type ListChildPaymentsResponse struct {
+	ParentPaymentID uuid.UUID
+	Currency        core.Currency
+	Children        []PaymentResponse
+	TotalAmount     float64
+	PaidAmount      float64 // Collected by SUCCESS installments
+	PendingAmount   float64 // Held by installments in REVIEW, PENDING or AUTHORIZED
+	RemainingAmount float64 // Left for new installments
+}
+
+// Orders ListPaymentEvents can return events in
+const (
+	EventOrderNewest = "desc"
+	EventOrderOldest = "asc"
+)
+
+// ListPaymentEventsRequest selects a page of a payment's events
+type ListPaymentEventsRequest struct {
+	PaymentID  uuid.UUID
+	MerchantID string // When set, payments of other merchants are reported as not found
+	Order      string // EventOrderNewest (default) or EventOrderOldest
+	Limit      int
+	Offset     int
+}
+
+// ListPaymentEventsResponse is a page of a payment's events
+type ListPaymentEventsResponse struct {
+	Events []PaymentEventResponse
+	Order  string
+	Limit  int
+	Offset int
+}
+
+// PaymentEventResponse represents the response for a payment status transition
+type PaymentEventResponse struct {
+	ID         uuid.UUID
+	FromStatus core.PaymentStatus
+	ToStatus   core.PaymentStatus
+	Note       string
+	Actor      string
+	CreatedAt  time.Time
+}
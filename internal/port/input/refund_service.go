@@ -0,0 +1,49 @@
+package input
+
+import (
+	"time"
+
+	"github.com/cashflow/payment-gateway/internal/core"
+	"github.com/google/uuid"
+)
+
+// RefundService is an input port (primary port) for refund operations
+// Primary adapters (HTTP handlers) will use this
+type RefundService interface {
+	// CreateRefund refunds (part of) a successful payment
+	// Retrying with the same refund ID returns the original refund instead of refunding twice
+	CreateRefund(req CreateRefundRequest) (*RefundResponse, error)
+
+	// ListRefunds retrieves a payment's refunds and its refund balances
+	ListRefunds(paymentID uuid.UUID, merchantID string) (*ListRefundsResponse, error)
+}
+
+// CreateRefundRequest represents the request to refund a payment
+// An Amount of 0 refunds the full remaining balance
+type CreateRefundRequest struct {
+	PaymentID  uuid.UUID
+	MerchantID string
+	RefundID   string
+	Amount     float64
+}
+
+// RefundResponse represents the response for a refund
+type RefundResponse struct {
+	ID        uuid.UUID
+	PaymentID uuid.UUID
+	RefundID  string
+	Amount    float64
+	Currency  core.Currency
+	Status    core.RefundStatus
+	CreatedAt time.Time
+	Replayed  bool // True when an earlier refund with the same refund ID was returned
+}
+
+// ListRefundsResponse represents a payment's refunds and balances
+// RefundableAmount is the payment amount minus successful refunds (0 unless the payment succeeded)
+type ListRefundsResponse struct {
+	Currency         core.Currency
+	Refunds          []RefundResponse
+	RefundedAmount   float64
+	RefundableAmount float64
+}
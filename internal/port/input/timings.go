@@ -0,0 +1,25 @@
+package input
+
+import "time"
+
+// Timings accumulates how long a request spent waiting on the database and the message broker
+// Services add to it around their repository and publisher calls; a nil *Timings records nothing,
+// so callers that don't want the breakdown leave it unset
+type Timings struct {
+	DB      time.Duration
+	Publish time.Duration
+}
+
+// AddDB records time spent on database queries since start
+func (t *Timings) AddDB(start time.Time) {
+	if t != nil {
+		t.DB += time.Since(start)
+	}
+}
+
+// AddPublish records time spent publishing to the message broker since start
+func (t *Timings) AddPublish(start time.Time) {
+	if t != nil {
+		t.Publish += time.Since(start)
+	}
+}
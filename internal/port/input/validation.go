@@ -0,0 +1,36 @@
+package input
+
+import "strings"
+
+// FieldError describes a validation problem with a single request field
+// Err is the core sentinel the problem maps to, e.g. core.ErrInvalidCurrency
+type FieldError struct {
+	Field   string
+	Message string
+	Err     error
+}
+
+// ValidationError reports every field problem found in a request
+type ValidationError struct {
+	Fields []FieldError
+}
+
+// Error joins the field messages into a single string
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Fields))
+	for _, field := range e.Fields {
+		messages = append(messages, field.Message)
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Unwrap exposes the field sentinels so errors.Is matches any of them
+func (e *ValidationError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Fields))
+	for _, field := range e.Fields {
+		if field.Err != nil {
+			errs = append(errs, field.Err)
+		}
+	}
+	return errs
+}
@@ -0,0 +1,36 @@
+package input
+
+import (
+	"time"
+
+	"github.com/cashflow/payment-gateway/internal/core"
+	"github.com/google/uuid"
+)
+
+// WebhookService is an input port (primary port) for webhook delivery operations
+// Primary adapters (HTTP handlers) will use this
+type WebhookService interface {
+	// ListDeliveries retrieves a payment's webhook deliveries in creation order
+	ListDeliveries(paymentID uuid.UUID, merchantID string) ([]WebhookDeliveryResponse, error)
+
+	// ReplayDelivery schedules a delivered or failed delivery to be sent again with a fresh attempt budget
+	ReplayDelivery(paymentID, deliveryID uuid.UUID, merchantID string) (*WebhookDeliveryResponse, error)
+
+	// ReplayPaymentWebhook re-enqueues the webhook for the payment's current terminal status
+	ReplayPaymentWebhook(paymentID uuid.UUID, merchantID string) (*WebhookDeliveryResponse, error)
+}
+
+// WebhookDeliveryResponse represents the response for a webhook delivery
+type WebhookDeliveryResponse struct {
+	ID             uuid.UUID
+	PaymentID      uuid.UUID
+	EventType      core.EventType
+	URL            string
+	Status         core.WebhookDeliveryStatus
+	Attempts       int
+	NextAttemptAt  time.Time
+	LastError      string
+	LastStatusCode int
+	DeliveredAt    *time.Time
+	CreatedAt      time.Time
+}
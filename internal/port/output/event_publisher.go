@@ -0,0 +1,28 @@
+package output
+
+import (
+	"errors"
+
+	"github.com/cashflow/payment-gateway/internal/core"
+)
+
+// ErrMessagingUnavailable is returned (wrapped) by Publish when the broker can't be reached,
+// as opposed to the broker rejecting the message
+var ErrMessagingUnavailable = errors.New("messaging unavailable")
+
+// EventPublisher is an output port (secondary port) for publishing domain events
+// Secondary adapters (RabbitMQ implementations) map each event type onto their transport
+type EventPublisher interface {
+	// Publish publishes a domain event
+	// A nil error means the transport accepted the event (broker-confirmed for RabbitMQ)
+	// Errors wrapping ErrMessagingUnavailable mean the broker could not be reached or did not confirm
+	// in time; a publish that timed out may still be delivered later, so callers that retry it
+	// (e.g. the outbox relay) rely on consumers tolerating duplicates
+	Publish(event core.DomainEvent) error
+	// PublishBatch publishes several domain events with one round-trip to the broker
+	// A nil error means every event was accepted; on error some events may still have been
+	// published, so consumers must tolerate duplicates when the batch is retried
+	PublishBatch(events []core.DomainEvent) error
+	// Close closes the messaging connection
+	Close() error
+}
@@ -0,0 +1,27 @@
+package output
+
+import (
+	"time"
+
+	"github.com/cashflow/payment-gateway/internal/core"
+	"github.com/google/uuid"
+)
+
+// ExportRepository is an output port (secondary port) for export bookkeeping and
+// reading the payments an export covers
+type ExportRepository interface {
+	// CreateExport saves a new export
+	CreateExport(export *core.Export) error
+
+	// GetExport retrieves an export by ID
+	// Returns core.ErrExportNotFound if it doesn't exist
+	GetExport(id uuid.UUID) (*core.Export, error)
+
+	// UpdateExport saves an export's status, row count, error and completion time
+	UpdateExport(export *core.Export) error
+
+	// StreamPayments calls fn for every payment created in [from, to), oldest first,
+	// reading them through a cursor so memory use doesn't grow with the number of rows
+	// Iteration stops at the first error fn returns, which StreamPayments returns
+	StreamPayments(from, to time.Time, fn func(*core.Payment) error) error
+}
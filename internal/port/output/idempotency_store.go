@@ -0,0 +1,19 @@
+package output
+
+import "github.com/google/uuid"
+
+// IdempotencyStore is an output port (secondary port) remembering which payment an
+// Idempotency-Key created, so retried create requests return it instead of creating another
+// Secondary adapters (in-memory and Redis implementations) will implement this
+type IdempotencyStore interface {
+	// Reserve claims key for a request about to create a payment
+	// When the key is already taken it returns false with the payment created under it,
+	// or uuid.Nil while the first request is still in flight
+	Reserve(key string) (reserved bool, paymentID uuid.UUID, err error)
+
+	// Complete records the payment created under a reserved key
+	Complete(key string, paymentID uuid.UUID) error
+
+	// Release frees a reserved key whose request created nothing, so it can be retried
+	Release(key string) error
+}
@@ -0,0 +1,13 @@
+package output
+
+import (
+	"github.com/cashflow/payment-gateway/internal/core"
+	"github.com/google/uuid"
+)
+
+// LedgerRepository is an output port (secondary port) for ledger data access
+// Ledger entries are written by PaymentRepository within payment status transactions
+type LedgerRepository interface {
+	// ListByPaymentID retrieves the ledger entries for a payment
+	ListByPaymentID(paymentID uuid.UUID) ([]core.LedgerEntry, error)
+}
@@ -0,0 +1,10 @@
+package output
+
+import "io"
+
+// ObjectStore is an output port (secondary port) for writing objects to blob storage
+type ObjectStore interface {
+	// Put writes everything read from body to key, replacing any existing object
+	// body is read to EOF; its length doesn't need to be known in advance
+	Put(key string, body io.Reader, contentType string) error
+}
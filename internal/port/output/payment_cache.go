@@ -0,0 +1,24 @@
+package output
+
+import (
+	"time"
+
+	"github.com/cashflow/payment-gateway/internal/core"
+	"github.com/google/uuid"
+)
+
+// PaymentCache is an output port (secondary port) for caching payments by ID
+// Secondary adapters (in-memory and Redis implementations) will implement this
+type PaymentCache interface {
+	// Get returns the cached payment, or false when it is not cached
+	Get(id uuid.UUID) (*core.Payment, bool, error)
+
+	// Set caches the payment; a zero ttl keeps it until it is deleted
+	Set(payment *core.Payment, ttl time.Duration) error
+
+	// Delete removes the payments from the cache
+	Delete(ids ...uuid.UUID) error
+
+	// Clear removes every cached payment
+	Clear() error
+}
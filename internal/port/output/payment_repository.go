@@ -1,24 +1,197 @@
 package output
 
 import (
+	"time"
+
 	"github.com/google/uuid"
 	"github.com/cashflow/payment-gateway/internal/core"
 )
 
 // PaymentRepository is an output port (secondary port) for payment data access
 // Secondary adapters (database implementations) will implement this
+// Processing, giving up on, expiring, capturing and voiding a payment store the merchant's webhook
+// delivery, built by the repository's WebhookFactory, in the same transaction as the new status
 type PaymentRepository interface {
 	// Create creates a new payment
+	// A PENDING payment gets an outbox entry in the same transaction, marking its payment.created
+	// message as unpublished until MarkPublished clears it
+	// An installment (ParentPaymentID set) is checked against its parent in the same transaction,
+	// with the parent row locked so concurrent installments can't both fit: the parent must be the
+	// merchant's and not an installment itself (core.ErrInvalidParentPayment), in the same currency
+	// (core.ErrInvalidCurrency), and its installments that are not FAILED, EXPIRED or CANCELLED must
+	// not add up to more than its amount (core.ErrInstallmentsExceedTotal)
 	Create(payment *core.Payment) error
 
+	// CreateWithinDailyLimit creates the payment like Create, unless it would take the total of the
+	// merchant's payments in its currency created today (UTC, by the database clock) past limit
+	// FAILED, EXPIRED and CANCELLED payments don't count. The check and the insert share one
+	// transaction and a merchant's limited creates are serialized, so concurrent creates can't both fit
+	// Returns core.ErrLimitExceeded when the payment doesn't fit
+	CreateWithinDailyLimit(payment *core.Payment, limit float64) error
+
+	// MarkPublished clears the outbox entries of payments whose payment.created messages the broker confirmed
+	MarkPublished(ids []uuid.UUID) error
+
+	// ClaimUnpublished returns up to limit PENDING payments whose outbox entries are due at now and
+	// older than minAge, leasing the entries until now+lease so concurrent relays skip them
+	// Entries of payments that are no longer PENDING are cleared instead: their message got through
+	ClaimUnpublished(now time.Time, minAge, lease time.Duration, limit int) ([]*core.Payment, error)
+
+	// DeleteUnpublished removes a payment whose payment.created message never reached the broker,
+	// with its status event and outbox entry, as if it had never been created
+	// Returns core.ErrPaymentAlreadyProcessed if the payment is no longer PENDING or an attempt started
+	DeleteUnpublished(id uuid.UUID) error
+
 	// GetByID retrieves a payment by its ID
 	GetByID(id uuid.UUID) (*core.Payment, error)
 
+	// GetGroup retrieves a parent payment and its installments, oldest first
+	// Returns core.ErrPaymentNotFound when there is no such parent; a payment without installments
+	// is returned with none
+	GetGroup(parentID uuid.UUID) (*core.PaymentGroup, error)
+
+	// GetByIDWithRelations retrieves a payment by its ID, eager-loading the requested relations
+	GetByIDWithRelations(id uuid.UUID, relations PaymentRelations) (*core.Payment, error)
+
+	// GetUpdatedAt returns when the payment last changed, reading that one column instead of the row,
+	// so callers can check that a payment exists cheaply. A non-empty merchantID limits the check to
+	// that merchant's payments. Returns core.ErrPaymentNotFound when there is no such payment
+	GetUpdatedAt(id uuid.UUID, merchantID string) (time.Time, error)
+
+	// ListEvents retrieves a page of a payment's status events
+	ListEvents(page EventPage) ([]core.PaymentEvent, error)
+
+	// ListAfter retrieves up to filter.Limit payments matching filter that come after cursor,
+	// oldest first in (created_at, id) order; a zero cursor starts at the beginning
+	// filter.Offset is ignored: pass the last payment's cursor to get the next page
+	ListAfter(filter PaymentFilter, after PaymentCursor) ([]*core.Payment, error)
+
 	// ProcessPayment atomically processes a payment if it's in PENDING status
 	// Uses SELECT FOR UPDATE to prevent concurrent processing
-	ProcessPayment(id uuid.UUID, newStatus core.PaymentStatus) error
+	// note is recorded on the status event; a payment past its expires_at is moved to EXPIRED
+	// instead of newStatus, with core.NoteExpired. The returned status is the one actually applied
+	ProcessPayment(id uuid.UUID, newStatus core.PaymentStatus, note string) (core.PaymentStatus, error)
+
+	// ProcessPayments applies several processing outcomes like ProcessPayment, in one transaction
+	// Updates for payments that are missing or no longer PENDING are skipped rather than failing the
+	// rest; the result maps every applied update's payment ID to the status actually applied
+	ProcessPayments(updates []StatusUpdate) (map[uuid.UUID]core.PaymentStatus, error)
+
+	// StartAttempt counts a processing attempt on a PENDING payment and returns the locked, updated payment
+	// A payment that already used maxAttempts attempts is not counted again: it is moved to FAILED
+	// with core.NoteMaxAttempts instead (EXPIRED with core.NoteExpired if it expired meanwhile) and
+	// returned with that status. Returns core.ErrPaymentAlreadyProcessed unless the payment is PENDING
+	StartAttempt(id uuid.UUID, maxAttempts int) (*core.Payment, error)
+
+	// StartAttempts counts an attempt on each PENDING payment among ids like StartAttempt, in one transaction
+	// It returns the payments it counted or gave up on; missing and no longer PENDING payments are left out
+	StartAttempts(ids []uuid.UUID, maxAttempts int) ([]*core.Payment, error)
+
+	// Capture settles an AUTHORIZED payment for amount, or its full authorized amount when amount is 0,
+	// moving it to SUCCESS with the captured amount and recording its ledger entries
+	// Returns core.ErrPaymentNotCapturable unless the payment is AUTHORIZED and
+	// core.ErrCaptureExceedsAuthorization when amount is more than was authorized
+	Capture(id uuid.UUID, amount float64) (*core.Payment, error)
+
+	// Void releases an AUTHORIZED payment without capturing it, moving it to CANCELLED
+	// Returns core.ErrPaymentNotVoidable unless the payment is AUTHORIZED
+	Void(id uuid.UUID) (*core.Payment, error)
+
+	// ResolveReview moves a REVIEW payment to decision (PENDING to process it, FAILED to reject it),
+	// recording the transition with note; an approved payment gets an outbox entry in the same
+	// transaction, like a created one
+	// Returns core.ErrPaymentNotInReview unless the payment is in REVIEW
+	ResolveReview(id uuid.UUID, decision core.PaymentStatus, note string) (*core.Payment, error)
+
+	// OverrideStatus forces the payment to status regardless of the transition table,
+	// recording the transition with actor and note
+	// In the same transaction it posts settlement or reversal entries so the ledger matches the new
+	// status, and a payment moved to PENDING gets a fresh attempt budget and an outbox entry
+	// Returns core.ErrPaymentStatusUnchanged when the payment already has status
+	OverrideStatus(id uuid.UUID, status core.PaymentStatus, actor, note string) (*core.Payment, error)
 
 	// ReferenceExists checks if a reference already exists
 	ReferenceExists(reference string) (bool, error)
+
+	// GetByReference retrieves a payment by its reference, reading from the primary database
+	GetByReference(reference string) (*core.Payment, error)
+
+	// List retrieves payments matching the filter, newest first
+	List(filter PaymentFilter) ([]*core.Payment, error)
+
+	// Count counts payments matching the filter, ignoring Limit and Offset
+	Count(filter PaymentFilter) (int64, error)
+
+	// ListPendingBefore retrieves up to limit PENDING payments created before cutoff, oldest first
+	ListPendingBefore(cutoff time.Time, limit int) ([]*core.Payment, error)
+
+	// ExpireDue moves up to limit PENDING payments whose expires_at has passed to EXPIRED,
+	// recording each transition, and returns the expired payments
+	// Expiry is judged by the database's clock rather than the caller's, and each returned
+	// payment's UpdatedAt is the time it expired
+	// Rows locked by a concurrent transaction (e.g. the processor) are skipped
+	ExpireDue(limit int) ([]*core.Payment, error)
+
+	// ResetForReprocess moves the given FAILED payments back to PENDING, recording each transition
+	// with note and giving each an outbox entry in the same transaction, and returns the payments that were reset
+	// Rows are locked and re-checked with core.Payment.CanReprocessAt against the database's clock,
+	// so payments that changed or expired since they were selected are skipped
+	ResetForReprocess(ids []uuid.UUID, note string) ([]*core.Payment, error)
+
+	// SoftDelete marks payments matching the filter as deleted and returns the number affected
+	SoftDelete(filter PaymentFilter) (int64, error)
+}
+
+// StatusUpdate is a processing outcome for a PENDING payment, with the note recorded on its status event
+type StatusUpdate struct {
+	PaymentID uuid.UUID
+	Status    core.PaymentStatus
+	Note      string
+}
+
+// PaymentCursor is a position in (created_at, id) order, from which a scan can resume
+type PaymentCursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
 }
 
+// CursorOf returns the cursor positioned at payment
+func CursorOf(payment *core.Payment) PaymentCursor {
+	return PaymentCursor{CreatedAt: payment.CreatedAt, ID: payment.ID}
+}
+
+// IsZero reports whether the cursor is unset
+func (c PaymentCursor) IsZero() bool {
+	return c.CreatedAt.IsZero() && c.ID == uuid.Nil
+}
+
+// EventPage selects a page of one payment's events, ordered by creation time
+type EventPage struct {
+	PaymentID   uuid.UUID
+	OldestFirst bool // Newest first unless set
+	Limit       int
+	Offset      int
+}
+
+// PaymentRelations selects the related records loaded with a payment
+type PaymentRelations struct {
+	Events bool
+	Ledger bool
+}
+
+// PaymentFilter narrows the payments returned by List
+// Zero values mean "no constraint" for that field
+type PaymentFilter struct {
+	IDs           []uuid.UUID // Only these payments
+	MerchantID    string
+	IsTest        *bool
+	Status        core.PaymentStatus
+	Method        core.PaymentMethod
+	CustomerID    string
+	RefPrefix     string // Only payments whose reference starts with this
+	Tag           string // Only payments carrying this tag
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+	Limit         int
+	Offset        int
+}
@@ -0,0 +1,21 @@
+package output
+
+import (
+	"github.com/cashflow/payment-gateway/internal/core"
+	"github.com/google/uuid"
+)
+
+// RefundRepository is an output port (secondary port) for refund data access
+// Secondary adapters (database implementations) will implement this
+type RefundRepository interface {
+	// CreateRefund atomically refunds a payment, locking it with SELECT FOR UPDATE
+	// An amount of 0 refunds the full remaining balance
+	// If the refund ID was already used for the payment, the existing refund is returned with replayed=true
+	CreateRefund(paymentID uuid.UUID, refundID string, amount float64) (refund *core.Refund, replayed bool, err error)
+
+	// RefundedTotal returns the sum of successful refunds for a payment
+	RefundedTotal(paymentID uuid.UUID) (float64, error)
+
+	// ListByPaymentID retrieves a payment's refunds in creation order
+	ListByPaymentID(paymentID uuid.UUID) ([]*core.Refund, error)
+}
@@ -0,0 +1,11 @@
+package output
+
+import "github.com/cashflow/payment-gateway/internal/core"
+
+// RiskEvaluator is an output port (secondary port) deciding whether a new payment may be processed
+// Implementations hold the risk rules, so rules can be added without touching the create flow
+type RiskEvaluator interface {
+	// Evaluate decides a validated payment before it is stored: approve it, hold it for review or decline it
+	// An error means the payment could not be evaluated and fails the create; nothing has been stored at that point
+	Evaluate(payment *core.Payment) (core.RiskDecision, error)
+}
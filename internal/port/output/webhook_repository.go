@@ -0,0 +1,39 @@
+package output
+
+import (
+	"time"
+
+	"github.com/cashflow/payment-gateway/internal/core"
+	"github.com/google/uuid"
+)
+
+// WebhookRepository is an output port (secondary port) for webhook delivery persistence
+type WebhookRepository interface {
+	// CreateDelivery saves a new delivery
+	CreateDelivery(delivery *core.WebhookDelivery) error
+
+	// GetDelivery retrieves a delivery by ID
+	GetDelivery(id uuid.UUID) (*core.WebhookDelivery, error)
+
+	// ListByPaymentID retrieves a payment's deliveries in creation order
+	ListByPaymentID(paymentID uuid.UUID) ([]*core.WebhookDelivery, error)
+
+	// ClaimDue returns up to limit PENDING deliveries whose next attempt is due at now
+	// Claimed deliveries have their next attempt pushed to now+lease, recorded in LeasedUntil, so
+	// concurrent dispatchers skip them and a crashed dispatcher's claims are retried after the lease
+	ClaimDue(now time.Time, lease time.Duration, limit int) ([]*core.WebhookDelivery, error)
+
+	// UpdateDelivery saves a delivery's status, attempt count and schedule
+	// A claimed delivery (LeasedUntil set) is only saved while it is still PENDING under that lease;
+	// core.ErrDeliveryLeaseLost is returned when it was replayed or claimed again meanwhile
+	UpdateDelivery(delivery *core.WebhookDelivery) error
+}
+
+// WebhookFactory builds the webhook deliveries that notify merchants of payment status changes
+// Payment repositories call it inside the transaction that changes the status, so the delivery is
+// stored if and only if the new status is
+type WebhookFactory interface {
+	// NewDelivery returns the delivery for the payment's current status, or nil when the merchant
+	// has no webhook URL or is not notified of that status
+	NewDelivery(payment *core.Payment) (*core.WebhookDelivery, error)
+}
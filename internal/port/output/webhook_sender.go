@@ -0,0 +1,8 @@
+package output
+
+// WebhookSender is an output port (secondary port) for sending webhook payloads
+type WebhookSender interface {
+	// Send posts the payload to url and returns the response status code
+	// A non-nil error means no response was received (connection failure or timeout)
+	Send(url string, payload []byte) (int, error)
+}
@@ -0,0 +1,113 @@
+//go:build integration
+
+// Package dbtest provides the Postgres the integration suites run against: a container started
+// with dockertest, or the server TEST_DATABASE_URL points at, and a fresh schema per test
+package dbtest
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/cashflow/payment-gateway/internal/constant/model/db"
+	"github.com/google/uuid"
+	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/ory/dockertest/v3"
+	"github.com/ory/dockertest/v3/docker"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// StartTimeout bounds the wait for a started container to accept connections
+const StartTimeout = 2 * time.Minute
+
+// Start returns the URL of the Postgres to test against and a function releasing it
+// TEST_DATABASE_URL selects a server already running; otherwise a container is started, and
+// Start fails rather than skipping when Docker is unreachable
+func Start() (string, func(), error) {
+	if url := os.Getenv("TEST_DATABASE_URL"); url != "" {
+		return url, func() {}, nil
+	}
+
+	pool, err := NewPool()
+	if err != nil {
+		return "", nil, err
+	}
+	resource, err := pool.RunWithOptions(&dockertest.RunOptions{
+		Repository: "postgres",
+		Tag:        "15-alpine",
+		Env:        []string{"POSTGRES_PASSWORD=postgres", "POSTGRES_DB=payments"},
+	}, AutoRemove)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to start Postgres: %w", err)
+	}
+	purge := Purger(pool, resource)
+
+	url := fmt.Sprintf("postgres://postgres:postgres@%s/payments?sslmode=disable", resource.GetHostPort("5432/tcp"))
+	err = pool.Retry(func() error {
+		conn, err := sql.Open("pgx", url)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		return conn.Ping()
+	})
+	if err != nil {
+		purge()
+		return "", nil, fmt.Errorf("failed to reach Postgres: %w", err)
+	}
+	return url, purge, nil
+}
+
+// Open connects to url in a schema of its own, migrated from scratch and dropped when the test
+// ends, so tests neither see each other's rows nor leave any behind
+func Open(t testing.TB, url string) *db.DB {
+	t.Helper()
+	schema := "it_" + uuid.NewString()[:8]
+	conn, err := db.NewDB(url, "", schema, db.PoolConfig{
+		MaxOpenConns:    20,
+		MaxIdleConns:    5,
+		ConnMaxLifetime: time.Minute,
+	}, gormlogger.Discard)
+	if err != nil {
+		t.Fatalf("failed to connect to database: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := conn.Exec("DROP SCHEMA " + schema + " CASCADE").Error; err != nil {
+			t.Errorf("failed to drop schema %s: %v", schema, err)
+		}
+		conn.Close()
+	})
+	return conn
+}
+
+// NewPool connects to Docker, waiting up to StartTimeout for containers to become ready
+func NewPool() (*dockertest.Pool, error) {
+	pool, err := dockertest.NewPool("")
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to Docker: %w", err)
+	}
+	if err := pool.Client.Ping(); err != nil {
+		return nil, fmt.Errorf("failed to connect to Docker: %w", err)
+	}
+	pool.MaxWait = StartTimeout
+	return pool, nil
+}
+
+// AutoRemove has Docker remove the container once it stops, even if the suite is killed before
+// purging it
+func AutoRemove(config *docker.HostConfig) {
+	config.AutoRemove = true
+	config.RestartPolicy = docker.RestartPolicy{Name: "no"}
+}
+
+// Purger returns a function removing the container
+func Purger(pool *dockertest.Pool, resource *dockertest.Resource) func() {
+	return func() {
+		if err := pool.Purge(resource); err != nil {
+			log.Printf("failed to remove container %s: %v", resource.Container.Name, err)
+		}
+	}
+}
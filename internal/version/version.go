@@ -0,0 +1,39 @@
+package version
+
+import "runtime"
+
+// Build information, injected at build time with
+//
+//	go build -ldflags "-X github.com/cashflow/payment-gateway/internal/version.Version=v1.2.3 \
+//	  -X github.com/cashflow/payment-gateway/internal/version.Commit=$(git rev-parse HEAD) \
+//	  -X github.com/cashflow/payment-gateway/internal/version.BuildTime=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+//
+// Builds without the flags (e.g. go run) report the defaults
+var (
+	Version   = "dev"
+	Commit    = "unknown"
+	BuildTime = "unknown"
+)
+
+// Info describes the running build
+type Info struct {
+	Version   string
+	Commit    string
+	BuildTime string
+	GoVersion string
+}
+
+// Get returns the running build's information
+func Get() Info {
+	return Info{
+		Version:   Version,
+		Commit:    Commit,
+		BuildTime: BuildTime,
+		GoVersion: runtime.Version(),
+	}
+}
+
+// String formats the build for startup logs, e.g. "v1.2.3 (commit abc123, built 2024-01-01T12:00:00Z)"
+func (i Info) String() string {
+	return i.Version + " (commit " + i.Commit + ", built " + i.BuildTime + ")"
+}
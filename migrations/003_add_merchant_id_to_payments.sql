@@ -0,0 +1,6 @@
+-- Add merchant_id to payments for merchant-scoped queries
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS merchant_id VARCHAR(64) NOT NULL DEFAULT '';
+
+-- Composite index serving merchant-scoped time-window listings
+-- (WHERE merchant_id = ? AND created_at >= ? AND created_at < ? ORDER BY created_at DESC)
+CREATE INDEX IF NOT EXISTS idx_payments_merchant_created_at ON payments(merchant_id, created_at);
@@ -0,0 +1,2 @@
+-- Add is_test to payments to mark sandbox payments
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS is_test BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,5 @@
+-- Add deleted_at to payments for soft deletion (admin purge)
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+
+-- Create index on deleted_at since every query filters on it
+CREATE INDEX IF NOT EXISTS idx_payments_deleted_at ON payments(deleted_at);
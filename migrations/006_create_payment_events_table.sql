@@ -0,0 +1,11 @@
+-- Create payment_events table (status history)
+CREATE TABLE IF NOT EXISTS payment_events (
+    id UUID PRIMARY KEY,
+    payment_id UUID NOT NULL REFERENCES payments(id),
+    from_status VARCHAR(20) NOT NULL DEFAULT '',
+    to_status VARCHAR(20) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create index on payment_id for per-payment history lookups
+CREATE INDEX IF NOT EXISTS idx_payment_events_payment_id ON payment_events(payment_id);
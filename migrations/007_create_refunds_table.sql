@@ -0,0 +1,13 @@
+-- Create refunds table
+CREATE TABLE IF NOT EXISTS refunds (
+    id UUID PRIMARY KEY,
+    payment_id UUID NOT NULL REFERENCES payments(id),
+    refund_id VARCHAR(64) NOT NULL,
+    amount DECIMAL(15, 2) NOT NULL CHECK (amount > 0),
+    currency VARCHAR(3) NOT NULL,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('SUCCESS', 'FAILED')),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Client-supplied refund IDs are idempotency keys, unique per payment
+CREATE UNIQUE INDEX IF NOT EXISTS idx_refunds_payment_refund_id ON refunds(payment_id, refund_id);
@@ -0,0 +1,5 @@
+-- Partial index serving the oldest-first scan for stuck PENDING payments
+-- (WHERE status = 'PENDING' AND created_at < ? ORDER BY created_at ASC LIMIT ?)
+-- Only pending rows are indexed, so it stays small however many payments have settled
+CREATE INDEX IF NOT EXISTS idx_payments_pending_created_at ON payments(created_at)
+    WHERE status = 'PENDING' AND deleted_at IS NULL;
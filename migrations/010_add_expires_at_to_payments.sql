@@ -0,0 +1,12 @@
+-- Allow the EXPIRED terminal status
+ALTER TABLE payments DROP CONSTRAINT IF EXISTS payments_status_check;
+ALTER TABLE payments ADD CONSTRAINT payments_status_check
+    CHECK (status IN ('PENDING', 'SUCCESS', 'FAILED', 'EXPIRED'));
+
+-- Add optional expiry; NULL means the payment never expires
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS expires_at TIMESTAMP;
+
+-- Partial index serving the expiry sweeper's scan
+-- (WHERE status = 'PENDING' AND expires_at <= ? ORDER BY expires_at ASC LIMIT ?)
+CREATE INDEX IF NOT EXISTS idx_payments_pending_expires_at ON payments(expires_at)
+    WHERE status = 'PENDING' AND expires_at IS NOT NULL AND deleted_at IS NULL;
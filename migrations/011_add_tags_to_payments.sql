@@ -0,0 +1,5 @@
+-- Add tags to payments for merchant-defined categorization
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS tags JSONB NOT NULL DEFAULT '[]';
+
+-- GIN index serving the list endpoint's tag filter (WHERE tags @> '["subscription"]')
+CREATE INDEX IF NOT EXISTS idx_payments_tags ON payments USING GIN (tags);
@@ -0,0 +1,2 @@
+-- Add an optional human-readable description shown to customers
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS description VARCHAR(500) NOT NULL DEFAULT '';
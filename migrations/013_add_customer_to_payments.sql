@@ -0,0 +1,6 @@
+-- Add optional customer identifiers to payments for fraud analysis and support lookups
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS customer_id VARCHAR(64) NOT NULL DEFAULT '';
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS customer_email VARCHAR(254) NOT NULL DEFAULT '';
+
+-- Index serving the list endpoint's customer_id filter, scoped by merchant
+CREATE INDEX IF NOT EXISTS idx_payments_merchant_customer ON payments (merchant_id, customer_id) WHERE customer_id <> '';
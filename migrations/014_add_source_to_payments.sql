@@ -0,0 +1,5 @@
+-- Record which code path created each payment, for auditing and debugging
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS source VARCHAR(10) NOT NULL DEFAULT 'api';
+ALTER TABLE payments DROP CONSTRAINT IF EXISTS payments_source_check;
+ALTER TABLE payments ADD CONSTRAINT payments_source_check
+    CHECK (source IN ('api', 'import', 'replay'));
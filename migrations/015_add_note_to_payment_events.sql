@@ -0,0 +1,2 @@
+-- Record why each status transition happened, e.g. "gateway declined" or "expired"
+ALTER TABLE payment_events ADD COLUMN IF NOT EXISTS note VARCHAR(255) NOT NULL DEFAULT '';
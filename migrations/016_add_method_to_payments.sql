@@ -0,0 +1,5 @@
+-- Record how each payment was paid; existing payments predate it and stay unknown
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS method VARCHAR(20) NOT NULL DEFAULT 'unknown';
+ALTER TABLE payments DROP CONSTRAINT IF EXISTS payments_method_check;
+ALTER TABLE payments ADD CONSTRAINT payments_method_check
+    CHECK (method IN ('unknown', 'card', 'mobile_money', 'bank_transfer'));
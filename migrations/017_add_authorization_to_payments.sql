@@ -0,0 +1,9 @@
+-- Allow auth-only payments: AUTHORIZED awaits a separate capture
+ALTER TABLE payments DROP CONSTRAINT IF EXISTS payments_status_check;
+ALTER TABLE payments ADD CONSTRAINT payments_status_check
+    CHECK (status IN ('PENDING', 'AUTHORIZED', 'SUCCESS', 'FAILED', 'EXPIRED'));
+
+-- auth_only marks payments created with capture=false; authorized_amount keeps the amount
+-- they were authorized for, since amount becomes the captured amount
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS auth_only BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS authorized_amount DECIMAL(15, 2) NOT NULL DEFAULT 0;
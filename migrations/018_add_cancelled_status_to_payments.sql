@@ -0,0 +1,4 @@
+-- Allow CANCELLED, the terminal status of an authorization voided without capture
+ALTER TABLE payments DROP CONSTRAINT IF EXISTS payments_status_check;
+ALTER TABLE payments ADD CONSTRAINT payments_status_check
+    CHECK (status IN ('PENDING', 'AUTHORIZED', 'SUCCESS', 'FAILED', 'EXPIRED', 'CANCELLED'));
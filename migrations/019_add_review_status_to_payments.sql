@@ -0,0 +1,4 @@
+-- Allow REVIEW, the status of a payment a risk rule holds for manual review before processing
+ALTER TABLE payments DROP CONSTRAINT IF EXISTS payments_status_check;
+ALTER TABLE payments ADD CONSTRAINT payments_status_check
+    CHECK (status IN ('REVIEW', 'PENDING', 'AUTHORIZED', 'SUCCESS', 'FAILED', 'EXPIRED', 'CANCELLED'));
@@ -0,0 +1,3 @@
+-- Index serving left-anchored reference LIKE queries (the velocity rule counts recent payments by reference prefix)
+-- The unique index on reference uses the database collation, which can't serve LIKE outside the C locale
+CREATE INDEX IF NOT EXISTS idx_payments_reference_pattern ON payments (reference varchar_pattern_ops);
@@ -0,0 +1,2 @@
+-- Add actor to payment_events, the operator who forced an admin status override
+ALTER TABLE payment_events ADD COLUMN IF NOT EXISTS actor VARCHAR(64) NOT NULL DEFAULT '';
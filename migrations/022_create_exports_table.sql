@@ -0,0 +1,13 @@
+-- Create exports table, recording bulk exports of a day's payments to the object store
+CREATE TABLE IF NOT EXISTS exports (
+    id UUID PRIMARY KEY,
+    day DATE NOT NULL,
+    status VARCHAR(20) NOT NULL CHECK (status IN ('RUNNING', 'SUCCEEDED', 'FAILED')),
+    object_key TEXT NOT NULL,
+    row_count BIGINT NOT NULL DEFAULT 0,
+    error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_exports_day ON exports(day);
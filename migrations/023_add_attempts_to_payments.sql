@@ -0,0 +1,2 @@
+-- Add attempts to payments, the processing attempts the worker started; past WORKER_MAX_ATTEMPTS the payment fails
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS attempts INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,10 @@
+-- Create payment_outbox table: PENDING payments whose payment.created message the broker has not confirmed yet
+CREATE TABLE IF NOT EXISTS payment_outbox (
+    payment_id UUID PRIMARY KEY REFERENCES payments(id),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Backs the outbox relay's scan for due entries
+CREATE INDEX IF NOT EXISTS idx_payment_outbox_next_attempt_at ON payment_outbox(next_attempt_at);
@@ -0,0 +1,5 @@
+-- Add parent_payment_id to payments, linking an installment to the payment whose amount it collects part of
+ALTER TABLE payments ADD COLUMN IF NOT EXISTS parent_payment_id UUID REFERENCES payments(id);
+
+-- Backs listing a parent's installments and summing them when one is created
+CREATE INDEX IF NOT EXISTS idx_payments_parent_payment_id ON payments(parent_payment_id) WHERE parent_payment_id IS NOT NULL;